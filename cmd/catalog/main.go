@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/controller/operators/catalog"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/audit"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/queueinformer"
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/signals"
 	log "github.com/sirupsen/logrus"
 )
@@ -32,6 +34,20 @@ var (
 
 	debug = flag.Bool(
 		"debug", false, "use debug log level")
+
+	auditSink = flag.String(
+		"auditSink", "", "where to write a JSON record of every object the catalog operator creates, updates or deletes: a file path, "+
+			"\"stdout\", or an http(s) URL to POST each record to. If not set, no records are written.")
+
+	workers = flag.Int(
+		"workers", queueinformer.DefaultWorkers, "number of workers syncing each queue")
+
+	maxRetries = flag.Int(
+		"maxRetries", queueinformer.DefaultMaxRetries, "number of times a failed sync is retried before giving up on it. "+
+			"A negative value retries forever.")
+
+	shutdownTimeout = flag.Duration(
+		"shutdownTimeout", queueinformer.DefaultShutdownTimeout, "how long to wait for in-flight syncs to finish when stopping")
 )
 
 func main() {
@@ -50,11 +66,26 @@ func main() {
 	})
 	go http.ListenAndServe(":8080", nil)
 
+	sink, err := audit.NewSink(*auditSink)
+	if err != nil {
+		log.Fatalf("error configuring audit sink: %s", err.Error())
+	}
+
 	// Create a new instance of the operator.
-	catalogOperator, err := catalog.NewOperator(*kubeConfigPath, *wakeupInterval, *catalogNamespace, strings.Split(*watchedNamespaces, ",")...)
+	catalogOperator, err := catalog.NewOperator(*kubeConfigPath, *wakeupInterval, *catalogNamespace, audit.NewAuditor(sink, "catalog-operator"), queueConfig(), strings.Split(*watchedNamespaces, ",")...)
 	if err != nil {
 		log.Panicf("error configuring operator: %s", err.Error())
 	}
 
-	catalogOperator.Run(stopCh)
+	if err := catalogOperator.Run(stopCh); err != nil {
+		log.Fatalf("error running operator: %s", err.Error())
+	}
+}
+
+func queueConfig() queueinformer.Config {
+	return queueinformer.Config{
+		Workers:         *workers,
+		MaxRetries:      *maxRetries,
+		ShutdownTimeout: *shutdownTimeout,
+	}
 }
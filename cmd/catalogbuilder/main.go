@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+
+	"github.com/ghodss/yaml"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/controller/registry"
+)
+
+// config flags defined globally so that they appear on the test binary as well
+var (
+	directory = flag.String(
+		"directory", "", "directory of catalog resources to build the ConfigMap from")
+
+	name = flag.String(
+		"name", "", "name of the catalog ConfigMap")
+
+	namespace = flag.String(
+		"namespace", "tectonic-system", "namespace of the catalog ConfigMap")
+
+	compress = flag.Bool(
+		"compress", true, "gzip the catalog entries into binaryData, so larger catalogs fit in the ConfigMap")
+
+	output = flag.String(
+		"output", "", "file to write the ConfigMap manifest to, stdout if empty")
+
+	debug = flag.Bool(
+		"debug", false, "use debug log level")
+)
+
+// catalogbuilder builds a catalog ConfigMap manifest from a directory of catalog resources, ready for kubectl apply
+func main() {
+	flag.Parse()
+
+	if *debug {
+		log.SetLevel(log.DebugLevel)
+	}
+	if *directory == "" || *name == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cm, err := registry.NewCatalogConfigMapFromDirectory(*directory, *name, *namespace, *compress)
+	if err != nil {
+		log.Fatalf("error building catalog ConfigMap: %s", err)
+	}
+	manifest, err := yaml.Marshal(cm)
+	if err != nil {
+		log.Fatalf("error writing catalog ConfigMap: %s", err)
+	}
+
+	if *output == "" {
+		_, err = os.Stdout.Write(manifest)
+	} else {
+		err = ioutil.WriteFile(*output, manifest, 0644)
+	}
+	if err != nil {
+		log.Fatalf("error writing catalog ConfigMap: %s", err)
+	}
+}
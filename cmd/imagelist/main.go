@@ -0,0 +1,109 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/controller/install"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/controller/registry"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/operatorclient"
+)
+
+// config flags defined globally so that they appear on the test binary as well
+var (
+	directory = flag.String(
+		"directory", "", "directory of catalog resources to list images from")
+
+	configMapFile = flag.String(
+		"configmapFile", "", "path to a catalog ConfigMap manifest to list images from")
+
+	configMapName = flag.String(
+		"configmap", "", "name of a catalog ConfigMap in the cluster to list images from")
+
+	namespace = flag.String(
+		"namespace", "tectonic-system", "namespace of the catalog ConfigMap given by -configmap")
+
+	kubeConfigPath = flag.String(
+		"kubeconfig", "", "absolute path to the kubeconfig file, used with -configmap")
+
+	imageRelocationConfig = flag.String(
+		"imageRelocationConfig", "", "path to an image relocation config. If set, each image is printed "+
+			"followed by the image it will be relocated to, for use in mirroring jobs")
+
+	showCSVs = flag.Bool(
+		"showCSVs", false, "print the ClusterServiceVersions that reference each image")
+
+	debug = flag.Bool(
+		"debug", false, "use debug log level")
+)
+
+// imagelist prints every image referenced by every ClusterServiceVersion in a catalog, one per line
+func main() {
+	flag.Parse()
+
+	if *debug {
+		log.SetLevel(log.DebugLevel)
+	}
+
+	catalog, err := loadCatalog()
+	if err != nil {
+		log.Fatalf("error loading catalog: %s", err.Error())
+	}
+
+	var imageRelocation *install.ImageRelocationConfig
+	if *imageRelocationConfig != "" {
+		imageRelocation, err = install.LoadImageRelocationConfig(*imageRelocationConfig)
+		if err != nil {
+			log.Fatalf("error loading image relocation config: %s", err.Error())
+		}
+	}
+
+	images, err := registry.ListCatalogImages(catalog)
+	if err != nil {
+		log.Fatalf("error listing images: %s", err.Error())
+	}
+	for _, image := range images {
+		line := []string{image.Image}
+		if imageRelocation != nil {
+			line = append(line, imageRelocation.Relocate(image.Image))
+		}
+		if *showCSVs {
+			line = append(line, strings.Join(image.CSVs, ","))
+		}
+		fmt.Println(strings.Join(line, " "))
+	}
+}
+
+func loadCatalog() (*registry.InMem, error) {
+	switch {
+	case *directory != "":
+		return registry.NewInMemoryFromDirectory(*directory)
+	case *configMapFile != "":
+		data, err := ioutil.ReadFile(*configMapFile)
+		if err != nil {
+			return nil, err
+		}
+		cm := &corev1.ConfigMap{}
+		if err := yaml.Unmarshal(data, cm); err != nil {
+			return nil, fmt.Errorf("error parsing ConfigMap %s: %s", *configMapFile, err)
+		}
+		loader := registry.NewConfigMapCatalogResourceLoader(cm.GetNamespace(), nil)
+		catalog := registry.NewInMem()
+		if err := loader.LoadCatalogResourcesFromConfigMap(catalog, cm); err != nil {
+			return nil, err
+		}
+		return catalog, nil
+	case *configMapName != "":
+		return registry.NewInMemoryFromConfigMap(operatorclient.NewClient(*kubeConfigPath), *namespace, *configMapName)
+	}
+	flag.Usage()
+	os.Exit(2)
+	return nil, nil
+}
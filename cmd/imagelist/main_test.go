@@ -0,0 +1,7 @@
+package main
+
+import "testing"
+
+func TestImageListMain(t *testing.T) {
+	main()
+}
@@ -10,7 +10,10 @@ import (
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/controller/install"
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/controller/operators/olm"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/audit"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/queueinformer"
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/signals"
 )
 
@@ -44,6 +47,24 @@ var (
 			"alm operator will watch all namespaces in the cluster.")
 	debug = flag.Bool(
 		"debug", false, "use debug log level")
+
+	imageRelocationConfig = flag.String(
+		"imageRelocationConfig", "", "path to a yaml file of registry, prefix, and digest rules used to rewrite the images "+
+			"of installed deployments. If not set, images are deployed as written in the ClusterServiceVersion.")
+
+	auditSink = flag.String(
+		"auditSink", "", "where to write a JSON record of every object the alm operator creates, updates or deletes: a file path, "+
+			"\"stdout\", or an http(s) URL to POST each record to. If not set, no records are written.")
+
+	workers = flag.Int(
+		"workers", queueinformer.DefaultWorkers, "number of workers syncing each queue")
+
+	maxRetries = flag.Int(
+		"maxRetries", queueinformer.DefaultMaxRetries, "number of times a failed sync is retried before giving up on it. "+
+			"A negative value retries forever.")
+
+	shutdownTimeout = flag.Duration(
+		"shutdownTimeout", queueinformer.DefaultShutdownTimeout, "how long to wait for in-flight syncs to finish when stopping")
 )
 
 // main function - entrypoint to ALM operator
@@ -73,8 +94,22 @@ func main() {
 	// the empty string, the resulting array will be `[]string{""}`.
 	namespaces := strings.Split(*watchedNamespaces, ",")
 
+	var imageRelocation *install.ImageRelocationConfig
+	if *imageRelocationConfig != "" {
+		config, err := install.LoadImageRelocationConfig(*imageRelocationConfig)
+		if err != nil {
+			log.Fatalf("error loading image relocation config: %s", err.Error())
+		}
+		imageRelocation = config
+	}
+
+	sink, err := audit.NewSink(*auditSink)
+	if err != nil {
+		log.Fatalf("error configuring audit sink: %s", err.Error())
+	}
+
 	// Create a new instance of the operator.
-	operator, err := olm.NewOperator(*kubeConfigPath, *wakeupInterval, annotation, namespaces)
+	operator, err := olm.NewOperator(*kubeConfigPath, *wakeupInterval, annotation, namespaces, imageRelocation, audit.NewAuditor(sink, "alm-operator"), queueConfig())
 
 	if err != nil {
 		log.Fatalf("error configuring operator: %s", err.Error())
@@ -86,5 +121,15 @@ func main() {
 	})
 	go http.ListenAndServe(":8080", nil)
 
-	operator.Run(stopCh)
+	if err := operator.Run(stopCh); err != nil {
+		log.Fatalf("error running operator: %s", err.Error())
+	}
+}
+
+func queueConfig() queueinformer.Config {
+	return queueinformer.Config{
+		Workers:         *workers,
+		MaxRetries:      *maxRetries,
+		ShutdownTimeout: *shutdownTimeout,
+	}
 }
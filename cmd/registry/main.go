@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/controller/registry"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/controller/registry/remote"
+)
+
+const (
+	defaultAddress   = ":50051"
+	defaultDirectory = "/registry"
+)
+
+// config flags defined globally so that they appear on the test binary as well
+var (
+	address = flag.String(
+		"address", defaultAddress, "address to serve the catalog on")
+
+	directory = flag.String(
+		"directory", defaultDirectory, "directory of catalog resources to serve")
+
+	debug = flag.Bool(
+		"debug", false, "use debug log level")
+)
+
+func main() {
+	// Parse the command-line flags.
+	flag.Parse()
+
+	if *debug {
+		log.SetLevel(log.DebugLevel)
+	}
+
+	catalog, err := registry.NewInMemoryFromDirectory(*directory)
+	if err != nil {
+		log.Fatalf("error loading catalog from %s: %s", *directory, err)
+	}
+
+	log.Infof("serving catalog from %s on %s", *directory, *address)
+	if err := http.ListenAndServe(*address, remote.NewServer(catalog)); err != nil {
+		log.Fatalf("error serving catalog: %s", err)
+	}
+}
@@ -0,0 +1,10 @@
+package main
+
+import (
+	"testing"
+)
+
+// Test started when the test binary is started. Only calls main.
+func TestRegistryMain(t *testing.T) {
+	main()
+}
@@ -58,6 +58,9 @@ func init() {
 	flag.StringVar(&options.Namespace,
 		"namespace", "", "namespace to restrict service scope")
 
+	flag.StringVar(&options.AuditSink,
+		"auditSink", "", "where to write a JSON record of every object the broker creates or deletes: a file path, \"stdout\", or an http(s) URL to POST each record to. If not set, no records are written.")
+
 	flag.BoolVar(&options.Debug,
 		"debug", false, "use debug log level")
 
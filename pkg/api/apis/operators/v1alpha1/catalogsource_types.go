@@ -1,6 +1,8 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -11,12 +13,52 @@ const (
 	CatalogSourceKind          = "CatalogSource"
 )
 
+const (
+	// SourceTypeInternal catalogs are loaded from a ConfigMap
+	SourceTypeInternal = "internal"
+	// SourceTypeGrpc catalogs are served by a registry server. The registry service protocol is JSON over HTTP; see
+	// the registry/remote package.
+	SourceTypeGrpc = "grpc"
+	// SourceTypeHTTP catalogs are loaded from a catalog archive published at a URL
+	SourceTypeHTTP = "http"
+)
+
 type CatalogSourceSpec struct {
 	Name       string   `json:"name"`
 	SourceType string   `json:"sourceType"`
 	ConfigMap  string   `json:"configMap,omitempty"`
 	Secrets    []string `json:"secrets,omitempty"`
 
+	// Priority orders the CatalogSources searched when resolving an InstallPlan: a CSV or CRD in more than one is
+	// taken from the one with the highest priority, then the first by name. Defaults to 0.
+	Priority int `json:"priority,omitempty"`
+
+	// ConfigMaps are more ConfigMaps an internal catalog is split across, merged with ConfigMap
+	ConfigMaps []string `json:"configMaps,omitempty"`
+	// ConfigMapSelector selects more ConfigMaps an internal catalog is split across, merged with ConfigMap and ConfigMaps
+	ConfigMapSelector *metav1.LabelSelector `json:"configMapSelector,omitempty"`
+
+	// Address is the host:port of the registry server of a grpc catalog
+	Address string `json:"address,omitempty"`
+
+	// URL is where the catalog archive of an http catalog is published: a tar archive, optionally gzipped, of a
+	// directory of catalog resources
+	URL string `json:"url,omitempty"`
+	// Checksum is the sha256 of the catalog archive, hex encoded. If empty, the archive must match the checksum
+	// published next to it, at the URL with ".sha256" appended.
+	Checksum string `json:"checksum,omitempty"`
+	// PollInterval is how often the catalog archive is checked for updates
+	PollInterval *metav1.Duration `json:"pollInterval,omitempty"`
+
+	// Verification, if set, requires the catalog content to be signed by one of the given keys
+	Verification *CatalogSourceVerification `json:"verification,omitempty"`
+
+	// Include, if set, hides every package channel it doesn't match. Like Exclude, it only applies to catalogs loaded
+	// into memory: internal and http catalogs.
+	Include *CatalogPackageFilter `json:"include,omitempty"`
+	// Exclude, if set, hides every package channel it matches
+	Exclude *CatalogPackageFilter `json:"exclude,omitempty"`
+
 	// Metadata
 	DisplayName string `json:"displayName,omitempty"`
 	Description string `json:"description,omitempty"`
@@ -24,9 +66,136 @@ type CatalogSourceSpec struct {
 	Icon        Icon   `json:"icon,omitempty"`
 }
 
+// CatalogSourceVerification lists the public keys trusted to sign a catalog's content
+type CatalogSourceVerification struct {
+	// PublicKeys are PEM encoded RSA or ECDSA public keys
+	PublicKeys []string `json:"publicKeys"`
+}
+
+// CatalogPackageFilter matches the channels of catalog packages. A channel matches if it matches every field that's
+// set; a filter with no fields set matches every channel.
+type CatalogPackageFilter struct {
+	// Packages are globs, as matched by path.Match, of package names
+	Packages []string `json:"packages,omitempty"`
+	// Channels are channel names
+	Channels []string `json:"channels,omitempty"`
+	// Maturities are maturities of the CSV at the head of the channel
+	Maturities []string `json:"maturities,omitempty"`
+	// Providers are provider names of the CSV at the head of the channel
+	Providers []string `json:"providers,omitempty"`
+}
+
 type CatalogSourceStatus struct {
+	// ConfigMapResource references the ConfigMap named by the spec's ConfigMap, as last loaded
 	ConfigMapResource *ConfigMapResourceReference `json:"configMapReference,omitempty"`
-	LastSync          metav1.Time                 `json:"lastSync,omitempty"`
+	// ConfigMapResources reference every ConfigMap the catalog was last loaded from
+	ConfigMapResources []ConfigMapResourceReference `json:"configMapReferences,omitempty"`
+	// Hash identifies the content of all the ConfigMaps the catalog was last loaded from
+	Hash string `json:"hash,omitempty"`
+	// Contents counts what's in the catalog in service
+	Contents *CatalogContentsStatus `json:"contents,omitempty"`
+	// LastSync is when the catalog was last loaded successfully
+	LastSync metav1.Time `json:"lastSync,omitempty"`
+	// LastAttempt is when the catalog was last loaded, successfully or not
+	LastAttempt  metav1.Time                `json:"lastAttempt,omitempty"`
+	Conditions   []CatalogSourceCondition   `json:"conditions,omitempty"`
+	Verification *CatalogVerificationStatus `json:"verification,omitempty"`
+	Archive      *CatalogArchiveStatus      `json:"archive,omitempty"`
+	// Filtered records what the catalog's Include and Exclude filters hide
+	Filtered *CatalogFilterStatus `json:"filtered,omitempty"`
+}
+
+// CatalogSourceConditionType describes the state of a CatalogSource
+type CatalogSourceConditionType string
+
+const (
+	// CatalogSourceHealthy is true if the catalog was loaded the last time it was synced
+	CatalogSourceHealthy CatalogSourceConditionType = "Healthy"
+)
+
+// CatalogSourceConditionReason is a camelcased reason for the state of a CatalogSource
+type CatalogSourceConditionReason string
+
+const (
+	CatalogSourceReasonLoaded             CatalogSourceConditionReason = "Loaded"
+	CatalogSourceReasonLoadFailed         CatalogSourceConditionReason = "LoadFailed"
+	CatalogSourceReasonVerificationFailed CatalogSourceConditionReason = "VerificationFailed"
+)
+
+// CatalogSourceCondition represents the state of a CatalogSource
+type CatalogSourceCondition struct {
+	Type               CatalogSourceConditionType   `json:"type,omitempty"`
+	Status             corev1.ConditionStatus       `json:"status,omitempty"` // True, False, or Unknown
+	LastTransitionTime metav1.Time                  `json:"lastTransitionTime,omitempty"`
+	Reason             CatalogSourceConditionReason `json:"reason,omitempty"`
+	Message            string                       `json:"message,omitempty"`
+}
+
+// SetCondition adds or updates a condition, using `Type` as merge key. The transition time is kept unless the
+// condition's status changed.
+func (s *CatalogSourceStatus) SetCondition(cond CatalogSourceCondition) CatalogSourceCondition {
+	cond.LastTransitionTime = now()
+	for i, existing := range s.Conditions {
+		if existing.Type != cond.Type {
+			continue
+		}
+		if existing.Status == cond.Status {
+			cond.LastTransitionTime = existing.LastTransitionTime
+		}
+		s.Conditions[i] = cond
+		return cond
+	}
+	s.Conditions = append(s.Conditions, cond)
+	return cond
+}
+
+// GetCondition returns the condition of the given type, if set
+func (s *CatalogSourceStatus) GetCondition(condType CatalogSourceConditionType) (CatalogSourceCondition, bool) {
+	for _, cond := range s.Conditions {
+		if cond.Type == condType {
+			return cond, true
+		}
+	}
+	return CatalogSourceCondition{}, false
+}
+
+// CatalogFilterStatus records what the package filters of a CatalogSource hide
+type CatalogFilterStatus struct {
+	// Packages are the packages with every channel hidden
+	Packages []string `json:"packages,omitempty"`
+	// Channels are the channels hidden from packages that still have channels exposed, as package/channel
+	Channels []string `json:"channels,omitempty"`
+	// ClusterServiceVersions counts the CSVs hidden because they're only in hidden channels
+	ClusterServiceVersions int `json:"clusterServiceVersions,omitempty"`
+	// CustomResourceDefinitions counts the CRDs hidden because they're only owned by hidden CSVs
+	CustomResourceDefinitions int `json:"customResourceDefinitions,omitempty"`
+}
+
+// CatalogContentsStatus counts the resources in a catalog
+type CatalogContentsStatus struct {
+	Packages                  int `json:"packages"`
+	ClusterServiceVersions    int `json:"clusterServiceVersions"`
+	CustomResourceDefinitions int `json:"customResourceDefinitions"`
+}
+
+// CatalogArchiveStatus reports the catalog archive in service for an http catalog
+type CatalogArchiveStatus struct {
+	// Revision is the sha256 of the catalog archive in service
+	Revision string `json:"revision,omitempty"`
+	// LastPoll is when the catalog archive was last checked for updates
+	LastPoll *metav1.Time `json:"lastPoll,omitempty"`
+	// Message describes why the last check for updates failed, if it did
+	Message string `json:"message,omitempty"`
+}
+
+// CatalogVerificationStatus reports the result of verifying a catalog's signature
+type CatalogVerificationStatus struct {
+	// Verified is true if the catalog content in service was signed by a trusted key
+	Verified bool `json:"verified"`
+	// Message describes why the most recently loaded catalog content was rejected, if it was
+	Message string `json:"message,omitempty"`
+	// LastFailure is when catalog content last failed verification
+	LastFailure *metav1.Time `json:"lastFailure,omitempty"`
 }
 type ConfigMapResourceReference struct {
 	Name      string `json:"name"`
@@ -14,6 +14,7 @@ var obsoleteReasons = map[ConditionReason]struct{}{
 func (c *ClusterServiceVersion) SetPhase(phase ClusterServiceVersionPhase, reason ConditionReason, message string) {
 	c.Status.LastUpdateTime = metav1.Now()
 	if c.Status.Phase != phase {
+		c.updateInstallProgress(phase)
 		c.Status.Phase = phase
 		c.Status.LastTransitionTime = metav1.Now()
 	}
@@ -40,6 +41,44 @@ func (c *ClusterServiceVersion) SetPhase(phase ClusterServiceVersionPhase, reaso
 	}
 }
 
+// updateInstallProgress starts tracking install progress when entering the Installing phase, and records the
+// time spent installing when leaving it
+func (c *ClusterServiceVersion) updateInstallProgress(next ClusterServiceVersionPhase) {
+	now := metav1.Now()
+	if next == CSVPhaseInstalling {
+		c.Status.InstallProgress = &InstallProgress{StartTime: now}
+		return
+	}
+	if c.Status.Phase == CSVPhaseInstalling && c.Status.InstallProgress != nil {
+		c.Status.InstallProgress.Duration = &metav1.Duration{Duration: now.Sub(c.InstallStartTime().Time)}
+	}
+}
+
+// InstallStartTime returns the time the CSV entered the Installing phase
+func (c *ClusterServiceVersion) InstallStartTime() metav1.Time {
+	if c.Status.InstallProgress != nil && !c.Status.InstallProgress.StartTime.IsZero() {
+		return c.Status.InstallProgress.StartTime
+	}
+	// CSVs that started installing before progress was tracked
+	return c.Status.LastTransitionTime
+}
+
+// InstallTimedOut reports if the CSV has been installing for longer than its install timeout
+func (c *ClusterServiceVersion) InstallTimedOut(now metav1.Time) bool {
+	if c.Status.Phase != CSVPhaseInstalling || c.Spec.InstallTimeout == nil {
+		return false
+	}
+	return now.Sub(c.InstallStartTime().Time) > c.Spec.InstallTimeout.Duration
+}
+
+// SetDeploymentProgress records the rollout state of the CSV's deployments
+func (c *ClusterServiceVersion) SetDeploymentProgress(deployments []DeploymentProgress) {
+	if c.Status.InstallProgress == nil {
+		c.Status.InstallProgress = &InstallProgress{}
+	}
+	c.Status.InstallProgress.Deployments = deployments
+}
+
 // SetRequirementStatus adds the status of all requirements to the CSV status
 func (c *ClusterServiceVersion) SetRequirementStatus(statuses []RequirementStatus) {
 	c.Status.RequirementStatus = statuses
@@ -2,8 +2,10 @@ package v1alpha1
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestSetRequirementStatus(t *testing.T) {
@@ -57,6 +59,79 @@ func TestSetPhase(t *testing.T) {
 	}
 }
 
+func TestSetPhaseRecordsInstallProgress(t *testing.T) {
+	csv := ClusterServiceVersion{
+		Status: ClusterServiceVersionStatus{
+			Phase: CSVPhaseInstallReady,
+		},
+	}
+
+	csv.SetPhase(CSVPhaseInstalling, "test", "test")
+	require.NotNil(t, csv.Status.InstallProgress)
+	require.False(t, csv.Status.InstallProgress.StartTime.IsZero())
+	require.Nil(t, csv.Status.InstallProgress.Duration)
+
+	csv.SetDeploymentProgress([]DeploymentProgress{{Name: "dep", Replicas: 1}})
+	csv.SetPhase(CSVPhaseInstalling, "test", "still installing")
+	require.Len(t, csv.Status.InstallProgress.Deployments, 1)
+
+	csv.SetPhase(CSVPhaseSucceeded, "test", "test")
+	require.NotNil(t, csv.Status.InstallProgress.Duration)
+}
+
+func TestInstallTimedOut(t *testing.T) {
+	start := metav1.NewTime(time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC))
+	tests := []struct {
+		phase       ClusterServiceVersionPhase
+		timeout     *metav1.Duration
+		now         metav1.Time
+		out         bool
+		description string
+	}{
+		{
+			phase:       CSVPhaseInstalling,
+			now:         metav1.NewTime(start.Add(time.Hour)),
+			out:         false,
+			description: "NoTimeout",
+		},
+		{
+			phase:       CSVPhaseInstalling,
+			timeout:     &metav1.Duration{Duration: 10 * time.Minute},
+			now:         metav1.NewTime(start.Add(5 * time.Minute)),
+			out:         false,
+			description: "WithinTimeout",
+		},
+		{
+			phase:       CSVPhaseInstalling,
+			timeout:     &metav1.Duration{Duration: 10 * time.Minute},
+			now:         metav1.NewTime(start.Add(15 * time.Minute)),
+			out:         true,
+			description: "TimedOut",
+		},
+		{
+			phase:       CSVPhaseSucceeded,
+			timeout:     &metav1.Duration{Duration: 10 * time.Minute},
+			now:         metav1.NewTime(start.Add(15 * time.Minute)),
+			out:         false,
+			description: "NotInstalling",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			csv := ClusterServiceVersion{
+				Spec: ClusterServiceVersionSpec{
+					InstallTimeout: tt.timeout,
+				},
+				Status: ClusterServiceVersionStatus{
+					Phase:           tt.phase,
+					InstallProgress: &InstallProgress{StartTime: start},
+				},
+			}
+			require.Equal(t, tt.out, csv.InstallTimedOut(tt.now))
+		})
+	}
+}
+
 func TestIsObsolete(t *testing.T) {
 	tests := []struct {
 		currentPhase      ClusterServiceVersionPhase
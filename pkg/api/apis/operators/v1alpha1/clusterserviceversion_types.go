@@ -88,6 +88,11 @@ type ClusterServiceVersionSpec struct {
 	// +optional
 	Replaces string `json:"replaces,omitempty"`
 
+	// The names of CSVs this one can upgrade directly from without replacing them, when a Subscription switches to a
+	// channel that doesn't hold the installed CSV.
+	// +optional
+	Skips []string `json:"skips,omitempty"`
+
 	// Map of string keys and values that can be used to organize and categorize
 	// (scope and select) objects.
 	// +optional
@@ -101,6 +106,11 @@ type ClusterServiceVersionSpec struct {
 	// Label selector for related resources.
 	// +optional
 	Selector *metav1.LabelSelector `json:"selector,omitempty" protobuf:"bytes,2,opt,name=selector"`
+
+	// Maximum amount of time the ClusterServiceVersion may spend in the Installing phase before it is
+	// marked as Failed. No timeout is enforced if unset.
+	// +optional
+	InstallTimeout *metav1.Duration `json:"installTimeout,omitempty"`
 }
 
 type Maintainer struct {
@@ -147,18 +157,20 @@ const (
 type ConditionReason string
 
 const (
-	CSVReasonRequirementsUnknown ConditionReason = "RequirementsUnknown"
-	CSVReasonRequirementsNotMet  ConditionReason = "RequirementsNotMet"
-	CSVReasonRequirementsMet     ConditionReason = "AllRequirementsMet"
-	CSVReasonOwnerConflict       ConditionReason = "OwnerConflict"
-	CSVReasonComponentFailed     ConditionReason = "InstallComponentFailed"
-	CSVReasonInvalidStrategy     ConditionReason = "InvalidInstallStrategy"
-	CSVReasonWaiting             ConditionReason = "InstallWaiting"
-	CSVReasonInstallSuccessful   ConditionReason = "InstallSucceeded"
-	CSVReasonInstallCheckFailed  ConditionReason = "InstallCheckFailed"
-	CSVReasonComponentUnhealthy  ConditionReason = "ComponentUnhealthy"
-	CSVReasonBeingReplaced       ConditionReason = "BeingReplaced"
-	CSVReasonReplaced            ConditionReason = "Replaced"
+	CSVReasonRequirementsUnknown     ConditionReason = "RequirementsUnknown"
+	CSVReasonRequirementsNotMet      ConditionReason = "RequirementsNotMet"
+	CSVReasonRequirementsMet         ConditionReason = "AllRequirementsMet"
+	CSVReasonOwnerConflict           ConditionReason = "OwnerConflict"
+	CSVReasonComponentFailed         ConditionReason = "InstallComponentFailed"
+	CSVReasonInvalidStrategy         ConditionReason = "InvalidInstallStrategy"
+	CSVReasonWaiting                 ConditionReason = "InstallWaiting"
+	CSVReasonInstallSuccessful       ConditionReason = "InstallSucceeded"
+	CSVReasonInstallCheckFailed      ConditionReason = "InstallCheckFailed"
+	CSVReasonComponentUnhealthy      ConditionReason = "ComponentUnhealthy"
+	CSVReasonComponentFailing        ConditionReason = "InstallComponentFailing"
+	CSVReasonInsufficientPermissions ConditionReason = "InsufficientPermissions"
+	CSVReasonBeingReplaced           ConditionReason = "BeingReplaced"
+	CSVReasonReplaced                ConditionReason = "Replaced"
 )
 
 // Conditions appear in the status as a record of state transitions on the ClusterServiceVersion
@@ -191,6 +203,27 @@ func (csv ClusterServiceVersion) OwnsCRD(name string) bool {
 	return false
 }
 
+// DeploymentProgress reports the rollout state of a single deployment created by an install strategy
+type DeploymentProgress struct {
+	Name              string `json:"name"`
+	Replicas          int32  `json:"replicas"`
+	UpdatedReplicas   int32  `json:"updatedReplicas"`
+	ReadyReplicas     int32  `json:"readyReplicas"`
+	AvailableReplicas int32  `json:"availableReplicas"`
+}
+
+// InstallProgress records how far along the install strategy of a ClusterServiceVersion is
+type InstallProgress struct {
+	// Time the ClusterServiceVersion entered the Installing phase
+	StartTime metav1.Time `json:"startTime,omitempty"`
+	// Time spent in the Installing phase, set once the ClusterServiceVersion leaves it
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+	// Rollout state of each deployment in the install strategy
+	// +optional
+	Deployments []DeploymentProgress `json:"deployments,omitempty"`
+}
+
 type RequirementStatus struct {
 	Group   string `json:"group"`
 	Version string `json:"version"`
@@ -222,6 +255,9 @@ type ClusterServiceVersionStatus struct {
 	Conditions []ClusterServiceVersionCondition `json:"conditions,omitempty"`
 	// The status of each requirement for this CSV
 	RequirementStatus []RequirementStatus `json:"requirementStatus,omitempty"`
+	// Progress of the install strategy
+	// +optional
+	InstallProgress *InstallProgress `json:"installProgress,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -34,6 +34,9 @@ type InstallPlanSpec struct {
 	ClusterServiceVersionNames []string `json:"clusterServiceVersionNames"`
 	Approval                   Approval `json:"approval"`
 	Approved                   bool     `json:"approved"`
+	// ServiceAccountName, if set, names a ServiceAccount in the InstallPlan's namespace whose permissions are used to
+	// execute the plan
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
 }
 
 // InstallPlanPhase is the current status of a InstallPlan as a whole.
@@ -43,6 +46,7 @@ const (
 	InstallPlanPhaseNone             InstallPlanPhase = ""
 	InstallPlanPhasePlanning         InstallPlanPhase = "Planning"
 	InstallPlanPhaseRequiresApproval InstallPlanPhase = "RequiresApproval"
+	InstallPlanPhaseWaiting          InstallPlanPhase = "Waiting"
 	InstallPlanPhaseInstalling       InstallPlanPhase = "Installing"
 	InstallPlanPhaseComplete         InstallPlanPhase = "Complete"
 	InstallPlanPhaseFailed           InstallPlanPhase = "Failed"
@@ -60,10 +64,11 @@ const (
 type InstallPlanConditionReason string
 
 const (
-	InstallPlanReasonPlanUnknown        InstallPlanConditionReason = "PlanUnknown"
-	InstallPlanReasonInstallCheckFailed InstallPlanConditionReason = "InstallCheckFailed"
-	InstallPlanReasonDependencyConflict InstallPlanConditionReason = "DependenciesConflict"
-	InstallPlanReasonComponentFailed    InstallPlanConditionReason = "InstallComponentFailed"
+	InstallPlanReasonPlanUnknown             InstallPlanConditionReason = "PlanUnknown"
+	InstallPlanReasonInstallCheckFailed      InstallPlanConditionReason = "InstallCheckFailed"
+	InstallPlanReasonDependencyConflict      InstallPlanConditionReason = "DependenciesConflict"
+	InstallPlanReasonComponentFailed         InstallPlanConditionReason = "InstallComponentFailed"
+	InstallPlanReasonInsufficientPermissions InstallPlanConditionReason = "InsufficientPermissions"
 )
 
 // StepStatus is the current status of a particular resource an in
@@ -90,6 +95,25 @@ type InstallPlanStatus struct {
 	Conditions     []InstallPlanCondition `json:"conditions,omitempty"`
 	CatalogSources []string               `json:"catalogSources"`
 	Plan           []Step                 `json:"plan,omitempty"`
+
+	// Hold tells why an approved InstallPlan is Waiting: upgrades are frozen, or no maintenance window is open
+	Hold *InstallPlanHold `json:"hold,omitempty"`
+}
+
+// InstallPlanHoldReason is a camelcased reason for holding an InstallPlan
+type InstallPlanHoldReason string
+
+const (
+	InstallPlanHoldUpgradesFrozen          InstallPlanHoldReason = "UpgradesFrozen"
+	InstallPlanHoldMaintenanceWindowClosed InstallPlanHoldReason = "MaintenanceWindowClosed"
+)
+
+// InstallPlanHold records why an approved InstallPlan isn't executed yet
+type InstallPlanHold struct {
+	Reason  InstallPlanHoldReason `json:"reason"`
+	Message string                `json:"message,omitempty"`
+	// Until is when the next maintenance window opens; unset while upgrades are frozen
+	Until *metav1.Time `json:"until,omitempty"`
 }
 
 // InstallPlanCondition represents the overall status of the execution of
@@ -159,6 +183,9 @@ type StepResource struct {
 	Kind                   string `json:"kind"`
 	Name                   string `json:"name"`
 	Manifest               string `json:"manifest,omitempty"`
+
+	// SourceReason tells why the resource was taken from its catalog source
+	SourceReason string `json:"sourceReason,omitempty"`
 }
 
 // NewStepResourceFromCSV creates an unresolved Step for the provided CSV.
@@ -42,6 +42,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&SubscriptionList{},
 		&ClusterServiceVersion{},
 		&ClusterServiceVersionList{},
+		&UpgradePolicy{},
+		&UpgradePolicyList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
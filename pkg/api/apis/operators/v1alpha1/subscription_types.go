@@ -2,6 +2,7 @@ package v1alpha1
 
 import (
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
@@ -21,6 +22,22 @@ const (
 	SubscriptionStateAtLatest         = "AtLatestKnown"
 )
 
+// SubscriptionUninstallPolicy is what's removed when a Subscription is deleted
+type SubscriptionUninstallPolicy string
+
+const (
+	// UninstallPolicyOrphan leaves the installed operator in place
+	UninstallPolicyOrphan SubscriptionUninstallPolicy = "Orphan"
+	// UninstallPolicyOperator removes the installed CSV and the resources it owns
+	UninstallPolicyOperator SubscriptionUninstallPolicy = "Operator"
+	// UninstallPolicyAll removes the installed CSV and the resources it owns, and then the CRDs the CSV owns, once no
+	// custom resources of their kinds remain
+	UninstallPolicyAll SubscriptionUninstallPolicy = "All"
+)
+
+// SubscriptionUninstallFinalizer holds a deleted Subscription until its uninstall policy is carried out
+const SubscriptionUninstallFinalizer = "operators.coreos.com/uninstall"
+
 // SubscriptionSpec defines an Application that can be installed
 type SubscriptionSpec struct {
 	CatalogSource          string   `json:"source"`
@@ -29,6 +46,17 @@ type SubscriptionSpec struct {
 	Channel                string   `json:"channel,omitempty"`
 	StartingCSV            string   `json:"startingCSV,omitempty"`
 	InstallPlanApproval    Approval `json:"installPlanApproval,omitempty"`
+	// ServiceAccountName, if set, names a ServiceAccount in the Subscription's namespace whose permissions are used
+	// to install the Subscription's InstallPlans and ClusterServiceVersions
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+	// VersionRange, if set, is a semver range, like ">=0.9.0 <0.10.0", "0.9.x" or "<=2.0.0", that caps the versions of
+	// the CSVs the Subscription installs and upgrades to
+	VersionRange string `json:"versionRange,omitempty"`
+	// MaintenanceWindows, if set, hold the Subscription's automatically approved InstallPlans while none of the
+	// windows is open
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+	// UninstallPolicy is what's removed when the Subscription is deleted. Defaults to Orphan.
+	UninstallPolicy SubscriptionUninstallPolicy `json:"uninstallPolicy,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -47,6 +75,145 @@ type SubscriptionStatus struct {
 
 	State       SubscriptionState `json:"state,omitempty"`
 	LastUpdated metav1.Time       `json:"lastUpdated"`
+
+	// Channel is the channel CurrentCSV was taken from
+	Channel string `json:"channel,omitempty"`
+	// ChannelSwitch records the last change of the Subscription's channel
+	ChannelSwitch *SubscriptionChannelSwitch `json:"channelSwitch,omitempty"`
+
+	// InstalledCSVName is the CSV in the Subscription's namespace that's installed. It only moves to CurrentCSV once that
+	// CSV exists, so while an upgrade is pending it still names the CSV being upgraded from.
+	InstalledCSVName string `json:"installedCSVName,omitempty"`
+	// InstalledCSVPhase mirrors the phase of the CSV named by InstalledCSVName
+	InstalledCSVPhase ClusterServiceVersionPhase `json:"installedCSVPhase,omitempty"`
+	// InstalledCSVReason mirrors the reason for the phase of the CSV named by InstalledCSVName
+	InstalledCSVReason ConditionReason `json:"installedCSVReason,omitempty"`
+
+	// BlockedCSV is the CSV in the channel that would replace CurrentCSV, but is outside the spec's VersionRange
+	BlockedCSV string `json:"blockedCSV,omitempty"`
+	// BlockedVersion is the version of BlockedCSV
+	BlockedVersion string `json:"blockedVersion,omitempty"`
+
+	Conditions []SubscriptionCondition `json:"conditions,omitempty"`
+
+	// Uninstall records the progress of carrying out the uninstall policy of a deleted Subscription
+	Uninstall *SubscriptionUninstall `json:"uninstall,omitempty"`
+}
+
+// SubscriptionConditionType describes the state of a Subscription
+type SubscriptionConditionType string
+
+const (
+	// SubscriptionCatalogSourceUnavailable is true if the Subscription's catalog source is unknown or not visible to
+	// the Subscription's namespace
+	SubscriptionCatalogSourceUnavailable SubscriptionConditionType = "CatalogSourceUnavailable"
+	// SubscriptionResolutionFailed is true if the Subscription's package and channel couldn't be resolved to a CSV
+	SubscriptionResolutionFailed SubscriptionConditionType = "ResolutionFailed"
+	// SubscriptionInstallPlanPending is true if the Subscription's InstallPlan hasn't completed or failed yet
+	SubscriptionInstallPlanPending SubscriptionConditionType = "InstallPlanPending"
+	// SubscriptionInstallPlanFailed is true if the Subscription's InstallPlan failed
+	SubscriptionInstallPlanFailed SubscriptionConditionType = "InstallPlanFailed"
+	// SubscriptionCSVFailed is true if the installed CSV failed
+	SubscriptionCSVFailed SubscriptionConditionType = "CSVFailed"
+	// SubscriptionUpgradeBlocked is true if a newer CSV is available in the channel, but outside the Subscription's
+	// version range
+	SubscriptionUpgradeBlocked SubscriptionConditionType = "UpgradeBlocked"
+	// SubscriptionHealthy is true if the installed CSV succeeded and no failure condition is true
+	SubscriptionHealthy SubscriptionConditionType = "Healthy"
+)
+
+// SubscriptionConditionReason is a camelcased reason for the state of a Subscription
+type SubscriptionConditionReason string
+
+const (
+	SubscriptionReasonCatalogSourceNotFound   SubscriptionConditionReason = "CatalogSourceNotFound"
+	SubscriptionReasonCatalogSourceNotVisible SubscriptionConditionReason = "CatalogSourceNotVisible"
+	SubscriptionReasonPackageNotFound         SubscriptionConditionReason = "PackageNotFound"
+	SubscriptionReasonChannelSwitchFailed     SubscriptionConditionReason = "ChannelSwitchFailed"
+	SubscriptionReasonInvalidVersionRange     SubscriptionConditionReason = "InvalidVersionRange"
+	SubscriptionReasonNoVersionInRange        SubscriptionConditionReason = "NoVersionInRange"
+	SubscriptionReasonOutsideVersionRange     SubscriptionConditionReason = "OutsideVersionRange"
+	SubscriptionReasonInstallPlanNotFound     SubscriptionConditionReason = "InstallPlanNotFound"
+	SubscriptionReasonCSVNotFound             SubscriptionConditionReason = "CSVNotFound"
+	SubscriptionReasonCSVNotSucceeded         SubscriptionConditionReason = "CSVNotSucceeded"
+	SubscriptionReasonCSVSucceeded            SubscriptionConditionReason = "CSVSucceeded"
+)
+
+// SubscriptionCondition represents the state of a Subscription
+type SubscriptionCondition struct {
+	Type               SubscriptionConditionType   `json:"type,omitempty"`
+	Status             corev1.ConditionStatus      `json:"status,omitempty"` // True, False, or Unknown
+	LastTransitionTime metav1.Time                 `json:"lastTransitionTime,omitempty"`
+	Reason             SubscriptionConditionReason `json:"reason,omitempty"`
+	Message            string                      `json:"message,omitempty"`
+}
+
+// SetCondition adds or updates a condition, using `Type` as merge key. The transition time is kept unless the
+// condition's status changed.
+func (s *SubscriptionStatus) SetCondition(cond SubscriptionCondition) SubscriptionCondition {
+	cond.LastTransitionTime = now()
+	for i, existing := range s.Conditions {
+		if existing.Type != cond.Type {
+			continue
+		}
+		if existing.Status == cond.Status {
+			cond.LastTransitionTime = existing.LastTransitionTime
+		}
+		s.Conditions[i] = cond
+		return cond
+	}
+	s.Conditions = append(s.Conditions, cond)
+	return cond
+}
+
+// GetCondition returns the condition of the given type, if set
+func (s *SubscriptionStatus) GetCondition(condType SubscriptionConditionType) (SubscriptionCondition, bool) {
+	for _, cond := range s.Conditions {
+		if cond.Type == condType {
+			return cond, true
+		}
+	}
+	return SubscriptionCondition{}, false
+}
+
+// SubscriptionChannelSwitch records a change of a Subscription's channel. The switch upgrades to the lowest CSV in the
+// new channel that replaces or skips the installed CSV, and never downgrades.
+type SubscriptionChannelSwitch struct {
+	// From is the channel switched from
+	From string `json:"from"`
+	// To is the channel switched to
+	To string `json:"to"`
+	// FromCSV is the CSV installed from the From channel
+	FromCSV string `json:"fromCSV"`
+	// ToCSV is the CSV in the To channel the switch upgrades to, unset until an upgrade edge is found
+	ToCSV string `json:"toCSV,omitempty"`
+	// Message tells why the switch hasn't happened
+	Message string `json:"message,omitempty"`
+}
+
+// SubscriptionUninstallPhase is the step of an uninstall in progress
+type SubscriptionUninstallPhase string
+
+const (
+	SubscriptionUninstallWaitingForCustomResources         SubscriptionUninstallPhase = "WaitingForCustomResources"
+	SubscriptionUninstallRemovingOperator                  SubscriptionUninstallPhase = "RemovingOperator"
+	SubscriptionUninstallRemovingCustomResourceDefinitions SubscriptionUninstallPhase = "RemovingCustomResourceDefinitions"
+	SubscriptionUninstallComplete                          SubscriptionUninstallPhase = "Complete"
+)
+
+// SubscriptionUninstall records the progress of uninstalling a deleted Subscription's operator. Under the All policy
+// it waits for the custom resources of the CSVs' owned CRDs to be deleted, so that the operator can still handle
+// their deletion, then removes the CSVs, and then the CRDs. Under the Operator policy it only removes the CSVs.
+type SubscriptionUninstall struct {
+	// Policy is the uninstall policy of the Subscription when it was deleted
+	Policy SubscriptionUninstallPolicy `json:"policy"`
+	Phase  SubscriptionUninstallPhase  `json:"phase"`
+	// ClusterServiceVersions are the CSVs of the Subscription's replacement chain being removed, newest first
+	ClusterServiceVersions []string `json:"clusterServiceVersions,omitempty"`
+	// CustomResourceDefinitions are the CRDs owned by ClusterServiceVersions that the All policy removes
+	CustomResourceDefinitions []string `json:"customResourceDefinitions,omitempty"`
+	// Message tells what the uninstall is waiting for
+	Message string `json:"message,omitempty"`
 }
 
 type InstallPlanReference struct {
@@ -71,3 +238,15 @@ func (s *Subscription) GetInstallPlanApproval() Approval {
 	}
 	return ApprovalAutomatic
 }
+
+// GetUninstallPolicy gets the configured uninstall policy or the default
+func (s *Subscription) GetUninstallPolicy() SubscriptionUninstallPolicy {
+	if s.Spec == nil {
+		return UninstallPolicyOrphan
+	}
+	switch s.Spec.UninstallPolicy {
+	case UninstallPolicyOperator, UninstallPolicyAll:
+		return s.Spec.UninstallPolicy
+	}
+	return UninstallPolicyOrphan
+}
@@ -0,0 +1,51 @@
+package v1alpha1
+
+import (
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	UpgradePolicyCRDAPIVersion = operators.GroupName + "/" + GroupVersion
+	UpgradePolicyKind          = "UpgradePolicy"
+)
+
+// MaintenanceWindow is a recurring window of time that InstallPlans may be executed in
+type MaintenanceWindow struct {
+	// Schedule is a cron schedule of the minutes the window opens at, like "0 22 * * 1-5" for 22:00 on weekdays
+	Schedule string `json:"schedule"`
+	// Duration is how long the window stays open once it opens
+	Duration metav1.Duration `json:"duration"`
+	// TimeZone is the IANA time zone of the schedule, like "Europe/Berlin". Defaults to UTC.
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+type UpgradePolicySpec struct {
+	// Freeze holds every InstallPlan, approved manually or not, until it's unset
+	Freeze bool `json:"freeze,omitempty"`
+	// FreezeMessage tells why upgrades are frozen
+	FreezeMessage string `json:"freezeMessage,omitempty"`
+
+	// MaintenanceWindows, if set, hold automatically approved InstallPlans while none of the windows is open
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+}
+
+// UpgradePolicy constrains when InstallPlans are executed. Only UpgradePolicies in the catalog operator's namespace
+// are honored, and they apply to InstallPlans in every namespace.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient
+// +genclient:noStatus
+type UpgradePolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+
+	Spec UpgradePolicySpec `json:"spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type UpgradePolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []UpgradePolicy `json:"items"`
+}
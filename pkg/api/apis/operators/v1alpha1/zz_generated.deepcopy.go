@@ -94,6 +94,109 @@ func (in *CRDResourceReference) DeepCopy() *CRDResourceReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CatalogArchiveStatus) DeepCopyInto(out *CatalogArchiveStatus) {
+	*out = *in
+	if in.LastPoll != nil {
+		in, out := &in.LastPoll, &out.LastPoll
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.Time)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CatalogArchiveStatus.
+func (in *CatalogArchiveStatus) DeepCopy() *CatalogArchiveStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CatalogArchiveStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CatalogContentsStatus) DeepCopyInto(out *CatalogContentsStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CatalogContentsStatus.
+func (in *CatalogContentsStatus) DeepCopy() *CatalogContentsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CatalogContentsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CatalogFilterStatus) DeepCopyInto(out *CatalogFilterStatus) {
+	*out = *in
+	if in.Packages != nil {
+		in, out := &in.Packages, &out.Packages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Channels != nil {
+		in, out := &in.Channels, &out.Channels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CatalogFilterStatus.
+func (in *CatalogFilterStatus) DeepCopy() *CatalogFilterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CatalogFilterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CatalogPackageFilter) DeepCopyInto(out *CatalogPackageFilter) {
+	*out = *in
+	if in.Packages != nil {
+		in, out := &in.Packages, &out.Packages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Channels != nil {
+		in, out := &in.Channels, &out.Channels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Maturities != nil {
+		in, out := &in.Maturities, &out.Maturities
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Providers != nil {
+		in, out := &in.Providers, &out.Providers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CatalogPackageFilter.
+func (in *CatalogPackageFilter) DeepCopy() *CatalogPackageFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(CatalogPackageFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CatalogSource) DeepCopyInto(out *CatalogSource) {
 	*out = *in
@@ -122,6 +225,23 @@ func (in *CatalogSource) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CatalogSourceCondition) DeepCopyInto(out *CatalogSourceCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CatalogSourceCondition.
+func (in *CatalogSourceCondition) DeepCopy() *CatalogSourceCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(CatalogSourceCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CatalogSourceList) DeepCopyInto(out *CatalogSourceList) {
 	*out = *in
@@ -163,6 +283,56 @@ func (in *CatalogSourceSpec) DeepCopyInto(out *CatalogSourceSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ConfigMaps != nil {
+		in, out := &in.ConfigMaps, &out.ConfigMaps
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ConfigMapSelector != nil {
+		in, out := &in.ConfigMapSelector, &out.ConfigMapSelector
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.LabelSelector)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.PollInterval != nil {
+		in, out := &in.PollInterval, &out.PollInterval
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.Duration)
+			**out = **in
+		}
+	}
+	if in.Verification != nil {
+		in, out := &in.Verification, &out.Verification
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(CatalogSourceVerification)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Include != nil {
+		in, out := &in.Include, &out.Include
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(CatalogPackageFilter)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(CatalogPackageFilter)
+			(*in).DeepCopyInto(*out)
+		}
+	}
 	out.Icon = in.Icon
 	return
 }
@@ -189,7 +359,56 @@ func (in *CatalogSourceStatus) DeepCopyInto(out *CatalogSourceStatus) {
 			**out = **in
 		}
 	}
+	if in.ConfigMapResources != nil {
+		in, out := &in.ConfigMapResources, &out.ConfigMapResources
+		*out = make([]ConfigMapResourceReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Contents != nil {
+		in, out := &in.Contents, &out.Contents
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(CatalogContentsStatus)
+			**out = **in
+		}
+	}
 	in.LastSync.DeepCopyInto(&out.LastSync)
+	in.LastAttempt.DeepCopyInto(&out.LastAttempt)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]CatalogSourceCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Verification != nil {
+		in, out := &in.Verification, &out.Verification
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(CatalogVerificationStatus)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Archive != nil {
+		in, out := &in.Archive, &out.Archive
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(CatalogArchiveStatus)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Filtered != nil {
+		in, out := &in.Filtered, &out.Filtered
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(CatalogFilterStatus)
+			(*in).DeepCopyInto(*out)
+		}
+	}
 	return
 }
 
@@ -203,6 +422,52 @@ func (in *CatalogSourceStatus) DeepCopy() *CatalogSourceStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CatalogSourceVerification) DeepCopyInto(out *CatalogSourceVerification) {
+	*out = *in
+	if in.PublicKeys != nil {
+		in, out := &in.PublicKeys, &out.PublicKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CatalogSourceVerification.
+func (in *CatalogSourceVerification) DeepCopy() *CatalogSourceVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(CatalogSourceVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CatalogVerificationStatus) DeepCopyInto(out *CatalogVerificationStatus) {
+	*out = *in
+	if in.LastFailure != nil {
+		in, out := &in.LastFailure, &out.LastFailure
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.Time)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CatalogVerificationStatus.
+func (in *CatalogVerificationStatus) DeepCopy() *CatalogVerificationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CatalogVerificationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterServiceVersion) DeepCopyInto(out *ClusterServiceVersion) {
 	*out = *in
@@ -309,6 +574,11 @@ func (in *ClusterServiceVersionSpec) DeepCopyInto(out *ClusterServiceVersionSpec
 		*out = make([]Icon, len(*in))
 		copy(*out, *in)
 	}
+	if in.Skips != nil {
+		in, out := &in.Skips, &out.Skips
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.Labels != nil {
 		in, out := &in.Labels, &out.Labels
 		*out = make(map[string]string, len(*in))
@@ -332,6 +602,15 @@ func (in *ClusterServiceVersionSpec) DeepCopyInto(out *ClusterServiceVersionSpec
 			(*in).DeepCopyInto(*out)
 		}
 	}
+	if in.InstallTimeout != nil {
+		in, out := &in.InstallTimeout, &out.InstallTimeout
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.Duration)
+			**out = **in
+		}
+	}
 	return
 }
 
@@ -362,6 +641,15 @@ func (in *ClusterServiceVersionStatus) DeepCopyInto(out *ClusterServiceVersionSt
 		*out = make([]RequirementStatus, len(*in))
 		copy(*out, *in)
 	}
+	if in.InstallProgress != nil {
+		in, out := &in.InstallProgress, &out.InstallProgress
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(InstallProgress)
+			(*in).DeepCopyInto(*out)
+		}
+	}
 	return
 }
 
@@ -421,6 +709,22 @@ func (in *CustomResourceDefinitions) DeepCopy() *CustomResourceDefinitions {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentProgress) DeepCopyInto(out *DeploymentProgress) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentProgress.
+func (in *DeploymentProgress) DeepCopy() *DeploymentProgress {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentProgress)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Icon) DeepCopyInto(out *Icon) {
 	*out = *in
@@ -483,6 +787,31 @@ func (in *InstallPlanCondition) DeepCopy() *InstallPlanCondition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstallPlanHold) DeepCopyInto(out *InstallPlanHold) {
+	*out = *in
+	if in.Until != nil {
+		in, out := &in.Until, &out.Until
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.Time)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstallPlanHold.
+func (in *InstallPlanHold) DeepCopy() *InstallPlanHold {
+	if in == nil {
+		return nil
+	}
+	out := new(InstallPlanHold)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InstallPlanList) DeepCopyInto(out *InstallPlanList) {
 	*out = *in
@@ -573,6 +902,15 @@ func (in *InstallPlanStatus) DeepCopyInto(out *InstallPlanStatus) {
 		*out = make([]Step, len(*in))
 		copy(*out, *in)
 	}
+	if in.Hold != nil {
+		in, out := &in.Hold, &out.Hold
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(InstallPlanHold)
+			(*in).DeepCopyInto(*out)
+		}
+	}
 	return
 }
 
@@ -586,6 +924,37 @@ func (in *InstallPlanStatus) DeepCopy() *InstallPlanStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstallProgress) DeepCopyInto(out *InstallProgress) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.Duration)
+			**out = **in
+		}
+	}
+	if in.Deployments != nil {
+		in, out := &in.Deployments, &out.Deployments
+		*out = make([]DeploymentProgress, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstallProgress.
+func (in *InstallProgress) DeepCopy() *InstallProgress {
+	if in == nil {
+		return nil
+	}
+	out := new(InstallProgress)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Maintainer) DeepCopyInto(out *Maintainer) {
 	*out = *in
@@ -602,6 +971,23 @@ func (in *Maintainer) DeepCopy() *Maintainer {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
+	*out = *in
+	out.Duration = in.Duration
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NamedInstallStrategy) DeepCopyInto(out *NamedInstallStrategy) {
 	*out = *in
@@ -751,7 +1137,7 @@ func (in *Subscription) DeepCopyInto(out *Subscription) {
 			*out = nil
 		} else {
 			*out = new(SubscriptionSpec)
-			**out = **in
+			(*in).DeepCopyInto(*out)
 		}
 	}
 	in.Status.DeepCopyInto(&out.Status)
@@ -776,6 +1162,39 @@ func (in *Subscription) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubscriptionChannelSwitch) DeepCopyInto(out *SubscriptionChannelSwitch) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubscriptionChannelSwitch.
+func (in *SubscriptionChannelSwitch) DeepCopy() *SubscriptionChannelSwitch {
+	if in == nil {
+		return nil
+	}
+	out := new(SubscriptionChannelSwitch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubscriptionCondition) DeepCopyInto(out *SubscriptionCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubscriptionCondition.
+func (in *SubscriptionCondition) DeepCopy() *SubscriptionCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(SubscriptionCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SubscriptionList) DeepCopyInto(out *SubscriptionList) {
 	*out = *in
@@ -812,6 +1231,11 @@ func (in *SubscriptionList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SubscriptionSpec) DeepCopyInto(out *SubscriptionSpec) {
 	*out = *in
+	if in.MaintenanceWindows != nil {
+		in, out := &in.MaintenanceWindows, &out.MaintenanceWindows
+		*out = make([]MaintenanceWindow, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -838,6 +1262,31 @@ func (in *SubscriptionStatus) DeepCopyInto(out *SubscriptionStatus) {
 		}
 	}
 	in.LastUpdated.DeepCopyInto(&out.LastUpdated)
+	if in.ChannelSwitch != nil {
+		in, out := &in.ChannelSwitch, &out.ChannelSwitch
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(SubscriptionChannelSwitch)
+			**out = **in
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]SubscriptionCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Uninstall != nil {
+		in, out := &in.Uninstall, &out.Uninstall
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(SubscriptionUninstall)
+			(*in).DeepCopyInto(*out)
+		}
+	}
 	return
 }
 
@@ -850,3 +1299,110 @@ func (in *SubscriptionStatus) DeepCopy() *SubscriptionStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubscriptionUninstall) DeepCopyInto(out *SubscriptionUninstall) {
+	*out = *in
+	if in.ClusterServiceVersions != nil {
+		in, out := &in.ClusterServiceVersions, &out.ClusterServiceVersions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CustomResourceDefinitions != nil {
+		in, out := &in.CustomResourceDefinitions, &out.CustomResourceDefinitions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubscriptionUninstall.
+func (in *SubscriptionUninstall) DeepCopy() *SubscriptionUninstall {
+	if in == nil {
+		return nil
+	}
+	out := new(SubscriptionUninstall)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpgradePolicy) DeepCopyInto(out *UpgradePolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradePolicy.
+func (in *UpgradePolicy) DeepCopy() *UpgradePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *UpgradePolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpgradePolicyList) DeepCopyInto(out *UpgradePolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]UpgradePolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradePolicyList.
+func (in *UpgradePolicyList) DeepCopy() *UpgradePolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradePolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *UpgradePolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpgradePolicySpec) DeepCopyInto(out *UpgradePolicySpec) {
+	*out = *in
+	if in.MaintenanceWindows != nil {
+		in, out := &in.MaintenanceWindows, &out.MaintenanceWindows
+		*out = make([]MaintenanceWindow, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradePolicySpec.
+func (in *UpgradePolicySpec) DeepCopy() *UpgradePolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradePolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
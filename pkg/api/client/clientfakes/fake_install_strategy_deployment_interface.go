@@ -9,6 +9,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	v1beta1rbac "k8s.io/api/rbac/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type FakeInstallStrategyDeploymentInterface struct {
@@ -115,6 +116,32 @@ type FakeInstallStrategyDeploymentInterface struct {
 		result1 []*appsv1.Deployment
 		result2 error
 	}
+	FindReplicaSetsMatchingSelectorStub        func(selector *metav1.LabelSelector) ([]*appsv1.ReplicaSet, error)
+	findReplicaSetsMatchingSelectorMutex       sync.RWMutex
+	findReplicaSetsMatchingSelectorArgsForCall []struct {
+		selector *metav1.LabelSelector
+	}
+	findReplicaSetsMatchingSelectorReturns struct {
+		result1 []*appsv1.ReplicaSet
+		result2 error
+	}
+	findReplicaSetsMatchingSelectorReturnsOnCall map[int]struct {
+		result1 []*appsv1.ReplicaSet
+		result2 error
+	}
+	FindPodsMatchingSelectorStub        func(selector *metav1.LabelSelector) ([]*corev1.Pod, error)
+	findPodsMatchingSelectorMutex       sync.RWMutex
+	findPodsMatchingSelectorArgsForCall []struct {
+		selector *metav1.LabelSelector
+	}
+	findPodsMatchingSelectorReturns struct {
+		result1 []*corev1.Pod
+		result2 error
+	}
+	findPodsMatchingSelectorReturnsOnCall map[int]struct {
+		result1 []*corev1.Pod
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -530,6 +557,108 @@ func (fake *FakeInstallStrategyDeploymentInterface) FindAnyDeploymentsMatchingNa
 	}{result1, result2}
 }
 
+func (fake *FakeInstallStrategyDeploymentInterface) FindReplicaSetsMatchingSelector(selector *metav1.LabelSelector) ([]*appsv1.ReplicaSet, error) {
+	fake.findReplicaSetsMatchingSelectorMutex.Lock()
+	ret, specificReturn := fake.findReplicaSetsMatchingSelectorReturnsOnCall[len(fake.findReplicaSetsMatchingSelectorArgsForCall)]
+	fake.findReplicaSetsMatchingSelectorArgsForCall = append(fake.findReplicaSetsMatchingSelectorArgsForCall, struct {
+		selector *metav1.LabelSelector
+	}{selector})
+	fake.recordInvocation("FindReplicaSetsMatchingSelector", []interface{}{selector})
+	fake.findReplicaSetsMatchingSelectorMutex.Unlock()
+	if fake.FindReplicaSetsMatchingSelectorStub != nil {
+		return fake.FindReplicaSetsMatchingSelectorStub(selector)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.findReplicaSetsMatchingSelectorReturns.result1, fake.findReplicaSetsMatchingSelectorReturns.result2
+}
+
+func (fake *FakeInstallStrategyDeploymentInterface) FindReplicaSetsMatchingSelectorCallCount() int {
+	fake.findReplicaSetsMatchingSelectorMutex.RLock()
+	defer fake.findReplicaSetsMatchingSelectorMutex.RUnlock()
+	return len(fake.findReplicaSetsMatchingSelectorArgsForCall)
+}
+
+func (fake *FakeInstallStrategyDeploymentInterface) FindReplicaSetsMatchingSelectorArgsForCall(i int) *metav1.LabelSelector {
+	fake.findReplicaSetsMatchingSelectorMutex.RLock()
+	defer fake.findReplicaSetsMatchingSelectorMutex.RUnlock()
+	return fake.findReplicaSetsMatchingSelectorArgsForCall[i].selector
+}
+
+func (fake *FakeInstallStrategyDeploymentInterface) FindReplicaSetsMatchingSelectorReturns(result1 []*appsv1.ReplicaSet, result2 error) {
+	fake.FindReplicaSetsMatchingSelectorStub = nil
+	fake.findReplicaSetsMatchingSelectorReturns = struct {
+		result1 []*appsv1.ReplicaSet
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeInstallStrategyDeploymentInterface) FindReplicaSetsMatchingSelectorReturnsOnCall(i int, result1 []*appsv1.ReplicaSet, result2 error) {
+	fake.FindReplicaSetsMatchingSelectorStub = nil
+	if fake.findReplicaSetsMatchingSelectorReturnsOnCall == nil {
+		fake.findReplicaSetsMatchingSelectorReturnsOnCall = make(map[int]struct {
+			result1 []*appsv1.ReplicaSet
+			result2 error
+		})
+	}
+	fake.findReplicaSetsMatchingSelectorReturnsOnCall[i] = struct {
+		result1 []*appsv1.ReplicaSet
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeInstallStrategyDeploymentInterface) FindPodsMatchingSelector(selector *metav1.LabelSelector) ([]*corev1.Pod, error) {
+	fake.findPodsMatchingSelectorMutex.Lock()
+	ret, specificReturn := fake.findPodsMatchingSelectorReturnsOnCall[len(fake.findPodsMatchingSelectorArgsForCall)]
+	fake.findPodsMatchingSelectorArgsForCall = append(fake.findPodsMatchingSelectorArgsForCall, struct {
+		selector *metav1.LabelSelector
+	}{selector})
+	fake.recordInvocation("FindPodsMatchingSelector", []interface{}{selector})
+	fake.findPodsMatchingSelectorMutex.Unlock()
+	if fake.FindPodsMatchingSelectorStub != nil {
+		return fake.FindPodsMatchingSelectorStub(selector)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.findPodsMatchingSelectorReturns.result1, fake.findPodsMatchingSelectorReturns.result2
+}
+
+func (fake *FakeInstallStrategyDeploymentInterface) FindPodsMatchingSelectorCallCount() int {
+	fake.findPodsMatchingSelectorMutex.RLock()
+	defer fake.findPodsMatchingSelectorMutex.RUnlock()
+	return len(fake.findPodsMatchingSelectorArgsForCall)
+}
+
+func (fake *FakeInstallStrategyDeploymentInterface) FindPodsMatchingSelectorArgsForCall(i int) *metav1.LabelSelector {
+	fake.findPodsMatchingSelectorMutex.RLock()
+	defer fake.findPodsMatchingSelectorMutex.RUnlock()
+	return fake.findPodsMatchingSelectorArgsForCall[i].selector
+}
+
+func (fake *FakeInstallStrategyDeploymentInterface) FindPodsMatchingSelectorReturns(result1 []*corev1.Pod, result2 error) {
+	fake.FindPodsMatchingSelectorStub = nil
+	fake.findPodsMatchingSelectorReturns = struct {
+		result1 []*corev1.Pod
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeInstallStrategyDeploymentInterface) FindPodsMatchingSelectorReturnsOnCall(i int, result1 []*corev1.Pod, result2 error) {
+	fake.FindPodsMatchingSelectorStub = nil
+	if fake.findPodsMatchingSelectorReturnsOnCall == nil {
+		fake.findPodsMatchingSelectorReturnsOnCall = make(map[int]struct {
+			result1 []*corev1.Pod
+			result2 error
+		})
+	}
+	fake.findPodsMatchingSelectorReturnsOnCall[i] = struct {
+		result1 []*corev1.Pod
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeInstallStrategyDeploymentInterface) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -549,6 +678,10 @@ func (fake *FakeInstallStrategyDeploymentInterface) Invocations() map[string][][
 	defer fake.getServiceAccountByNameMutex.RUnlock()
 	fake.findAnyDeploymentsMatchingNamesMutex.RLock()
 	defer fake.findAnyDeploymentsMatchingNamesMutex.RUnlock()
+	fake.findReplicaSetsMatchingSelectorMutex.RLock()
+	defer fake.findReplicaSetsMatchingSelectorMutex.RUnlock()
+	fake.findPodsMatchingSelectorMutex.RLock()
+	defer fake.findPodsMatchingSelectorMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -44,6 +44,10 @@ func (c *FakeOperatorsV1alpha1) Subscriptions(namespace string) v1alpha1.Subscri
 	return &FakeSubscriptions{c, namespace}
 }
 
+func (c *FakeOperatorsV1alpha1) UpgradePolicies(namespace string) v1alpha1.UpgradePolicyInterface {
+	return &FakeUpgradePolicies{c, namespace}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeOperatorsV1alpha1) RESTClient() rest.Interface {
@@ -25,3 +25,5 @@ type ClusterServiceVersionExpansion interface{}
 type InstallPlanExpansion interface{}
 
 type SubscriptionExpansion interface{}
+
+type UpgradePolicyExpansion interface{}
@@ -31,6 +31,7 @@ type OperatorsV1alpha1Interface interface {
 	ClusterServiceVersionsGetter
 	InstallPlansGetter
 	SubscriptionsGetter
+	UpgradePoliciesGetter
 }
 
 // OperatorsV1alpha1Client is used to interact with features provided by the operators.coreos.com group.
@@ -54,6 +55,10 @@ func (c *OperatorsV1alpha1Client) Subscriptions(namespace string) SubscriptionIn
 	return newSubscriptions(c, namespace)
 }
 
+func (c *OperatorsV1alpha1Client) UpgradePolicies(namespace string) UpgradePolicyInterface {
+	return newUpgradePolicies(c, namespace)
+}
+
 // NewForConfig creates a new OperatorsV1alpha1Client for the given config.
 func NewForConfig(c *rest.Config) (*OperatorsV1alpha1Client, error) {
 	config := *c
@@ -23,6 +23,8 @@ type InstallStrategyDeploymentInterface interface {
 	DeleteDeployment(name string) error
 	GetServiceAccountByName(serviceAccountName string) (*corev1.ServiceAccount, error)
 	FindAnyDeploymentsMatchingNames(depNames []string) ([]*appsv1.Deployment, error)
+	FindReplicaSetsMatchingSelector(selector *metav1.LabelSelector) ([]*appsv1.ReplicaSet, error)
+	FindPodsMatchingSelector(selector *metav1.LabelSelector) ([]*corev1.Pod, error)
 }
 
 type InstallStrategyDeploymentClientForNamespace struct {
@@ -115,3 +117,35 @@ func (c *InstallStrategyDeploymentClientForNamespace) FindAnyDeploymentsMatching
 	}
 	return deployments, nil
 }
+
+func (c *InstallStrategyDeploymentClientForNamespace) FindReplicaSetsMatchingSelector(selector *metav1.LabelSelector) ([]*appsv1.ReplicaSet, error) {
+	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid replicaset selector")
+	}
+	list, err := c.opClient.KubernetesInterface().AppsV1().ReplicaSets(c.Namespace).List(metav1.ListOptions{LabelSelector: labelSelector.String()})
+	if err != nil {
+		return nil, err
+	}
+	var replicaSets []*appsv1.ReplicaSet
+	for i := range list.Items {
+		replicaSets = append(replicaSets, &list.Items[i])
+	}
+	return replicaSets, nil
+}
+
+func (c *InstallStrategyDeploymentClientForNamespace) FindPodsMatchingSelector(selector *metav1.LabelSelector) ([]*corev1.Pod, error) {
+	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid pod selector")
+	}
+	list, err := c.opClient.KubernetesInterface().CoreV1().Pods(c.Namespace).List(metav1.ListOptions{LabelSelector: labelSelector.String()})
+	if err != nil {
+		return nil, err
+	}
+	var pods []*corev1.Pod
+	for i := range list.Items {
+		pods = append(pods, &list.Items[i])
+	}
+	return pods, nil
+}
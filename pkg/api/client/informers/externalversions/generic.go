@@ -61,6 +61,8 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Operators().V1alpha1().InstallPlans().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("subscriptions"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Operators().V1alpha1().Subscriptions().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("upgradepolicies"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Operators().V1alpha1().UpgradePolicies().Informer()}, nil
 
 	}
 
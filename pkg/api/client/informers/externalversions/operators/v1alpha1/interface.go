@@ -32,6 +32,8 @@ type Interface interface {
 	InstallPlans() InstallPlanInformer
 	// Subscriptions returns a SubscriptionInformer.
 	Subscriptions() SubscriptionInformer
+	// UpgradePolicies returns a UpgradePolicyInformer.
+	UpgradePolicies() UpgradePolicyInformer
 }
 
 type version struct {
@@ -64,3 +66,8 @@ func (v *version) InstallPlans() InstallPlanInformer {
 func (v *version) Subscriptions() SubscriptionInformer {
 	return &subscriptionInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
+
+// UpgradePolicies returns a UpgradePolicyInformer.
+func (v *version) UpgradePolicies() UpgradePolicyInformer {
+	return &upgradePolicyInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
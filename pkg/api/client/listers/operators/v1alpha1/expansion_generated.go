@@ -49,3 +49,11 @@ type SubscriptionListerExpansion interface{}
 // SubscriptionNamespaceListerExpansion allows custom methods to be added to
 // SubscriptionNamespaceLister.
 type SubscriptionNamespaceListerExpansion interface{}
+
+// UpgradePolicyListerExpansion allows custom methods to be added to
+// UpgradePolicyLister.
+type UpgradePolicyListerExpansion interface{}
+
+// UpgradePolicyNamespaceListerExpansion allows custom methods to be added to
+// UpgradePolicyNamespaceLister.
+type UpgradePolicyNamespaceListerExpansion interface{}
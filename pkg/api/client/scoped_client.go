@@ -0,0 +1,91 @@
+//go:generate counterfeiter scoped_client.go ServiceAccountClientFactory
+package client
+
+import (
+	"fmt"
+	"sync"
+
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/rest"
+
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/clientset/versioned"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/operatorclient"
+)
+
+// InstallServiceAccountAnnotationKey names the ServiceAccount whose permissions are used to install into a namespace
+// when set on the namespace, or to install a ClusterServiceVersion into a namespace that doesn't name one when set on
+// the ClusterServiceVersion
+const InstallServiceAccountAnnotationKey = operators.GroupName + "/install-service-account"
+
+// ServiceAccountClientFactory creates clients that act with the permissions of a ServiceAccount
+type ServiceAccountClientFactory interface {
+	ClientsForServiceAccount(namespace, name string) (operatorclient.ClientInterface, versioned.Interface, error)
+}
+
+type impersonatingClientFactory struct {
+	config *rest.Config
+
+	// clients caches the clients for each ServiceAccount, by namespace and name
+	clientsLock sync.Mutex
+	clients     map[string]*serviceAccountClients
+}
+
+type serviceAccountClients struct {
+	opClient operatorclient.ClientInterface
+	crClient versioned.Interface
+}
+
+var _ ServiceAccountClientFactory = &impersonatingClientFactory{}
+
+// NewServiceAccountClientFactory creates a ServiceAccountClientFactory that impersonates ServiceAccounts with the
+// identity from kubeconfig, which must be allowed to impersonate them
+func NewServiceAccountClientFactory(kubeconfig string) (ServiceAccountClientFactory, error) {
+	config, err := getConfig(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	return &impersonatingClientFactory{config: config, clients: map[string]*serviceAccountClients{}}, nil
+}
+
+func (f *impersonatingClientFactory) ClientsForServiceAccount(namespace, name string) (operatorclient.ClientInterface, versioned.Interface, error) {
+	username := ServiceAccountUsername(namespace, name)
+	f.clientsLock.Lock()
+	defer f.clientsLock.Unlock()
+	if clients, ok := f.clients[username]; ok {
+		return clients.opClient, clients.crClient, nil
+	}
+
+	config := rest.CopyConfig(f.config)
+	config.Impersonate = rest.ImpersonationConfig{UserName: username}
+
+	opClient, err := operatorclient.NewClientFromConfig(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	crClient, err := versioned.NewForConfig(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	f.clients[username] = &serviceAccountClients{opClient: opClient, crClient: crClient}
+	return opClient, crClient, nil
+}
+
+// ServiceAccountUsername returns the username a ServiceAccount authenticates as
+func ServiceAccountUsername(namespace, name string) string {
+	return fmt.Sprintf("system:serviceaccount:%s:%s", namespace, name)
+}
+
+// InstallServiceAccount returns the ServiceAccount to install into a namespace with: the one named by the namespace's
+// annotation, or else the requested one. A namespace that names a ServiceAccount can't be installed into with any
+// other. It returns the empty string if neither names one.
+func InstallServiceAccount(namespaces corelisters.NamespaceLister, namespace, requested string) (string, error) {
+	ns, err := namespaces.Get(namespace)
+	if err != nil {
+		return "", fmt.Errorf("error looking up install service account for namespace %s: %s", namespace, err)
+	}
+	if name := ns.GetAnnotations()[InstallServiceAccountAnnotationKey]; name != "" {
+		return name, nil
+	}
+	return requested, nil
+}
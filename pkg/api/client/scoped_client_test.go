@@ -0,0 +1,23 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+)
+
+func TestClientsForServiceAccountCached(t *testing.T) {
+	factory := &impersonatingClientFactory{config: &rest.Config{Host: "localhost"}, clients: map[string]*serviceAccountClients{}}
+
+	opClient, crClient, err := factory.ClientsForServiceAccount("ns", "installer")
+	require.NoError(t, err)
+	cachedOpClient, cachedCRClient, err := factory.ClientsForServiceAccount("ns", "installer")
+	require.NoError(t, err)
+	require.True(t, opClient == cachedOpClient)
+	require.True(t, crClient == cachedCRClient)
+
+	otherOpClient, _, err := factory.ClientsForServiceAccount("other", "installer")
+	require.NoError(t, err)
+	require.False(t, opClient == otherOpClient)
+}
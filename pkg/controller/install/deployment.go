@@ -8,8 +8,11 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	rbac "k8s.io/api/rbac/v1beta1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators/v1alpha1"
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/client"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/audit"
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/ownerutil"
 )
 
@@ -40,6 +43,8 @@ type StrategyDeploymentInstaller struct {
 	strategyClient   client.InstallStrategyDeploymentInterface
 	owner            ownerutil.Owner
 	previousStrategy Strategy
+	imageRelocation  *ImageRelocationConfig
+	auditor          *audit.Auditor
 }
 
 func (d *StrategyDetailsDeployment) GetStrategyName() string {
@@ -48,15 +53,35 @@ func (d *StrategyDetailsDeployment) GetStrategyName() string {
 
 var _ Strategy = &StrategyDetailsDeployment{}
 var _ StrategyInstaller = &StrategyDeploymentInstaller{}
+var _ StrategyProgressReporter = &StrategyDeploymentInstaller{}
 
-func NewStrategyDeploymentInstaller(strategyClient client.InstallStrategyDeploymentInterface, owner ownerutil.Owner, previousStrategy Strategy) StrategyInstaller {
+func NewStrategyDeploymentInstaller(strategyClient client.InstallStrategyDeploymentInterface, owner ownerutil.Owner, previousStrategy Strategy, imageRelocation *ImageRelocationConfig, auditor *audit.Auditor) StrategyInstaller {
 	return &StrategyDeploymentInstaller{
 		strategyClient:   strategyClient,
 		owner:            owner,
 		previousStrategy: previousStrategy,
+		imageRelocation:  imageRelocation,
+		auditor:          auditor,
 	}
 }
 
+// audit records a mutation made on behalf of the owner, as the owner's install ServiceAccount if it names one
+func (i *StrategyDeploymentInstaller) audit(action audit.Action, apiVersion, kind string, obj metav1.Object, diff string) {
+	if !i.auditor.Enabled() {
+		return
+	}
+	record := audit.Record{
+		Action:         action,
+		Object:         audit.Reference(apiVersion, kind, obj),
+		Diff:           diff,
+		CorrelationIDs: audit.Correlation{ClusterServiceVersion: string(i.owner.GetUID())},
+	}
+	if serviceAccount := i.owner.GetAnnotations()[client.InstallServiceAccountAnnotationKey]; serviceAccount != "" {
+		record.Actor = client.ServiceAccountUsername(i.owner.GetNamespace(), serviceAccount)
+	}
+	i.auditor.Record(record)
+}
+
 func (i *StrategyDeploymentInstaller) installPermissions(perms []StrategyDeploymentPermissions) error {
 	for _, permission := range perms {
 		// create role
@@ -69,6 +94,7 @@ func (i *StrategyDeploymentInstaller) installPermissions(perms []StrategyDeploym
 		if err != nil {
 			return err
 		}
+		i.audit(audit.ActionCreate, "rbac.authorization.k8s.io/v1beta1", "Role", createdRole, "")
 
 		// create serviceaccount if necessary
 		serviceAccount := &corev1.ServiceAccount{}
@@ -78,6 +104,7 @@ func (i *StrategyDeploymentInstaller) installPermissions(perms []StrategyDeploym
 		if err != nil {
 			return err
 		}
+		i.audit(audit.ActionApply, "v1", "ServiceAccount", serviceAccount, "")
 
 		// create rolebinding
 		roleBinding := &rbac.RoleBinding{
@@ -94,9 +121,11 @@ func (i *StrategyDeploymentInstaller) installPermissions(perms []StrategyDeploym
 		ownerutil.AddNonBlockingOwner(roleBinding, i.owner)
 		roleBinding.SetGenerateName(fmt.Sprintf("%s-%s-rolebinding-", createdRole.Name, serviceAccount.Name))
 
-		if _, err := i.strategyClient.CreateRoleBinding(roleBinding); err != nil {
+		createdRoleBinding, err := i.strategyClient.CreateRoleBinding(roleBinding)
+		if err != nil {
 			return err
 		}
+		i.audit(audit.ActionCreate, "rbac.authorization.k8s.io/v1beta1", "RoleBinding", createdRoleBinding, "")
 	}
 	return nil
 }
@@ -104,7 +133,8 @@ func (i *StrategyDeploymentInstaller) installPermissions(perms []StrategyDeploym
 func (i *StrategyDeploymentInstaller) installDeployments(deps []StrategyDeploymentSpec) error {
 	for _, d := range deps {
 		// Create or Update Deployment
-		dep := &appsv1.Deployment{Spec: d.Spec}
+		dep := &appsv1.Deployment{Spec: *d.Spec.DeepCopy()}
+		i.imageRelocation.RelocatePodSpec(&dep.Spec.Template.Spec)
 		dep.SetName(d.Name)
 		dep.SetNamespace(i.owner.GetNamespace())
 		ownerutil.AddNonBlockingOwner(dep, i.owner)
@@ -113,9 +143,24 @@ func (i *StrategyDeploymentInstaller) installDeployments(deps []StrategyDeployme
 		}
 		dep.Labels["alm-owner-name"] = i.owner.GetName()
 		dep.Labels["alm-owner-namespace"] = i.owner.GetNamespace()
-		if _, err := i.strategyClient.CreateOrUpdateDeployment(dep); err != nil {
+
+		// looking up the existing deployment is only needed to describe the change
+		var existing *appsv1.Deployment
+		if i.auditor.Enabled() {
+			if found, err := i.strategyClient.FindAnyDeploymentsMatchingNames([]string{d.Name}); err == nil && len(found) > 0 {
+				existing = found[0]
+			}
+		}
+
+		applied, err := i.strategyClient.CreateOrUpdateDeployment(dep)
+		if err != nil {
 			return err
 		}
+		if existing == nil {
+			i.audit(audit.ActionCreate, "apps/v1", "Deployment", applied, "")
+		} else {
+			i.audit(audit.ActionUpdate, "apps/v1", "Deployment", applied, audit.DiffSummary(existing.Spec, dep.Spec))
+		}
 	}
 
 	return nil
@@ -133,7 +178,9 @@ func (i *StrategyDeploymentInstaller) cleanupPrevious(current *StrategyDetailsDe
 	// delete deployments in old strategy but not new
 	var err error = nil
 	for name := range previousDeploymentsMap {
-		err = i.strategyClient.DeleteDeployment(name)
+		if err = i.strategyClient.DeleteDeployment(name); err == nil {
+			i.audit(audit.ActionDelete, "apps/v1", "Deployment", &metav1.ObjectMeta{Namespace: i.owner.GetNamespace(), Name: name}, "")
+		}
 	}
 	return err
 }
@@ -145,11 +192,11 @@ func (i *StrategyDeploymentInstaller) Install(s Strategy) error {
 	}
 
 	if err := i.installPermissions(strategy.Permissions); err != nil {
-		return err
+		return permissionError(err)
 	}
 
 	if err := i.installDeployments(strategy.DeploymentSpecs); err != nil {
-		return err
+		return permissionError(err)
 	}
 
 	if i.previousStrategy != nil {
@@ -157,7 +204,7 @@ func (i *StrategyDeploymentInstaller) Install(s Strategy) error {
 		if !ok {
 			return fmt.Errorf("couldn't parse old install %s strategy with deployment installer", previous.GetStrategyName())
 		}
-		return i.cleanupPrevious(strategy, previous)
+		return permissionError(i.cleanupPrevious(strategy, previous))
 	}
 	return nil
 }
@@ -184,12 +231,49 @@ func (i *StrategyDeploymentInstaller) CheckInstalled(s Strategy) (installed bool
 	return true, nil
 }
 
+// InstallProgress reports the replica counts of each deployment in the strategy that exists in the cluster
+func (i *StrategyDeploymentInstaller) InstallProgress(s Strategy) ([]v1alpha1.DeploymentProgress, error) {
+	strategy, ok := s.(*StrategyDetailsDeployment)
+	if !ok {
+		return nil, fmt.Errorf("attempted to report progress of %s strategy with deployment installer", s.GetStrategyName())
+	}
+
+	var depNames []string
+	for _, dep := range strategy.DeploymentSpecs {
+		depNames = append(depNames, dep.Name)
+	}
+	existingDeployments, err := i.strategyClient.FindAnyDeploymentsMatchingNames(depNames)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := []v1alpha1.DeploymentProgress{}
+	for _, dep := range existingDeployments {
+		// the api server defaults replicas to 1
+		replicas := int32(1)
+		if dep.Spec.Replicas != nil {
+			replicas = *dep.Spec.Replicas
+		}
+		progress = append(progress, v1alpha1.DeploymentProgress{
+			Name:              dep.GetName(),
+			Replicas:          replicas,
+			UpdatedReplicas:   dep.Status.UpdatedReplicas,
+			ReadyReplicas:     dep.Status.ReadyReplicas,
+			AvailableReplicas: dep.Status.AvailableReplicas,
+		})
+	}
+	return progress, nil
+}
+
 func (i *StrategyDeploymentInstaller) checkForServiceAccount(serviceAccountName string) error {
 	if _, err := i.strategyClient.GetServiceAccountByName(serviceAccountName); err != nil {
 		if apierrors.IsNotFound(err) {
 			log.Debugf("service account not found: %s", serviceAccountName)
 			return StrategyError{Reason: StrategyErrReasonComponentMissing, Message: fmt.Sprintf("service account not found: %s", serviceAccountName)}
 		}
+		if apierrors.IsForbidden(err) {
+			return permissionError(err)
+		}
 		log.Debugf("error querying for %s: %s", serviceAccountName, err)
 		return StrategyError{Reason: StrategyErrReasonComponentMissing, Message: fmt.Sprintf("error querying for %s: %s", serviceAccountName, err)}
 	}
@@ -204,6 +288,9 @@ func (i *StrategyDeploymentInstaller) checkForDeployments(deploymentSpecs []Stra
 	}
 
 	existingDeployments, err := i.strategyClient.FindAnyDeploymentsMatchingNames(depNames)
+	if apierrors.IsForbidden(err) {
+		return permissionError(err)
+	}
 	if err != nil {
 		return StrategyError{Reason: StrategyErrReasonComponentMissing, Message: fmt.Sprintf("error querying for %s: %s", depNames, err)}
 	}
@@ -222,9 +309,18 @@ func (i *StrategyDeploymentInstaller) checkForDeployments(deploymentSpecs []Stra
 		reason, ready, err := DeploymentStatus(dep)
 		if err != nil {
 			log.Debugf("deployment %s not ready before timeout: %s", dep.Name, err.Error())
-			return StrategyError{Reason: StrategyErrReasonTimeout, Message: fmt.Sprintf("deployment %s not ready before timeout: %s", dep.Name, err.Error())}
+			message := fmt.Sprintf("deployment %s not ready before timeout: %s", dep.Name, err.Error())
+			if diagnosis := i.diagnoseDeployment(dep); diagnosis != nil {
+				message = fmt.Sprintf("%s (%s)", message, diagnosis.Message)
+			}
+			return StrategyError{Reason: StrategyErrReasonTimeout, Message: message}
 		}
 		if !ready {
+			// report why the deployment's pods are failing if it's something more than slow progress
+			if diagnosis := i.diagnoseDeployment(dep); diagnosis != nil {
+				log.Debugf("deployment %s failing: %s", dep.Name, diagnosis.Message)
+				return *diagnosis
+			}
 			return StrategyError{Reason: StrategyErrReasonWaiting, Message: fmt.Sprintf("waiting for deployment %s to become ready: %s", dep.Name, reason)}
 		}
 	}
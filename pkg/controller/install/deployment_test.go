@@ -1,6 +1,8 @@
 package install
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"testing"
@@ -13,7 +15,9 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators/v1alpha1"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/client"
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/clientfakes"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/audit"
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/ownerutil"
 	"github.com/stretchr/testify/require"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -591,7 +595,7 @@ func TestInstallStrategyDeployment(t *testing.T) {
 				fakeClient.CreateDeploymentReturnsOnCall(i-1, &deployment, nil)
 			}
 
-			installer := NewStrategyDeploymentInstaller(fakeClient, &mockOwner, nil)
+			installer := NewStrategyDeploymentInstaller(fakeClient, &mockOwner, nil, nil, nil)
 
 			installed, err := installer.CheckInstalled(strategy)
 			if tt.numMockServiceAccounts == tt.numExpected && tt.numMockDeployments == tt.numExpected {
@@ -624,7 +628,7 @@ func TestNewStrategyDeploymentInstaller(t *testing.T) {
 		},
 	}
 	fakeClient := new(clientfakes.FakeInstallStrategyDeploymentInterface)
-	strategy := NewStrategyDeploymentInstaller(fakeClient, &mockOwner, nil)
+	strategy := NewStrategyDeploymentInstaller(fakeClient, &mockOwner, nil, nil, nil)
 	require.Implements(t, (*StrategyInstaller)(nil), strategy)
 	require.Error(t, strategy.Install(&BadStrategy{}))
 	installed, err := strategy.CheckInstalled(&BadStrategy{})
@@ -680,7 +684,7 @@ func TestInstallStrategyDeploymentCheckInstallErrors(t *testing.T) {
 		t.Run(tt.description, func(t *testing.T) {
 			fakeClient := new(clientfakes.FakeInstallStrategyDeploymentInterface)
 			strategy := strategy(1, namespace, &mockOwner)
-			installer := NewStrategyDeploymentInstaller(fakeClient, &mockOwner, nil)
+			installer := NewStrategyDeploymentInstaller(fakeClient, &mockOwner, nil, nil, nil)
 
 			skipInstall := tt.checkServiceAccountErr != nil
 
@@ -760,3 +764,113 @@ func TestInstallStrategyDeploymentCheckInstallErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestInstallStrategyDeploymentInstallProgress(t *testing.T) {
+	namespace := "alm-test-deployment"
+	mockOwner := v1alpha1.ClusterServiceVersion{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "clusterserviceversion-owner",
+			Namespace: namespace,
+		},
+	}
+
+	fakeClient := new(clientfakes.FakeInstallStrategyDeploymentInterface)
+	strategy := strategy(2, namespace, &mockOwner)
+	installer := &StrategyDeploymentInstaller{strategyClient: fakeClient, owner: &mockOwner}
+
+	replicas := int32(3)
+	dep1 := testDeployment("alm-dep-1", namespace, &mockOwner)
+	dep1.Spec.Replicas = &replicas
+	dep1.Status = appsv1.DeploymentStatus{UpdatedReplicas: 3, ReadyReplicas: 2, AvailableReplicas: 1}
+	dep2 := testDeployment("alm-dep-2", namespace, &mockOwner)
+	fakeClient.FindAnyDeploymentsMatchingNamesReturns([]*appsv1.Deployment{&dep1, &dep2}, nil)
+
+	progress, err := installer.InstallProgress(strategy)
+	require.NoError(t, err)
+	require.Equal(t, []string{"alm-dep-1", "alm-dep-2"}, fakeClient.FindAnyDeploymentsMatchingNamesArgsForCall(0))
+	require.Equal(t, []v1alpha1.DeploymentProgress{
+		{Name: "alm-dep-1", Replicas: 3, UpdatedReplicas: 3, ReadyReplicas: 2, AvailableReplicas: 1},
+		{Name: "alm-dep-2", Replicas: 1},
+	}, progress)
+}
+
+func TestInstallStrategyDeploymentInsufficientPermissions(t *testing.T) {
+	namespace := "alm-test-deployment"
+	mockOwner := v1alpha1.ClusterServiceVersion{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "clusterserviceversion-owner",
+			Namespace: namespace,
+		},
+	}
+	forbidden := apierrors.NewForbidden(schema.GroupResource{Group: "rbac.authorization.k8s.io", Resource: "roles"}, "test-role", errors.New("no access"))
+
+	fakeClient := new(clientfakes.FakeInstallStrategyDeploymentInterface)
+	strategy := strategy(1, namespace, &mockOwner)
+	installer := NewStrategyDeploymentInstaller(fakeClient, &mockOwner, nil, nil, nil)
+
+	fakeClient.CreateRoleReturns(nil, forbidden)
+	err := installer.Install(strategy)
+	require.True(t, IsErrorInsufficientPermissions(err))
+	require.False(t, IsErrorUnrecoverable(err))
+
+	fakeClient.GetServiceAccountByNameReturns(nil, forbidden)
+	installed, err := installer.CheckInstalled(strategy)
+	require.False(t, installed)
+	require.True(t, IsErrorInsufficientPermissions(err))
+
+	fakeClient.CreateRoleReturns(nil, errors.New("error creating role"))
+	err = installer.Install(strategy)
+	require.Error(t, err)
+	require.False(t, IsErrorInsufficientPermissions(err))
+}
+
+func TestInstallStrategyDeploymentInstallAudits(t *testing.T) {
+	namespace := "alm-test-deployment"
+	mockOwner := v1alpha1.ClusterServiceVersion{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "clusterserviceversion-owner",
+			Namespace:   namespace,
+			UID:         "csv-uid",
+			Annotations: map[string]string{client.InstallServiceAccountAnnotationKey: "installer"},
+		},
+	}
+
+	var buf bytes.Buffer
+	auditor := audit.NewAuditor(audit.NewWriterSink(&buf), "alm-operator")
+	fakeClient := new(clientfakes.FakeInstallStrategyDeploymentInterface)
+	strategy := strategy(1, namespace, &mockOwner)
+	previous := &StrategyDetailsDeployment{DeploymentSpecs: []StrategyDeploymentSpec{{Name: "alm-dep-old"}}}
+	installer := NewStrategyDeploymentInstaller(fakeClient, &mockOwner, previous, nil, auditor)
+
+	fakeClient.CreateRoleReturns(&v1beta1rbac.Role{ObjectMeta: metav1.ObjectMeta{Name: "role", Namespace: namespace}}, nil)
+	fakeClient.EnsureServiceAccountReturns(testServiceAccount("alm-sa-1", &mockOwner), nil)
+	fakeClient.CreateRoleBindingReturns(&v1beta1rbac.RoleBinding{ObjectMeta: metav1.ObjectMeta{Name: "rolebinding", Namespace: namespace}}, nil)
+	existing := testDeployment("alm-dep-1", namespace, &mockOwner)
+	existing.Spec.Template.Spec.Containers = []corev1.Container{{Name: "operator", Image: "quay.io/coreos/operator:v0"}}
+	fakeClient.FindAnyDeploymentsMatchingNamesReturns([]*appsv1.Deployment{&existing}, nil)
+	applied := testDeployment("alm-dep-1", namespace, &mockOwner)
+	fakeClient.CreateOrUpdateDeploymentReturns(&applied, nil)
+
+	require.NoError(t, installer.Install(strategy))
+
+	records := []audit.Record{}
+	decoder := json.NewDecoder(&buf)
+	for decoder.More() {
+		var record audit.Record
+		require.NoError(t, decoder.Decode(&record))
+		require.Equal(t, "system:serviceaccount:alm-test-deployment:installer", record.Actor)
+		require.Equal(t, "csv-uid", record.CorrelationIDs.ClusterServiceVersion)
+		records = append(records, record)
+	}
+	require.Len(t, records, 5)
+	require.Equal(t, audit.ActionCreate, records[0].Action)
+	require.Equal(t, "Role", records[0].Object.Kind)
+	require.Equal(t, audit.ActionApply, records[1].Action)
+	require.Equal(t, "ServiceAccount", records[1].Object.Kind)
+	require.Equal(t, "RoleBinding", records[2].Object.Kind)
+	require.Equal(t, audit.ActionUpdate, records[3].Action)
+	require.Equal(t, "alm-dep-1", records[3].Object.Name)
+	require.Equal(t, "template.spec.containers", records[3].Diff)
+	require.Equal(t, audit.ActionDelete, records[4].Action)
+	require.Equal(t, "alm-dep-old", records[4].Object.Name)
+}
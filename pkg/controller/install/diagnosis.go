@@ -0,0 +1,139 @@
+package install
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	revisionAnnotation = "deployment.kubernetes.io/revision"
+
+	// maxTerminationMessageLength caps how much of a container's termination message ends up in the CSV status
+	maxTerminationMessageLength = 256
+)
+
+// UnrecoverableFailureThreshold is how long a pod may fail to pull its images or to be scheduled before the
+// install is considered unrecoverable
+var UnrecoverableFailureThreshold = 10 * time.Minute
+
+// for test stubbing
+var timeNow = time.Now
+
+// imagePullReasons are the container waiting reasons that mean an image can't be pulled
+var imagePullReasons = map[string]struct{}{
+	"ErrImagePull":      {},
+	"ImagePullBackOff":  {},
+	"InvalidImageName":  {},
+	"ErrImageNeverPull": {},
+}
+
+// diagnoseDeployment inspects the pods of the deployment's current ReplicaSet and returns a StrategyError
+// describing the first common failure mode found, or nil if none could be identified
+func (i *StrategyDeploymentInstaller) diagnoseDeployment(dep *appsv1.Deployment) *StrategyError {
+	replicaSets, err := i.strategyClient.FindReplicaSetsMatchingSelector(dep.Spec.Selector)
+	if err != nil {
+		log.Debugf("unable to list replicasets for deployment %s: %s", dep.GetName(), err)
+		return nil
+	}
+	current := currentReplicaSets(dep, replicaSets)
+	if len(current) == 0 {
+		return nil
+	}
+
+	pods, err := i.strategyClient.FindPodsMatchingSelector(dep.Spec.Selector)
+	if err != nil {
+		log.Debugf("unable to list pods for deployment %s: %s", dep.GetName(), err)
+		return nil
+	}
+	for _, pod := range pods {
+		ref := metav1.GetControllerOf(pod)
+		if ref == nil || ref.Kind != "ReplicaSet" {
+			continue
+		}
+		if _, ok := current[ref.Name]; !ok {
+			continue
+		}
+		if diagnosis := diagnosePod(pod); diagnosis != nil {
+			diagnosis.Message = fmt.Sprintf("deployment %s: %s", dep.GetName(), diagnosis.Message)
+			return diagnosis
+		}
+	}
+	return nil
+}
+
+// currentReplicaSets returns the ReplicaSets controlled by the deployment that belong to its current revision
+func currentReplicaSets(dep *appsv1.Deployment, replicaSets []*appsv1.ReplicaSet) map[string]*appsv1.ReplicaSet {
+	revision := dep.GetAnnotations()[revisionAnnotation]
+	current := map[string]*appsv1.ReplicaSet{}
+	for _, rs := range replicaSets {
+		ref := metav1.GetControllerOf(rs)
+		if ref == nil || ref.Kind != "Deployment" || ref.Name != dep.GetName() {
+			continue
+		}
+		if revision != "" && rs.GetAnnotations()[revisionAnnotation] != revision {
+			continue
+		}
+		current[rs.GetName()] = rs
+	}
+	return current
+}
+
+// diagnosePod classifies why a pod isn't becoming ready
+func diagnosePod(pod *corev1.Pod) *StrategyError {
+	persistent := timeNow().Sub(pod.GetCreationTimestamp().Time) > UnrecoverableFailureThreshold
+
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse && cond.Reason == corev1.PodReasonUnschedulable {
+			reason := StrategyErrReasonUnschedulable
+			if persistent {
+				reason = StrategyErrReasonSchedulingFailed
+			}
+			return &StrategyError{Reason: reason, Message: fmt.Sprintf("pod %s can't be scheduled: %s", pod.GetName(), cond.Message)}
+		}
+	}
+
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		if waiting := status.State.Waiting; waiting != nil {
+			if _, ok := imagePullReasons[waiting.Reason]; ok {
+				reason := StrategyErrReasonImagePullBackOff
+				if persistent {
+					reason = StrategyErrReasonImagePullFailed
+				}
+				return &StrategyError{Reason: reason, Message: fmt.Sprintf("pod %s container %s: %s: %s", pod.GetName(), status.Name, waiting.Reason, waiting.Message)}
+			}
+			if waiting.Reason == "CrashLoopBackOff" {
+				reason := StrategyErrReasonCrashLoopBackOff
+				if last := status.LastTerminationState.Terminated; last != nil && last.Reason == "OOMKilled" {
+					reason = StrategyErrReasonOOMKilled
+				}
+				return &StrategyError{Reason: reason, Message: fmt.Sprintf("pod %s container %s: %s%s", pod.GetName(), status.Name, waiting.Reason, describeTermination(status.LastTerminationState.Terminated))}
+			}
+		}
+		if terminated := status.State.Terminated; terminated != nil && terminated.Reason == "OOMKilled" {
+			return &StrategyError{Reason: StrategyErrReasonOOMKilled, Message: fmt.Sprintf("pod %s container %s: %s%s", pod.GetName(), status.Name, terminated.Reason, describeTermination(terminated))}
+		}
+	}
+	return nil
+}
+
+// describeTermination summarizes a container's termination state for status messages
+func describeTermination(terminated *corev1.ContainerStateTerminated) string {
+	if terminated == nil {
+		return ""
+	}
+	description := fmt.Sprintf(" (last termination: %s, exit code %d", terminated.Reason, terminated.ExitCode)
+	if message := strings.TrimSpace(terminated.Message); message != "" {
+		if len(message) > maxTerminationMessageLength {
+			message = message[:maxTerminationMessageLength] + "..."
+		}
+		description += ": " + message
+	}
+	return description + ")"
+}
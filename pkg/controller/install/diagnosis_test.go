@@ -0,0 +1,171 @@
+package install
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators/v1alpha1"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/clientfakes"
+)
+
+func controllerRef(kind, name string) []metav1.OwnerReference {
+	isController := true
+	return []metav1.OwnerReference{{Kind: kind, Name: name, Controller: &isController}}
+}
+
+func testPod(name, replicaSet string, created time.Time, status corev1.PodStatus) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			CreationTimestamp: metav1.NewTime(created),
+			OwnerReferences:   controllerRef("ReplicaSet", replicaSet),
+		},
+		Status: status,
+	}
+}
+
+func TestDiagnosePod(t *testing.T) {
+	now := time.Date(2018, 1, 1, 12, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	recent := now.Add(-time.Minute)
+	old := now.Add(-2 * UnrecoverableFailureThreshold)
+
+	tests := []struct {
+		description string
+		created     time.Time
+		status      corev1.PodStatus
+		reason      string
+		message     string
+	}{
+		{
+			description: "Healthy",
+			created:     recent,
+			status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{{Name: "c", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}}},
+			},
+		},
+		{
+			description: "Unschedulable",
+			created:     recent,
+			status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{{Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: corev1.PodReasonUnschedulable, Message: "0/3 nodes are available: 3 Insufficient cpu."}},
+			},
+			reason:  StrategyErrReasonUnschedulable,
+			message: "pod p can't be scheduled: 0/3 nodes are available: 3 Insufficient cpu.",
+		},
+		{
+			description: "PersistentlyUnschedulable",
+			created:     old,
+			status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{{Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: corev1.PodReasonUnschedulable, Message: "no nodes"}},
+			},
+			reason:  StrategyErrReasonSchedulingFailed,
+			message: "pod p can't be scheduled: no nodes",
+		},
+		{
+			description: "ImagePullBackOff",
+			created:     recent,
+			status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{{Name: "c", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "Back-off pulling image \"bad\""}}}},
+			},
+			reason:  StrategyErrReasonImagePullBackOff,
+			message: "pod p container c: ImagePullBackOff: Back-off pulling image \"bad\"",
+		},
+		{
+			description: "PersistentInitContainerImagePull",
+			created:     old,
+			status: corev1.PodStatus{
+				InitContainerStatuses: []corev1.ContainerStatus{{Name: "init", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ErrImagePull", Message: "not found"}}}},
+			},
+			reason:  StrategyErrReasonImagePullFailed,
+			message: "pod p container init: ErrImagePull: not found",
+		},
+		{
+			description: "CrashLoopBackOff",
+			created:     old,
+			status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:                 "c",
+					State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+					LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Error", ExitCode: 1, Message: "panic: bad config\n"}},
+				}},
+			},
+			reason:  StrategyErrReasonCrashLoopBackOff,
+			message: "pod p container c: CrashLoopBackOff (last termination: Error, exit code 1: panic: bad config)",
+		},
+		{
+			description: "CrashLoopOOMKilled",
+			created:     recent,
+			status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:                 "c",
+					State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+					LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137}},
+				}},
+			},
+			reason:  StrategyErrReasonOOMKilled,
+			message: "pod p container c: CrashLoopBackOff (last termination: OOMKilled, exit code 137)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			diagnosis := diagnosePod(testPod("p", "rs", tt.created, tt.status))
+			if tt.reason == "" {
+				require.Nil(t, diagnosis)
+				return
+			}
+			require.NotNil(t, diagnosis)
+			require.Equal(t, tt.reason, diagnosis.Reason)
+			require.Equal(t, tt.message, diagnosis.Message)
+		})
+	}
+}
+
+func TestInstallStrategyDeploymentCheckInstalledDiagnosesPods(t *testing.T) {
+	namespace := "alm-test-deployment"
+	mockOwner := v1alpha1.ClusterServiceVersion{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "clusterserviceversion-owner",
+			Namespace: namespace,
+		},
+	}
+
+	fakeClient := new(clientfakes.FakeInstallStrategyDeploymentInterface)
+	strategy := strategy(1, namespace, &mockOwner)
+	installer := NewStrategyDeploymentInstaller(fakeClient, &mockOwner, nil, nil, nil)
+
+	dep := testDeployment("alm-dep-1", namespace, &mockOwner)
+	dep.SetAnnotations(map[string]string{revisionAnnotation: "2"})
+	replicas := int32(1)
+	dep.Spec.Replicas = &replicas
+	fakeClient.GetServiceAccountByNameReturns(testServiceAccount(strategy.Permissions[0].ServiceAccountName, &mockOwner), nil)
+	fakeClient.FindAnyDeploymentsMatchingNamesReturns([]*appsv1.Deployment{&dep}, nil)
+	fakeClient.FindReplicaSetsMatchingSelectorReturns([]*appsv1.ReplicaSet{
+		{ObjectMeta: metav1.ObjectMeta{Name: "rs-old", Annotations: map[string]string{revisionAnnotation: "1"}, OwnerReferences: controllerRef("Deployment", "alm-dep-1")}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "rs-new", Annotations: map[string]string{revisionAnnotation: "2"}, OwnerReferences: controllerRef("Deployment", "alm-dep-1")}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "rs-other", Annotations: map[string]string{revisionAnnotation: "2"}, OwnerReferences: controllerRef("Deployment", "other")}},
+	}, nil)
+
+	crashing := corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{Name: "c", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}}}}
+	pullFailing := corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{Name: "c", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "bad image"}}}}}
+	fakeClient.FindPodsMatchingSelectorReturns([]*corev1.Pod{
+		// pods of other and previous revisions are ignored
+		testPod("old-pod", "rs-old", time.Now(), crashing),
+		testPod("other-pod", "rs-other", time.Now(), crashing),
+		testPod("new-pod", "rs-new", time.Now(), pullFailing),
+	}, nil)
+
+	installed, err := installer.CheckInstalled(strategy)
+	require.False(t, installed)
+	require.Equal(t, StrategyError{Reason: StrategyErrReasonImagePullBackOff, Message: "deployment alm-dep-1: pod new-pod container c: ImagePullBackOff: bad image"}, err)
+	require.True(t, IsErrorComponentFailure(err))
+	require.False(t, IsErrorUnrecoverable(err))
+}
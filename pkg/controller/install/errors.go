@@ -1,6 +1,11 @@
 package install
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
 
 const (
 	StrategyErrReasonComponentMissing = "ComponentMissing"
@@ -8,12 +13,36 @@ const (
 	StrategyErrReasonInvalidStrategy  = "InvalidStrategy"
 	StrategyErrReasonTimeout          = "Timeout"
 	StrategyErrReasonUnknown          = "Unknown"
+
+	// Reasons diagnosed from the pods of a deployment that isn't becoming ready
+	StrategyErrReasonImagePullBackOff = "ImagePullBackOff"
+	StrategyErrReasonCrashLoopBackOff = "CrashLoopBackOff"
+	StrategyErrReasonUnschedulable    = "Unschedulable"
+	StrategyErrReasonOOMKilled        = "OOMKilled"
+	// Image pull and scheduling failures that have persisted past UnrecoverableFailureThreshold
+	StrategyErrReasonImagePullFailed  = "ImagePullFailed"
+	StrategyErrReasonSchedulingFailed = "SchedulingFailed"
+
+	// The identity installing the strategy isn't allowed to create or read one of its components
+	StrategyErrReasonInsufficientPermissions = "InsufficientPermissions"
 )
 
 // unrecoverableErrors are the set of errors that mean we can't recover an install strategy
 var unrecoverableErrors = map[string]struct{}{
-	StrategyErrReasonInvalidStrategy: {},
-	StrategyErrReasonTimeout:         {},
+	StrategyErrReasonInvalidStrategy:  {},
+	StrategyErrReasonTimeout:          {},
+	StrategyErrReasonImagePullFailed:  {},
+	StrategyErrReasonSchedulingFailed: {},
+}
+
+// componentFailureErrors are the set of errors diagnosed from failing pods
+var componentFailureErrors = map[string]struct{}{
+	StrategyErrReasonImagePullBackOff: {},
+	StrategyErrReasonCrashLoopBackOff: {},
+	StrategyErrReasonUnschedulable:    {},
+	StrategyErrReasonOOMKilled:        {},
+	StrategyErrReasonImagePullFailed:  {},
+	StrategyErrReasonSchedulingFailed: {},
 }
 
 // StrategyError is used to represent error types for install strategies
@@ -38,6 +67,28 @@ func IsErrorUnrecoverable(err error) bool {
 	return ok
 }
 
+// IsErrorComponentFailure reports if a given strategy error is a diagnosed pod failure
+func IsErrorComponentFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	_, ok := componentFailureErrors[reasonForError(err)]
+	return ok
+}
+
+// IsErrorInsufficientPermissions reports if a given strategy error was caused by a request the installer wasn't allowed to make
+func IsErrorInsufficientPermissions(err error) bool {
+	return err != nil && reasonForError(err) == StrategyErrReasonInsufficientPermissions
+}
+
+// permissionError converts a forbidden api error into a StrategyError, leaving other errors untouched
+func permissionError(err error) error {
+	if err != nil && apierrors.IsForbidden(errors.Cause(err)) {
+		return StrategyError{Reason: StrategyErrReasonInsufficientPermissions, Message: err.Error()}
+	}
+	return err
+}
+
 func reasonForError(err error) string {
 	switch t := err.(type) {
 	case StrategyError:
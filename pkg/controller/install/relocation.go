@@ -0,0 +1,167 @@
+package install
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const defaultRegistry = "docker.io"
+
+// RegistryMapping rewrites every image hosted on the Source registry to the Mirror registry
+type RegistryMapping struct {
+	Source string `json:"source"`
+	Mirror string `json:"mirror"`
+}
+
+// PrefixMapping rewrites every image whose repository starts with Source, replacing that prefix with Mirror. A Source
+// that doesn't end in "/" only matches whole path components: quay.io/coreos/etcd matches quay.io/coreos/etcd:v3 and
+// quay.io/coreos/etcd/backup, but not quay.io/coreos/etcd-operator.
+type PrefixMapping struct {
+	Source string `json:"source"`
+	Mirror string `json:"mirror"`
+}
+
+// ImageRelocationConfig describes how images referenced by install strategies are rewritten before they're
+// deployed, so that clusters without access to upstream registries can pull them from a mirror
+type ImageRelocationConfig struct {
+	// Prefixes are checked first; the longest matching prefix wins
+	Prefixes []PrefixMapping `json:"prefixes,omitempty"`
+	// Registries are only used if no prefix matches
+	Registries []RegistryMapping `json:"registries,omitempty"`
+	// Digests pins image references, as written in the CSV, to a digest (e.g. "sha256:...")
+	Digests map[string]string `json:"digests,omitempty"`
+}
+
+// LoadImageRelocationConfig reads an ImageRelocationConfig from a yaml or json file
+func LoadImageRelocationConfig(path string) (*ImageRelocationConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading image relocation config %s: %s", path, err)
+	}
+	config := &ImageRelocationConfig{}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("error parsing image relocation config %s: %s", path, err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid image relocation config %s: %s", path, err)
+	}
+	return config, nil
+}
+
+// Validate checks that every rule has both a source and a mirror and that digests are well formed
+func (c *ImageRelocationConfig) Validate() error {
+	for _, p := range c.Prefixes {
+		if p.Source == "" || p.Mirror == "" {
+			return fmt.Errorf("prefix mapping %q -> %q must set both source and mirror", p.Source, p.Mirror)
+		}
+	}
+	for _, r := range c.Registries {
+		if r.Source == "" || r.Mirror == "" {
+			return fmt.Errorf("registry mapping %q -> %q must set both source and mirror", r.Source, r.Mirror)
+		}
+	}
+	for image, digest := range c.Digests {
+		if !strings.Contains(digest, ":") {
+			return fmt.Errorf("digest %q for image %s must be of the form <algorithm>:<hex>", digest, image)
+		}
+	}
+	return nil
+}
+
+// Relocate returns the reference that should be deployed in place of image
+func (c *ImageRelocationConfig) Relocate(image string) string {
+	if c == nil {
+		return image
+	}
+	relocated := image
+	if digest, ok := c.Digests[image]; ok {
+		repository, _, _ := splitImage(image)
+		relocated = repository + "@" + digest
+	}
+
+	// longest prefix wins so that specific rules can override general ones
+	prefixes := append([]PrefixMapping{}, c.Prefixes...)
+	sort.SliceStable(prefixes, func(i, j int) bool { return len(prefixes[i].Source) > len(prefixes[j].Source) })
+	for _, p := range prefixes {
+		if matchesPrefix(relocated, p.Source) {
+			return p.Mirror + strings.TrimPrefix(relocated, p.Source)
+		}
+	}
+
+	registry, remainder := splitRegistry(relocated)
+	for _, r := range c.Registries {
+		if r.Source == registry {
+			return r.Mirror + "/" + remainder
+		}
+	}
+	return relocated
+}
+
+// matchesPrefix reports whether image starts with prefix, and prefix ends at a boundary of the image's reference
+func matchesPrefix(image, prefix string) bool {
+	if !strings.HasPrefix(image, prefix) {
+		return false
+	}
+	if strings.HasSuffix(prefix, "/") || len(image) == len(prefix) {
+		return true
+	}
+	return strings.ContainsRune("/:@", rune(image[len(prefix)]))
+}
+
+// RelocatePodSpec rewrites the images of all containers and init containers in spec
+func (c *ImageRelocationConfig) RelocatePodSpec(spec *corev1.PodSpec) {
+	if c == nil {
+		return
+	}
+	for i := range spec.InitContainers {
+		spec.InitContainers[i].Image = c.Relocate(spec.InitContainers[i].Image)
+	}
+	for i := range spec.Containers {
+		spec.Containers[i].Image = c.Relocate(spec.Containers[i].Image)
+	}
+}
+
+// PodSpecImages lists the images referenced by the containers and init containers in spec
+func PodSpecImages(spec corev1.PodSpec) []string {
+	var images []string
+	for _, c := range spec.InitContainers {
+		images = append(images, c.Image)
+	}
+	for _, c := range spec.Containers {
+		images = append(images, c.Image)
+	}
+	return images
+}
+
+// splitImage splits an image reference into its repository, tag and digest
+func splitImage(image string) (repository, tag, digest string) {
+	repository = image
+	if i := strings.Index(repository, "@"); i >= 0 {
+		repository, digest = repository[:i], repository[i+1:]
+	}
+	// a colon after the last slash separates the tag; earlier ones are registry ports
+	if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+		repository, tag = repository[:i], repository[i+1:]
+	}
+	return
+}
+
+// splitRegistry splits an image reference into the registry that hosts it and the rest of the reference
+func splitRegistry(image string) (registry, remainder string) {
+	i := strings.Index(image, "/")
+	if i < 0 {
+		// official images live under library/ on the default registry
+		return defaultRegistry, "library/" + image
+	}
+	// the first component is only a registry if it looks like a hostname
+	first := image[:i]
+	if !strings.ContainsAny(first, ".:") && first != "localhost" {
+		return defaultRegistry, image
+	}
+	return first, image[i+1:]
+}
@@ -0,0 +1,110 @@
+package install
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators/v1alpha1"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/clientfakes"
+)
+
+func TestImageRelocationConfigRelocate(t *testing.T) {
+	config := &ImageRelocationConfig{
+		Prefixes: []PrefixMapping{
+			{Source: "quay.io/", Mirror: "general.internal/"},
+			{Source: "quay.io/coreos/etcd", Mirror: "etcd.internal/etcd"},
+		},
+		Registries: []RegistryMapping{
+			{Source: "gcr.io", Mirror: "registry.internal:5000"},
+			{Source: "docker.io", Mirror: "dockerhub.internal"},
+		},
+		Digests: map[string]string{
+			"gcr.io/kubernetes-helm/tiller:v2.6.2": "sha256:abc",
+		},
+	}
+
+	tests := []struct {
+		image    string
+		expected string
+	}{
+		{"quay.io/coreos/etcd:v3.3", "etcd.internal/etcd:v3.3"},
+		{"quay.io/coreos/etcd@sha256:def", "etcd.internal/etcd@sha256:def"},
+		{"quay.io/coreos/etcd/backup:v1", "etcd.internal/etcd/backup:v1"},
+		{"quay.io/coreos/etcd", "etcd.internal/etcd"},
+		// a prefix only matches whole path components
+		{"quay.io/coreos/etcd-operator:v0.9.2", "general.internal/coreos/etcd-operator:v0.9.2"},
+		{"quay.io/coreos/olm:master", "general.internal/coreos/olm:master"},
+		{"gcr.io/kubernetes-helm/tiller:v2.6.2", "registry.internal:5000/kubernetes-helm/tiller@sha256:abc"},
+		{"gcr.io/google-containers/pause", "registry.internal:5000/google-containers/pause"},
+		{"nginx:1.15", "dockerhub.internal/library/nginx:1.15"},
+		{"coreos/etcd:v3", "dockerhub.internal/coreos/etcd:v3"},
+		{"localhost:5000/test:latest", "localhost:5000/test:latest"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			require.Equal(t, tt.expected, config.Relocate(tt.image))
+		})
+	}
+
+	var none *ImageRelocationConfig
+	require.Equal(t, "quay.io/coreos/olm:master", none.Relocate("quay.io/coreos/olm:master"))
+}
+
+func TestLoadImageRelocationConfig(t *testing.T) {
+	file, err := ioutil.TempFile("", "image-relocation")
+	require.NoError(t, err)
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString(`
+registries:
+- source: quay.io
+  mirror: registry.internal
+digests:
+  quay.io/coreos/olm:master: sha256:abc
+`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	config, err := LoadImageRelocationConfig(file.Name())
+	require.NoError(t, err)
+	require.Equal(t, &ImageRelocationConfig{
+		Registries: []RegistryMapping{{Source: "quay.io", Mirror: "registry.internal"}},
+		Digests:    map[string]string{"quay.io/coreos/olm:master": "sha256:abc"},
+	}, config)
+
+	require.NoError(t, ioutil.WriteFile(file.Name(), []byte("digests:\n  quay.io/coreos/olm:master: abc\n"), 0644))
+	_, err = LoadImageRelocationConfig(file.Name())
+	require.Error(t, err)
+}
+
+func TestInstallStrategyDeploymentInstallRelocatesImages(t *testing.T) {
+	namespace := "alm-test-deployment"
+	mockOwner := v1alpha1.ClusterServiceVersion{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "clusterserviceversion-owner",
+			Namespace: namespace,
+		},
+	}
+
+	fakeClient := new(clientfakes.FakeInstallStrategyDeploymentInterface)
+	config := &ImageRelocationConfig{Registries: []RegistryMapping{{Source: "quay.io", Mirror: "registry.internal"}}}
+	installer := NewStrategyDeploymentInstaller(fakeClient, &mockOwner, nil, config, nil).(*StrategyDeploymentInstaller)
+
+	strategy := strategy(1, namespace, &mockOwner)
+	strategy.DeploymentSpecs[0].Spec.Template.Spec = corev1.PodSpec{
+		InitContainers: []corev1.Container{{Name: "init", Image: "quay.io/coreos/init:v1"}},
+		Containers:     []corev1.Container{{Name: "operator", Image: "quay.io/coreos/operator:v1"}},
+	}
+
+	require.NoError(t, installer.installDeployments(strategy.DeploymentSpecs))
+	deployed := fakeClient.CreateOrUpdateDeploymentArgsForCall(0)
+	require.Equal(t, []string{"registry.internal/coreos/init:v1", "registry.internal/coreos/operator:v1"}, PodSpecImages(deployed.Spec.Template.Spec))
+
+	// the strategy itself is left untouched
+	require.Equal(t, []string{"quay.io/coreos/init:v1", "quay.io/coreos/operator:v1"}, PodSpecImages(strategy.DeploymentSpecs[0].Spec.Template.Spec))
+}
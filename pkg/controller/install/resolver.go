@@ -9,6 +9,7 @@ import (
 
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators/v1alpha1"
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/client"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/audit"
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/operatorclient"
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/ownerutil"
 )
@@ -22,12 +23,23 @@ type StrategyInstaller interface {
 	CheckInstalled(strategy Strategy) (bool, error)
 }
 
+// StrategyProgressReporter is implemented by StrategyInstallers that can report the rollout state of the
+// components they install
+type StrategyProgressReporter interface {
+	InstallProgress(strategy Strategy) ([]v1alpha1.DeploymentProgress, error)
+}
+
 type StrategyResolverInterface interface {
 	UnmarshalStrategy(s v1alpha1.NamedInstallStrategy) (strategy Strategy, err error)
 	InstallerForStrategy(strategyName string, opClient operatorclient.ClientInterface, owner ownerutil.Owner, previousStrategy Strategy) StrategyInstaller
 }
 
-type StrategyResolver struct{}
+type StrategyResolver struct {
+	// ImageRelocation, if set, rewrites the images of installed deployments
+	ImageRelocation *ImageRelocationConfig
+	// Auditor, if set, records the objects installers create, update and delete
+	Auditor *audit.Auditor
+}
 
 func (r *StrategyResolver) UnmarshalStrategy(s v1alpha1.NamedInstallStrategy) (strategy Strategy, err error) {
 	switch s.StrategyName {
@@ -46,7 +58,7 @@ func (r *StrategyResolver) InstallerForStrategy(strategyName string, opClient op
 	switch strategyName {
 	case InstallStrategyNameDeployment:
 		strategyClient := client.NewInstallStrategyDeploymentClient(opClient, owner.GetNamespace())
-		return NewStrategyDeploymentInstaller(strategyClient, owner, previousStrategy)
+		return NewStrategyDeploymentInstaller(strategyClient, owner, previousStrategy, r.ImageRelocation, r.Auditor)
 	}
 
 	// Insurance against these functions being called incorrectly (unmarshal strategy will return a valid strategy name)
@@ -0,0 +1,174 @@
+package catalog
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators/v1alpha1"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/controller/registry"
+)
+
+const (
+	// configMapIndex indexes CatalogSources by the namespace/name of each ConfigMap they're loaded from
+	configMapIndex = "configmap"
+
+	// selectedConfigMaps is indexed under configMapIndex, as namespace/selectedConfigMaps, for CatalogSources that
+	// select ConfigMaps by label. It isn't a valid ConfigMap name.
+	selectedConfigMaps = "*"
+
+	// catalogSourceIndex indexes Subscriptions by the namespace/name of the CatalogSource they subscribe from
+	catalogSourceIndex = "catalogsource"
+)
+
+// indexCatalogSourceByConfigMap is a cache.IndexFunc that returns the ConfigMaps a CatalogSource is loaded from
+func indexCatalogSourceByConfigMap(obj interface{}) ([]string, error) {
+	catsrc, ok := obj.(*v1alpha1.CatalogSource)
+	if !ok {
+		return nil, fmt.Errorf("casting CatalogSource failed")
+	}
+	if catsrc.Spec.SourceType == v1alpha1.SourceTypeGrpc || catsrc.Spec.SourceType == v1alpha1.SourceTypeHTTP {
+		return nil, nil
+	}
+
+	keys := []string{}
+	if catsrc.Spec.ConfigMap != "" {
+		keys = append(keys, catsrc.GetNamespace()+"/"+catsrc.Spec.ConfigMap)
+	}
+	for _, name := range catsrc.Spec.ConfigMaps {
+		keys = append(keys, catsrc.GetNamespace()+"/"+name)
+	}
+	if catsrc.Spec.ConfigMapSelector != nil {
+		keys = append(keys, catsrc.GetNamespace()+"/"+selectedConfigMaps)
+	}
+	return keys, nil
+}
+
+// indexSubscriptionByCatalogSource is a cache.IndexFunc that returns the CatalogSource a Subscription subscribes from
+func (o *Operator) indexSubscriptionByCatalogSource(obj interface{}) ([]string, error) {
+	sub, ok := obj.(*v1alpha1.Subscription)
+	if !ok {
+		return nil, fmt.Errorf("casting Subscription failed")
+	}
+	if sub.Spec == nil {
+		return nil, nil
+	}
+	catalogNamespace := sub.Spec.CatalogSourceNamespace
+	if catalogNamespace == "" {
+		catalogNamespace = o.namespace
+	}
+	return []string{catalogNamespace + "/" + sub.Spec.CatalogSource}, nil
+}
+
+// configMapEventHandler reloads the CatalogSources loaded from a ConfigMap that was added, changed or deleted. Events of
+// ConfigMaps no CatalogSource is loaded from are dropped, as are resyncs, which CatalogSources get on their own.
+func (o *Operator) configMapEventHandler() cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: o.requeueCatalogSourcesForConfigMap,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldCM, oldOK := oldObj.(*v1.ConfigMap)
+			newCM, newOK := newObj.(*v1.ConfigMap)
+			if oldOK && newOK && oldCM.GetResourceVersion() == newCM.GetResourceVersion() {
+				return
+			}
+			// a ConfigMap whose labels changed may no longer be selected by the CatalogSources it was loaded into
+			o.requeueCatalogSourcesForConfigMap(oldObj)
+			o.requeueCatalogSourcesForConfigMap(newObj)
+		},
+		DeleteFunc: o.requeueCatalogSourcesForConfigMap,
+	}
+}
+
+// requeueCatalogSourcesForConfigMap queues the CatalogSources loaded from a ConfigMap to be reloaded
+func (o *Operator) requeueCatalogSourcesForConfigMap(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	cm, ok := obj.(*v1.ConfigMap)
+	if !ok {
+		log.Debugf("wrong type: %#v", obj)
+		return
+	}
+
+	catsrcs, err := o.catalogSourcesForConfigMap(cm)
+	if err != nil {
+		log.Infof("error listing CatalogSources loaded from ConfigMap %s/%s: %s", cm.GetNamespace(), cm.GetName(), err)
+		return
+	}
+	for _, catsrc := range catsrcs {
+		k, err := cache.MetaNamespaceKeyFunc(catsrc)
+		if err != nil {
+			log.Infof("creating key failed: %s", err)
+			continue
+		}
+		log.Infof("ConfigMap %s/%s changed, reloading CatalogSource %s", cm.GetNamespace(), cm.GetName(), k)
+		o.catsrcQueue.Add(k)
+	}
+}
+
+// catalogSourcesForConfigMap returns the CatalogSources loaded from a ConfigMap, by name or by label
+func (o *Operator) catalogSourcesForConfigMap(cm *v1.ConfigMap) ([]*v1alpha1.CatalogSource, error) {
+	catsrcs := []*v1alpha1.CatalogSource{}
+	for _, indexer := range o.catsrcIndexers {
+		named, err := indexer.ByIndex(configMapIndex, cm.GetNamespace()+"/"+cm.GetName())
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range named {
+			if catsrc, ok := obj.(*v1alpha1.CatalogSource); ok {
+				catsrcs = append(catsrcs, catsrc)
+			}
+		}
+
+		selecting, err := indexer.ByIndex(configMapIndex, cm.GetNamespace()+"/"+selectedConfigMaps)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range selecting {
+			catsrc, ok := obj.(*v1alpha1.CatalogSource)
+			if !ok {
+				continue
+			}
+			set, err := registry.ConfigMapSetForCatalogSource(catsrc.Spec)
+			if err != nil || set.Selector == nil || !set.Selector.Matches(labels.Set(cm.GetLabels())) {
+				continue
+			}
+			if !containsCatalogSource(catsrcs, catsrc) {
+				catsrcs = append(catsrcs, catsrc)
+			}
+		}
+	}
+	return catsrcs, nil
+}
+
+func containsCatalogSource(catsrcs []*v1alpha1.CatalogSource, catsrc *v1alpha1.CatalogSource) bool {
+	for _, c := range catsrcs {
+		if c.GetNamespace() == catsrc.GetNamespace() && c.GetName() == catsrc.GetName() {
+			return true
+		}
+	}
+	return false
+}
+
+// requeueSubscriptionsForCatalogSource queues the Subscriptions to a CatalogSource whose catalog changed, so that
+// they're re-evaluated against it right away
+func (o *Operator) requeueSubscriptionsForCatalogSource(key registry.SourceKey) {
+	for _, indexer := range o.subIndexers {
+		subs, err := indexer.ByIndex(catalogSourceIndex, key.Namespace+"/"+key.Name)
+		if err != nil {
+			log.Infof("error listing Subscriptions to CatalogSource %s/%s: %s", key.Namespace, key.Name, err)
+			continue
+		}
+		for _, obj := range subs {
+			k, err := cache.MetaNamespaceKeyFunc(obj)
+			if err != nil {
+				log.Infof("creating key failed: %s", err)
+				continue
+			}
+			o.subQueue.Add(k)
+		}
+	}
+}
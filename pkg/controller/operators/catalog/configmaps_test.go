@@ -0,0 +1,208 @@
+package catalog
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators/v1alpha1"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/clientset/versioned/fake"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/controller/registry"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/operatorclient"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/queueinformer"
+)
+
+// drain returns the keys in a queue, sorted
+func drain(queue workqueue.RateLimitingInterface) []string {
+	keys := []string{}
+	for queue.Len() > 0 {
+		key, _ := queue.Get()
+		keys = append(keys, key.(string))
+		queue.Done(key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestConfigMapEventHandler(t *testing.T) {
+	catalogSource := func(name string, spec v1alpha1.CatalogSourceSpec) *v1alpha1.CatalogSource {
+		return &v1alpha1.CatalogSource{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"}, Spec: spec}
+	}
+	catsrcIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{configMapIndex: indexCatalogSourceByConfigMap})
+	for _, catsrc := range []*v1alpha1.CatalogSource{
+		catalogSource("named", v1alpha1.CatalogSourceSpec{ConfigMap: "catalog"}),
+		catalogSource("split", v1alpha1.CatalogSourceSpec{ConfigMap: "other", ConfigMaps: []string{"catalog"}}),
+		catalogSource("selected", v1alpha1.CatalogSourceSpec{
+			ConfigMapSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"catalog": "selected"}},
+		}),
+		catalogSource("both", v1alpha1.CatalogSourceSpec{
+			ConfigMap:         "catalog",
+			ConfigMapSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"catalog": "selected"}},
+		}),
+		catalogSource("registry", v1alpha1.CatalogSourceSpec{SourceType: v1alpha1.SourceTypeGrpc, ConfigMap: "catalog"}),
+		catalogSource("unrelated", v1alpha1.CatalogSourceSpec{ConfigMap: "unrelated"}),
+	} {
+		require.NoError(t, catsrcIndexer.Add(catsrc))
+	}
+
+	configMap := func(name, resourceVersion string, labels map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns", ResourceVersion: resourceVersion, Labels: labels}}
+	}
+	selected := map[string]string{"catalog": "selected"}
+
+	tests := []struct {
+		description string
+		event       func(handler cache.ResourceEventHandler)
+		expected    []string
+	}{
+		{
+			description: "Named",
+			event:       func(h cache.ResourceEventHandler) { h.OnAdd(configMap("catalog", "1", nil)) },
+			expected:    []string{"ns/both", "ns/named", "ns/split"},
+		},
+		{
+			description: "Selected",
+			event:       func(h cache.ResourceEventHandler) { h.OnAdd(configMap("catalog", "1", selected)) },
+			expected:    []string{"ns/both", "ns/named", "ns/selected", "ns/split"},
+		},
+		{
+			description: "Unrelated",
+			event:       func(h cache.ResourceEventHandler) { h.OnAdd(configMap("settings", "1", nil)) },
+			expected:    []string{},
+		},
+		{
+			description: "Resync",
+			event: func(h cache.ResourceEventHandler) {
+				h.OnUpdate(configMap("catalog", "1", nil), configMap("catalog", "1", nil))
+			},
+			expected: []string{},
+		},
+		{
+			// the CatalogSource the ConfigMap was selected by has to drop it
+			description: "Unselected",
+			event: func(h cache.ResourceEventHandler) {
+				h.OnUpdate(configMap("settings", "1", selected), configMap("settings", "2", nil))
+			},
+			expected: []string{"ns/both", "ns/selected"},
+		},
+		{
+			description: "Deleted",
+			event: func(h cache.ResourceEventHandler) {
+				h.OnDelete(cache.DeletedFinalStateUnknown{Key: "ns/catalog", Obj: configMap("catalog", "1", nil)})
+			},
+			expected: []string{"ns/both", "ns/named", "ns/split"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			op := &Operator{
+				catsrcQueue:    workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+				catsrcIndexers: []cache.Indexer{catsrcIndexer},
+			}
+			defer op.catsrcQueue.ShutDown()
+
+			tt.event(op.configMapEventHandler())
+			require.Equal(t, tt.expected, drain(op.catsrcQueue))
+		})
+	}
+}
+
+func TestSyncCatalogSourcesRequeuesSubscriptions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "catalog", Namespace: "ns"},
+		Data: map[string]string{
+			registry.ConfigMapCSVName:     "- metadata:\n    name: test.v1\n  spec:\n    displayName: Test\n",
+			registry.ConfigMapPackageName: "- packageName: test\n  channels:\n  - name: alpha\n    currentCSV: test.v1\n",
+		},
+	}
+	catsrc := &v1alpha1.CatalogSource{
+		ObjectMeta: metav1.ObjectMeta{Name: "catsrc", Namespace: "ns"},
+		Spec:       v1alpha1.CatalogSourceSpec{ConfigMap: "catalog"},
+	}
+	mockClient := operatorclient.NewMockClientInterface(ctrl)
+	mockClient.EXPECT().KubernetesInterface().Return(k8sfake.NewSimpleClientset(cm)).AnyTimes()
+
+	op := &Operator{
+		Operator:  &queueinformer.Operator{OpClient: mockClient},
+		client:    fake.NewSimpleClientset(catsrc),
+		namespace: "ns",
+		sources:   map[registry.SourceKey]registry.Source{},
+		subQueue:  workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+	}
+	defer op.subQueue.ShutDown()
+
+	subIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{catalogSourceIndex: op.indexSubscriptionByCatalogSource})
+	for _, sub := range []*v1alpha1.Subscription{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "explicit", Namespace: "app"},
+			Spec:       &v1alpha1.SubscriptionSpec{CatalogSource: "catsrc", CatalogSourceNamespace: "ns"},
+		},
+		{
+			// the catalog namespace defaults to the operator's namespace
+			ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "app"},
+			Spec:       &v1alpha1.SubscriptionSpec{CatalogSource: "catsrc"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "app"},
+			Spec:       &v1alpha1.SubscriptionSpec{CatalogSource: "other", CatalogSourceNamespace: "ns"},
+		},
+	} {
+		require.NoError(t, subIndexer.Add(sub))
+	}
+	op.subIndexers = []cache.Indexer{subIndexer}
+
+	require.NoError(t, op.syncCatalogSources(catsrc))
+	require.Equal(t, []string{"app/default", "app/explicit"}, drain(op.subQueue))
+
+	// subscriptions aren't requeued when the catalog didn't change
+	stored, err := op.client.OperatorsV1alpha1().CatalogSources("ns").Get("catsrc", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NoError(t, op.syncCatalogSources(stored))
+	require.Empty(t, drain(op.subQueue))
+}
+
+func TestLoadConfigMapCatalogFromLister(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "catalog", Namespace: "ns", Labels: map[string]string{"catalog": "selected"}},
+		Data: map[string]string{
+			registry.ConfigMapCSVName:     "- metadata:\n    name: test.v1\n  spec:\n    displayName: Test\n",
+			registry.ConfigMapPackageName: "- packageName: test\n  channels:\n  - name: alpha\n    currentCSV: test.v1\n",
+		},
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	require.NoError(t, indexer.Add(cm))
+
+	// the api server isn't called, so the mock has no expectations
+	op := &Operator{
+		Operator:         &queueinformer.Operator{OpClient: operatorclient.NewMockClientInterface(ctrl)},
+		namespace:        "ns",
+		sources:          map[registry.SourceKey]registry.Source{},
+		configMapListers: map[string]corelisters.ConfigMapLister{metav1.NamespaceAll: corelisters.NewConfigMapLister(indexer)},
+	}
+
+	for _, spec := range []v1alpha1.CatalogSourceSpec{
+		{ConfigMap: "catalog"},
+		{ConfigMapSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"catalog": "selected"}}},
+	} {
+		catsrc := &v1alpha1.CatalogSource{ObjectMeta: metav1.ObjectMeta{Name: "catsrc", Namespace: "ns"}, Spec: spec}
+		catalog, err := op.loadConfigMapCatalog(catsrc, nil)
+		require.NoError(t, err)
+		csv, err := catalog.FindCSVByName("test.v1")
+		require.NoError(t, err)
+		require.Equal(t, "test.v1", csv.GetName())
+	}
+}
@@ -0,0 +1,160 @@
+package catalog
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators/v1alpha1"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/cron"
+)
+
+// holdRecheckInterval is how often a held InstallPlan is checked again, so that lifted freezes and changed
+// maintenance windows are noticed without a change to the InstallPlan
+const holdRecheckInterval = time.Minute
+
+// HoldPlan returns why an approved InstallPlan may not be executed yet, or nil if it may. Every InstallPlan is held
+// while an UpgradePolicy in the operator namespace freezes upgrades. An automatically approved InstallPlan is also
+// held unless a maintenance window is open for every UpgradePolicy and for the Subscription that owns it, of those
+// that have maintenance windows.
+func (o *Operator) HoldPlan(plan *v1alpha1.InstallPlan) *v1alpha1.InstallPlanHold {
+	policies, err := o.upgradePolicies()
+	if err != nil {
+		// don't upgrade while unsure whether upgrades are allowed
+		return &v1alpha1.InstallPlanHold{
+			Reason:  v1alpha1.InstallPlanHoldUpgradesFrozen,
+			Message: fmt.Sprintf("failed to list UpgradePolicies: %s", err),
+		}
+	}
+	for _, policy := range policies {
+		if !policy.Spec.Freeze {
+			continue
+		}
+		message := fmt.Sprintf("upgrades are frozen by UpgradePolicy %s", policy.GetName())
+		if policy.Spec.FreezeMessage != "" {
+			message += ": " + policy.Spec.FreezeMessage
+		}
+		return &v1alpha1.InstallPlanHold{Reason: v1alpha1.InstallPlanHoldUpgradesFrozen, Message: message}
+	}
+
+	// approving a manual InstallPlan is itself the decision of when to upgrade
+	if plan.Spec.Approval == v1alpha1.ApprovalManual {
+		return nil
+	}
+	now := o.now().Time
+	for _, policy := range policies {
+		if hold := maintenanceWindowHold(policy.Spec.MaintenanceWindows, now, "UpgradePolicy "+policy.GetName()); hold != nil {
+			return hold
+		}
+	}
+	if sub := o.subscriptionForInstallPlan(plan); sub != nil && sub.Spec != nil {
+		return maintenanceWindowHold(sub.Spec.MaintenanceWindows, now, "Subscription "+sub.GetName())
+	}
+	return nil
+}
+
+// upgradePolicies returns the UpgradePolicies in the operator namespace, by name
+func (o *Operator) upgradePolicies() ([]*v1alpha1.UpgradePolicy, error) {
+	policies, err := o.upgradePolicyLister.UpgradePolicies(o.namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(policies, func(i, j int) bool { return policies[i].GetName() < policies[j].GetName() })
+	return policies, nil
+}
+
+// subscriptionForInstallPlan returns the Subscription that owns an InstallPlan, or nil if none does
+func (o *Operator) subscriptionForInstallPlan(plan *v1alpha1.InstallPlan) *v1alpha1.Subscription {
+	for _, owner := range plan.GetOwnerReferences() {
+		if owner.Kind != v1alpha1.SubscriptionKind {
+			continue
+		}
+		for _, indexer := range o.subIndexers {
+			obj, ok, err := indexer.GetByKey(plan.GetNamespace() + "/" + owner.Name)
+			if err != nil {
+				log.Infof("error getting Subscription %s owning InstallPlan %s: %s", owner.Name, plan.GetName(), err)
+				continue
+			}
+			if sub, isSub := obj.(*v1alpha1.Subscription); ok && isSub {
+				return sub
+			}
+		}
+	}
+	return nil
+}
+
+// maintenanceWindowHold returns a hold if none of an owner's maintenance windows is open at now, or nil if one is or
+// the owner has none
+func maintenanceWindowHold(windows []v1alpha1.MaintenanceWindow, now time.Time, owner string) *v1alpha1.InstallPlanHold {
+	if len(windows) == 0 {
+		return nil
+	}
+	open, opens, err := maintenanceWindowsOpen(windows, now)
+	if err != nil {
+		return &v1alpha1.InstallPlanHold{
+			Reason:  v1alpha1.InstallPlanHoldMaintenanceWindowClosed,
+			Message: fmt.Sprintf("invalid maintenance window of %s: %s", owner, err),
+		}
+	}
+	if open {
+		return nil
+	}
+	hold := &v1alpha1.InstallPlanHold{
+		Reason:  v1alpha1.InstallPlanHoldMaintenanceWindowClosed,
+		Message: fmt.Sprintf("no maintenance window of %s is open", owner),
+	}
+	if !opens.IsZero() {
+		until := metav1.NewTime(opens.UTC())
+		hold.Until = &until
+		hold.Message += fmt.Sprintf("; the next opens at %s", opens.Format(time.RFC3339))
+	}
+	return hold
+}
+
+// maintenanceWindowsOpen reports whether any of the maintenance windows is open at now. If none is, it returns when
+// the next one opens, or the zero time if none ever does.
+func maintenanceWindowsOpen(windows []v1alpha1.MaintenanceWindow, now time.Time) (open bool, opens time.Time, err error) {
+	for _, window := range windows {
+		schedule, err := cron.Parse(window.Schedule)
+		if err != nil {
+			return false, time.Time{}, err
+		}
+		if window.Duration.Duration <= 0 {
+			return false, time.Time{}, fmt.Errorf("invalid duration %s for schedule %q", window.Duration.Duration, window.Schedule)
+		}
+		// an empty time zone loads UTC
+		location, err := time.LoadLocation(window.TimeZone)
+		if err != nil {
+			return false, time.Time{}, fmt.Errorf("invalid time zone %q: %s", window.TimeZone, err)
+		}
+
+		// the window is open if it last opened less than its duration ago
+		start := schedule.Next(now.In(location).Add(-window.Duration.Duration))
+		if start.IsZero() {
+			continue
+		}
+		if !start.After(now) {
+			return true, time.Time{}, nil
+		}
+		if opens.IsZero() || start.Before(opens) {
+			opens = start
+		}
+	}
+	return false, opens, nil
+}
+
+// requeueHeldInstallPlan syncs a held InstallPlan again when the next maintenance window opens, or after
+// holdRecheckInterval if that's sooner
+func (o *Operator) requeueHeldInstallPlan(plan *v1alpha1.InstallPlan) {
+	delay := holdRecheckInterval
+	if hold := plan.Status.Hold; hold != nil && hold.Until != nil {
+		if untilOpen := hold.Until.Sub(o.now().Time); untilOpen > 0 && untilOpen < delay {
+			delay = untilOpen
+		}
+	}
+	o.requeueInstallPlan(plan, delay)
+}
@@ -0,0 +1,168 @@
+package catalog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators/v1alpha1"
+	listers "github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/listers/operators/v1alpha1"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/ownerutil"
+)
+
+func TestMaintenanceWindowsOpen(t *testing.T) {
+	// 22:00 to 02:00 Berlin time on weekdays, which is 20:00 to 00:00 UTC in the summer
+	windows := []v1alpha1.MaintenanceWindow{{
+		Schedule: "0 22 * * 1-5",
+		Duration: metav1.Duration{Duration: 4 * time.Hour},
+		TimeZone: "Europe/Berlin",
+	}}
+
+	// Wednesday
+	open, _, err := maintenanceWindowsOpen(windows, time.Date(2018, 7, 4, 23, 30, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.True(t, open)
+
+	open, opens, err := maintenanceWindowsOpen(windows, time.Date(2018, 7, 5, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.False(t, open)
+	require.True(t, time.Date(2018, 7, 5, 20, 0, 0, 0, time.UTC).Equal(opens), "opens at %s", opens)
+
+	// Friday night's window opens next on Monday
+	_, opens, err = maintenanceWindowsOpen(windows, time.Date(2018, 7, 7, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.True(t, time.Date(2018, 7, 9, 20, 0, 0, 0, time.UTC).Equal(opens), "opens at %s", opens)
+
+	// the soonest window to open is returned
+	windows = append(windows, v1alpha1.MaintenanceWindow{Schedule: "0 6 * * *", Duration: metav1.Duration{Duration: time.Hour}})
+	_, opens, err = maintenanceWindowsOpen(windows, time.Date(2018, 7, 7, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.True(t, time.Date(2018, 7, 8, 6, 0, 0, 0, time.UTC).Equal(opens), "opens at %s", opens)
+
+	_, _, err = maintenanceWindowsOpen([]v1alpha1.MaintenanceWindow{{Schedule: "0 6 * * *", Duration: metav1.Duration{Duration: time.Hour}, TimeZone: "Mars/Olympus"}}, time.Now())
+	require.EqualError(t, err, `invalid time zone "Mars/Olympus": unknown time zone Mars/Olympus`)
+	_, _, err = maintenanceWindowsOpen([]v1alpha1.MaintenanceWindow{{Schedule: "0 6 * * *"}}, time.Now())
+	require.EqualError(t, err, `invalid duration 0s for schedule "0 6 * * *"`)
+}
+
+func TestHoldPlan(t *testing.T) {
+	// Saturday noon
+	now := time.Date(2018, 7, 7, 12, 0, 0, 0, time.UTC)
+	nightly := []v1alpha1.MaintenanceWindow{{Schedule: "0 2 * * *", Duration: metav1.Duration{Duration: 2 * time.Hour}}}
+	weekends := []v1alpha1.MaintenanceWindow{{Schedule: "0 0 * * 6", Duration: metav1.Duration{Duration: 48 * time.Hour}}}
+	nextNight := metav1.NewTime(time.Date(2018, 7, 8, 2, 0, 0, 0, time.UTC))
+
+	sub := &v1alpha1.Subscription{
+		TypeMeta:   metav1.TypeMeta{Kind: v1alpha1.SubscriptionKind, APIVersion: v1alpha1.SubscriptionCRDAPIVersion},
+		ObjectMeta: metav1.ObjectMeta{Name: "sub", Namespace: "ns", UID: "sub-uid"},
+		Spec:       &v1alpha1.SubscriptionSpec{MaintenanceWindows: nightly},
+	}
+	policy := func(name string, spec v1alpha1.UpgradePolicySpec) *v1alpha1.UpgradePolicy {
+		return &v1alpha1.UpgradePolicy{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "olm"}, Spec: spec}
+	}
+
+	tests := []struct {
+		description string
+		existing    []runtime.Object
+		approval    v1alpha1.Approval
+		owned       bool
+		expected    *v1alpha1.InstallPlanHold
+	}{
+		{
+			description: "NoPolicies",
+			approval:    v1alpha1.ApprovalAutomatic,
+		},
+		{
+			description: "Frozen",
+			existing:    []runtime.Object{policy("freeze", v1alpha1.UpgradePolicySpec{Freeze: true, FreezeMessage: "quarter end"})},
+			approval:    v1alpha1.ApprovalManual,
+			expected: &v1alpha1.InstallPlanHold{
+				Reason:  v1alpha1.InstallPlanHoldUpgradesFrozen,
+				Message: "upgrades are frozen by UpgradePolicy freeze: quarter end",
+			},
+		},
+		{
+			description: "FrozenInOtherNamespace",
+			existing: []runtime.Object{&v1alpha1.UpgradePolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "freeze", Namespace: "ns"},
+				Spec:       v1alpha1.UpgradePolicySpec{Freeze: true},
+			}},
+			approval: v1alpha1.ApprovalAutomatic,
+		},
+		{
+			description: "PolicyWindowOpen",
+			existing:    []runtime.Object{policy("weekends", v1alpha1.UpgradePolicySpec{MaintenanceWindows: weekends})},
+			approval:    v1alpha1.ApprovalAutomatic,
+		},
+		{
+			description: "PolicyWindowClosed",
+			existing:    []runtime.Object{policy("nightly", v1alpha1.UpgradePolicySpec{MaintenanceWindows: nightly})},
+			approval:    v1alpha1.ApprovalAutomatic,
+			expected: &v1alpha1.InstallPlanHold{
+				Reason:  v1alpha1.InstallPlanHoldMaintenanceWindowClosed,
+				Message: "no maintenance window of UpgradePolicy nightly is open; the next opens at 2018-07-08T02:00:00Z",
+				Until:   &nextNight,
+			},
+		},
+		{
+			description: "PolicyWindowClosedManualApproval",
+			existing:    []runtime.Object{policy("nightly", v1alpha1.UpgradePolicySpec{MaintenanceWindows: nightly})},
+			approval:    v1alpha1.ApprovalManual,
+		},
+		{
+			description: "SubscriptionWindowClosed",
+			existing:    []runtime.Object{sub, policy("weekends", v1alpha1.UpgradePolicySpec{MaintenanceWindows: weekends})},
+			approval:    v1alpha1.ApprovalAutomatic,
+			owned:       true,
+			expected: &v1alpha1.InstallPlanHold{
+				Reason:  v1alpha1.InstallPlanHoldMaintenanceWindowClosed,
+				Message: "no maintenance window of Subscription sub is open; the next opens at 2018-07-08T02:00:00Z",
+				Until:   &nextNight,
+			},
+		},
+		{
+			description: "InvalidWindow",
+			existing: []runtime.Object{policy("broken", v1alpha1.UpgradePolicySpec{MaintenanceWindows: []v1alpha1.MaintenanceWindow{
+				{Schedule: "0 25 * * *", Duration: metav1.Duration{Duration: time.Hour}},
+			}})},
+			approval: v1alpha1.ApprovalAutomatic,
+			expected: &v1alpha1.InstallPlanHold{
+				Reason:  v1alpha1.InstallPlanHoldMaintenanceWindowClosed,
+				Message: `invalid maintenance window of UpgradePolicy broken: invalid cron schedule "0 25 * * *": invalid hour "25": expected 0-23`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			policyIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			subIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			for _, obj := range tt.existing {
+				switch obj := obj.(type) {
+				case *v1alpha1.UpgradePolicy:
+					require.NoError(t, policyIndexer.Add(obj))
+				case *v1alpha1.Subscription:
+					require.NoError(t, subIndexer.Add(obj))
+				}
+			}
+			op := &Operator{
+				upgradePolicyLister: listers.NewUpgradePolicyLister(policyIndexer),
+				subIndexers:         []cache.Indexer{subIndexer},
+				namespace:           "olm",
+				clock:               clock.NewFakeClock(now),
+			}
+			plan := &v1alpha1.InstallPlan{
+				ObjectMeta: metav1.ObjectMeta{Name: "install", Namespace: "ns"},
+				Spec:       v1alpha1.InstallPlanSpec{Approval: tt.approval},
+			}
+			if tt.owned {
+				ownerutil.AddNonBlockingOwner(plan, sub)
+			}
+			require.Equal(t, tt.expected, op.HoldPlan(plan))
+		})
+	}
+}
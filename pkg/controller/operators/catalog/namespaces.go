@@ -0,0 +1,32 @@
+package catalog
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/controller/registry"
+)
+
+// catalogNamespaces returns the namespaces CatalogSources are watched in: the operator namespace, which holds the
+// global catalogs, and every watched namespace, which may hold catalogs private to it.
+func catalogNamespaces(operatorNamespace string, watchedNamespaces []string) []string {
+	namespaces := []string{operatorNamespace}
+	seen := map[string]struct{}{operatorNamespace: {}}
+	for _, namespace := range watchedNamespaces {
+		if namespace == metav1.NamespaceAll {
+			return []string{metav1.NamespaceAll}
+		}
+		if _, ok := seen[namespace]; ok {
+			continue
+		}
+		seen[namespace] = struct{}{}
+		namespaces = append(namespaces, namespace)
+	}
+	return namespaces
+}
+
+// sourceVisibleTo reports whether the CatalogSource with the given key may be used for Subscriptions and InstallPlans
+// in namespace. CatalogSources in the operator namespace are visible to every namespace; any other CatalogSource is
+// only visible to its own namespace.
+func (o *Operator) sourceVisibleTo(key registry.SourceKey, namespace string) bool {
+	return key.Namespace == o.namespace || key.Namespace == namespace
+}
@@ -0,0 +1,120 @@
+package catalog
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators/v1alpha1"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/clientset/versioned/fake"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/controller/registry"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/controller/registry/resolver"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/operatorclient"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/queueinformer"
+)
+
+// snapshotResolver resolves every InstallPlan to a single step from the first source it's given
+type snapshotResolver struct{}
+
+var _ resolver.DependencyResolver = &snapshotResolver{}
+
+func (r *snapshotResolver) ResolveInstallPlan(sourceRefs []registry.SourceRef, catalogLabelKey string, plan *v1alpha1.InstallPlan) ([]v1alpha1.Step, []registry.SourceKey, error) {
+	if len(sourceRefs) == 0 {
+		return nil, nil, fmt.Errorf("not found: ClusterServiceVersion %s", plan.Spec.ClusterServiceVersionNames[0])
+	}
+	key := sourceRefs[0].SourceKey
+	step := v1alpha1.Step{Resource: v1alpha1.StepResource{
+		CatalogSource:          key.Name,
+		CatalogSourceNamespace: key.Namespace,
+		Kind:                   v1alpha1.ClusterServiceVersionKind,
+		Name:                   plan.Spec.ClusterServiceVersionNames[0],
+	}}
+	return []v1alpha1.Step{step}, []registry.SourceKey{key}, nil
+}
+
+func TestCatalogNamespaces(t *testing.T) {
+	require.Equal(t, []string{"olm"}, catalogNamespaces("olm", nil))
+	require.Equal(t, []string{"olm", "a", "b"}, catalogNamespaces("olm", []string{"a", "olm", "b", "a"}))
+	require.Equal(t, []string{metav1.NamespaceAll}, catalogNamespaces("olm", []string{"a", metav1.NamespaceAll}))
+}
+
+func TestResolvePlanTenantCatalogs(t *testing.T) {
+	tenantKey := registry.SourceKey{Name: "private", Namespace: "tenant-a"}
+	client := fake.NewSimpleClientset(&v1alpha1.CatalogSource{
+		ObjectMeta: metav1.ObjectMeta{Name: tenantKey.Name, Namespace: tenantKey.Namespace},
+		Spec:       v1alpha1.CatalogSourceSpec{Secrets: []string{"pull-secret"}},
+	})
+
+	tests := []struct {
+		description   string
+		plan          *v1alpha1.InstallPlan
+		expectedError string
+	}{
+		{
+			description: "TenantNamespace",
+			plan: &v1alpha1.InstallPlan{
+				ObjectMeta: metav1.ObjectMeta{Name: "install", Namespace: "tenant-a"},
+				Spec:       v1alpha1.InstallPlanSpec{ClusterServiceVersionNames: []string{"private.v1"}},
+			},
+		},
+		{
+			description: "OtherNamespace",
+			plan: &v1alpha1.InstallPlan{
+				ObjectMeta: metav1.ObjectMeta{Name: "install", Namespace: "tenant-b"},
+				Spec:       v1alpha1.InstallPlanSpec{ClusterServiceVersionNames: []string{"private.v1"}},
+			},
+			expectedError: "not found: ClusterServiceVersion private.v1",
+		},
+		{
+			description: "OtherNamespaceNamesCatalog",
+			plan: &v1alpha1.InstallPlan{
+				ObjectMeta: metav1.ObjectMeta{Name: "install", Namespace: "tenant-b"},
+				Spec: v1alpha1.InstallPlanSpec{
+					CatalogSource:              tenantKey.Name,
+					CatalogSourceNamespace:     tenantKey.Namespace,
+					ClusterServiceVersionNames: []string{"private.v1"},
+				},
+			},
+			expectedError: "catalog source private in namespace tenant-a is not visible to namespace tenant-b",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockClient := operatorclient.NewMockClientInterface(ctrl)
+			mockClient.EXPECT().KubernetesInterface().Return(k8sfake.NewSimpleClientset(&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "pull-secret", Namespace: tenantKey.Namespace},
+			})).AnyTimes()
+
+			op := &Operator{
+				Operator:           &queueinformer.Operator{OpClient: mockClient},
+				client:             client,
+				namespace:          "olm",
+				sources:            map[registry.SourceKey]registry.Source{tenantKey: registry.NewInMem()},
+				dependencyResolver: &snapshotResolver{},
+			}
+
+			tt.plan.Status.Phase = v1alpha1.InstallPlanPhasePlanning
+			err := op.ResolvePlan(tt.plan)
+			if tt.expectedError != "" {
+				require.EqualError(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, tt.plan.Status.Plan, 2)
+
+			// secrets are taken from the namespace of the catalog that requires them
+			secret := tt.plan.Status.Plan[0].Resource
+			require.Equal(t, "pull-secret", secret.Name)
+			require.Equal(t, tenantKey.Namespace, secret.CatalogSourceNamespace)
+			require.Equal(t, v1alpha1.StepStatusPresent, tt.plan.Status.Plan[0].Status)
+			require.Equal(t, tenantKey.Namespace, tt.plan.Status.Plan[1].Resource.CatalogSourceNamespace)
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -12,21 +13,35 @@ import (
 	v1beta1ext "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/informers"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
 
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators/v1alpha1"
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/client"
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/clientset/versioned"
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/informers/externalversions"
+	listers "github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/listers/operators/v1alpha1"
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/controller/registry"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/controller/registry/remote"
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/controller/registry/resolver"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/audit"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/operatorclient"
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/queueinformer"
-	"k8s.io/client-go/util/workqueue"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/statusutil"
 )
 
 const (
 	crdKind    = "CustomResourceDefinition"
 	secretKind = "Secret"
+
+	// defaultArchivePollInterval is how often the catalog archives of http CatalogSources are checked for updates
+	defaultArchivePollInterval = 5 * time.Minute
+	// crdEstablishedRecheckInterval is how often an InstallPlan waiting for its CRDs to be established is executed again
+	crdEstablishedRecheckInterval = 5 * time.Second
 )
 
 //for test stubbing and for ensuring standardization of timezones to UTC
@@ -36,96 +51,211 @@ var timeNow = func() metav1.Time { return metav1.NewTime(time.Now().UTC()) }
 // resolving dependencies in a catalog.
 type Operator struct {
 	*queueinformer.Operator
-	client             versioned.Interface
-	namespace          string
-	sources            map[registry.SourceKey]registry.Source
-	sourcesLock        sync.RWMutex
-	sourcesLastUpdate  metav1.Time
+	client    versioned.Interface
+	namespace string
+	sources   map[registry.SourceKey]registry.Source
+	// sourcePriorities holds the priorities of sources with a non-zero priority
+	sourcePriorities  map[registry.SourceKey]int
+	sourcesLock       sync.RWMutex
+	sourcesLastUpdate metav1.Time
+	archives          map[registry.SourceKey]*archiveSource
+	archivesLock      sync.Mutex
+	catsrcQueue       workqueue.RateLimitingInterface
+	// registries are the clients of grpc CatalogSources' registry servers, kept so their response caches are too
+	registries     map[registry.SourceKey]*remote.Client
+	registriesLock sync.Mutex
+	// catsrcIndexers index CatalogSources by the ConfigMaps they're loaded from
+	catsrcIndexers []cache.Indexer
+	subQueue       workqueue.RateLimitingInterface
+	ipQueue        workqueue.RateLimitingInterface
+	// configMapListers read the ConfigMaps of each catalog namespace, or of all namespaces under ""
+	configMapListers map[string]corelisters.ConfigMapLister
+	// ipListers and csvListers read the InstallPlans and CSVs of each watched namespace, or of all namespaces under ""
+	ipListers  map[string]listers.InstallPlanLister
+	csvListers map[string]listers.ClusterServiceVersionLister
+	// subIndexers index Subscriptions by the CatalogSource they subscribe from
+	subIndexers        []cache.Indexer
 	subscriptions      map[registry.SubscriptionKey]v1alpha1.Subscription
 	subscriptionsLock  sync.RWMutex
 	dependencyResolver resolver.DependencyResolver
+	// serviceAccountClients creates clients for installing with a user-supplied ServiceAccount; nil installs
+	// everything with OLM's own identity
+	serviceAccountClients client.ServiceAccountClientFactory
+	// namespaceLister reads the install ServiceAccounts namespaces require
+	namespaceLister corelisters.NamespaceLister
+	// upgradePolicyLister reads the UpgradePolicies in the operator namespace
+	upgradePolicyLister listers.UpgradePolicyLister
+	// auditor records the objects InstallPlans create; nil records nothing
+	auditor *audit.Auditor
+	// clock tells the time catalogs and subscriptions were last updated; nil uses timeNow
+	clock clock.Clock
 }
 
-// NewOperator creates a new Catalog Operator.
-func NewOperator(kubeconfigPath string, wakeupInterval time.Duration, operatorNamespace string, watchedNamespaces ...string) (*Operator, error) {
-	// Default to watching all namespaces.
-	if watchedNamespaces == nil {
-		watchedNamespaces = []string{metav1.NamespaceAll}
+// now returns the current time from the operator's clock, in UTC
+func (o *Operator) now() metav1.Time {
+	if o.clock == nil {
+		return timeNow()
 	}
+	return metav1.NewTime(o.clock.Now().UTC())
+}
 
+// NewOperator creates a new Catalog Operator.
+func NewOperator(kubeconfigPath string, wakeupInterval time.Duration, operatorNamespace string, auditor *audit.Auditor, queueConfig queueinformer.Config, watchedNamespaces ...string) (*Operator, error) {
 	// Create a new client for ALM types (CRs)
 	crClient, err := client.NewClient(kubeconfigPath)
 	if err != nil {
 		return nil, err
 	}
 
+	// Create a factory for clients that impersonate install ServiceAccounts
+	serviceAccountClients, err := client.NewServiceAccountClientFactory(kubeconfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewOperatorFromClients(operatorclient.NewClient(kubeconfigPath), crClient, serviceAccountClients, clock.RealClock{}, wakeupInterval, operatorNamespace, auditor, queueConfig, watchedNamespaces...)
+}
+
+// NewOperatorFromClients creates a Catalog Operator that manages the cluster the given clients talk to, and reads the
+// time from clk. A nil serviceAccountClients rejects InstallPlans that name a ServiceAccount.
+func NewOperatorFromClients(opClient operatorclient.ClientInterface, crClient versioned.Interface, serviceAccountClients client.ServiceAccountClientFactory, clk clock.Clock, wakeupInterval time.Duration, operatorNamespace string, auditor *audit.Auditor, queueConfig queueinformer.Config, watchedNamespaces ...string) (*Operator, error) {
+	// Default to watching all namespaces.
+	if watchedNamespaces == nil {
+		watchedNamespaces = []string{metav1.NamespaceAll}
+	}
+
 	// Create an informer for each watched namespace.
 	ipSharedIndexInformers := []cache.SharedIndexInformer{}
 	subSharedIndexInformers := []cache.SharedIndexInformer{}
+	csvSharedIndexInformers := []cache.SharedIndexInformer{}
+	ipListers := map[string]listers.InstallPlanLister{}
+	csvListers := map[string]listers.ClusterServiceVersionLister{}
 	for _, namespace := range watchedNamespaces {
 		nsInformerFactory := externalversions.NewSharedInformerFactoryWithOptions(crClient, wakeupInterval, externalversions.WithNamespace(namespace))
-		ipSharedIndexInformers = append(ipSharedIndexInformers, nsInformerFactory.Operators().V1alpha1().InstallPlans().Informer())
+		ipInformer := nsInformerFactory.Operators().V1alpha1().InstallPlans()
+		ipSharedIndexInformers = append(ipSharedIndexInformers, ipInformer.Informer())
+		ipListers[namespace] = ipInformer.Lister()
 		subSharedIndexInformers = append(subSharedIndexInformers, nsInformerFactory.Operators().V1alpha1().Subscriptions().Informer())
+		csvInformer := nsInformerFactory.Operators().V1alpha1().ClusterServiceVersions()
+		csvSharedIndexInformers = append(csvSharedIndexInformers, csvInformer.Informer())
+		csvListers[namespace] = csvInformer.Lister()
 	}
 
 	// Create an informer for each catalog namespace
 	catsrcSharedIndexInformers := []cache.SharedIndexInformer{}
-	for _, namespace := range []string{operatorNamespace} {
+	configMapInformers := map[string]coreinformers.ConfigMapInformer{}
+	for _, namespace := range catalogNamespaces(operatorNamespace, watchedNamespaces) {
 		nsInformerFactory := externalversions.NewSharedInformerFactoryWithOptions(crClient, wakeupInterval, externalversions.WithNamespace(namespace))
 		catsrcSharedIndexInformers = append(catsrcSharedIndexInformers, nsInformerFactory.Operators().V1alpha1().CatalogSources().Informer())
+		k8sInformerFactory := informers.NewSharedInformerFactoryWithOptions(opClient.KubernetesInterface(), wakeupInterval, informers.WithNamespace(namespace))
+		configMapInformers[namespace] = k8sInformerFactory.Core().V1().ConfigMaps()
 	}
 
 	// Create a new queueinformer-based operator.
-	queueOperator, err := queueinformer.NewOperator(kubeconfigPath)
+	queueOperator, err := queueinformer.NewOperatorFromClient(opClient, queueConfig)
 	if err != nil {
 		return nil, err
 	}
 
 	// Allocate the new instance of an Operator.
 	op := &Operator{
-		Operator:           queueOperator,
-		client:             crClient,
-		namespace:          operatorNamespace,
-		sources:            make(map[registry.SourceKey]registry.Source),
-		subscriptions:      make(map[registry.SubscriptionKey]v1alpha1.Subscription),
-		dependencyResolver: &resolver.MultiSourceResolver{},
+		Operator:              queueOperator,
+		client:                crClient,
+		namespace:             operatorNamespace,
+		sources:               make(map[registry.SourceKey]registry.Source),
+		sourcePriorities:      make(map[registry.SourceKey]int),
+		archives:              make(map[registry.SourceKey]*archiveSource),
+		registries:            make(map[registry.SourceKey]*remote.Client),
+		subscriptions:         make(map[registry.SubscriptionKey]v1alpha1.Subscription),
+		dependencyResolver:    &resolver.MultiSourceResolver{},
+		serviceAccountClients: serviceAccountClients,
+		auditor:               auditor,
+		clock:                 clk,
 	}
 
+	// Cache namespaces to read the install ServiceAccounts they name.
+	namespaceInformer := informers.NewSharedInformerFactory(opClient.KubernetesInterface(), wakeupInterval).Core().V1().Namespaces()
+	op.RegisterInformer(namespaceInformer.Informer())
+	op.namespaceLister = namespaceInformer.Lister()
+
+	// Cache the UpgradePolicies that hold InstallPlans.
+	upgradePolicyInformer := externalversions.NewSharedInformerFactoryWithOptions(crClient, wakeupInterval, externalversions.WithNamespace(operatorNamespace)).Operators().V1alpha1().UpgradePolicies()
+	op.RegisterInformer(upgradePolicyInformer.Informer())
+	op.upgradePolicyLister = upgradePolicyInformer.Lister()
+
 	// Register CatalogSource informers.
-	catsrcQueue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "catalogsources")
+	catsrcQueue := queueOperator.NewQueue("catalogsources")
 	catsrcQueueInformer := queueinformer.New(
 		catsrcQueue,
 		catsrcSharedIndexInformers,
 		op.syncCatalogSources,
+		op.deleteCatalogSource,
 		nil,
 	)
 	for _, informer := range catsrcQueueInformer {
 		op.RegisterQueueInformer(informer)
 	}
+	op.catsrcQueue = catsrcQueue
+	for _, informer := range catsrcSharedIndexInformers {
+		if err := informer.AddIndexers(cache.Indexers{configMapIndex: indexCatalogSourceByConfigMap}); err != nil {
+			return nil, err
+		}
+		op.catsrcIndexers = append(op.catsrcIndexers, informer.GetIndexer())
+	}
+
+	// Register ConfigMap informers, which catalogs are loaded from and which reload the CatalogSources loaded from
+	// changed ConfigMaps.
+	op.configMapListers = map[string]corelisters.ConfigMapLister{}
+	for namespace, informer := range configMapInformers {
+		informer.Informer().AddEventHandler(op.configMapEventHandler())
+		op.RegisterInformer(informer.Informer())
+		op.configMapListers[namespace] = informer.Lister()
+	}
 
 	// Register InstallPlan informers.
-	ipQueue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "installplans")
+	ipQueue := queueOperator.NewQueue("installplans")
 	ipQueueInformers := queueinformer.New(
 		ipQueue,
 		ipSharedIndexInformers,
 		op.syncInstallPlans,
 		nil,
+		nil,
 	)
 	for _, informer := range ipQueueInformers {
 		op.RegisterQueueInformer(informer)
 	}
+	op.ipQueue = ipQueue
+	op.ipListers = ipListers
 
 	// Register Subscription informers.
-	subscriptionQueue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "subscriptions")
+	subscriptionQueue := queueOperator.NewQueue("subscriptions")
 	subscriptionQueueInformers := queueinformer.New(
 		subscriptionQueue,
 		subSharedIndexInformers,
 		op.syncSubscriptions,
+		op.deleteSubscription,
 		nil,
 	)
 	for _, informer := range subscriptionQueueInformers {
 		op.RegisterQueueInformer(informer)
 	}
+	op.subQueue = subscriptionQueue
+	for _, informer := range subSharedIndexInformers {
+		if err := informer.AddIndexers(cache.Indexers{catalogSourceIndex: op.indexSubscriptionByCatalogSource, csvIndex: indexSubscriptionByCSV}); err != nil {
+			return nil, err
+		}
+		op.subIndexers = append(op.subIndexers, informer.GetIndexer())
+	}
+
+	// Sync Subscriptions when their InstallPlans or installed CSVs change, to keep their conditions current.
+	for _, informer := range ipSharedIndexInformers {
+		informer.AddEventHandler(subscriptionRequeueHandler(op.requeueSubscriptionForInstallPlan))
+	}
+	for _, informer := range csvSharedIndexInformers {
+		informer.AddEventHandler(subscriptionRequeueHandler(op.requeueSubscriptionsForCSV))
+		op.RegisterInformer(informer)
+	}
+	op.csvListers = csvListers
 
 	return op, nil
 }
@@ -137,15 +267,388 @@ func (o *Operator) syncCatalogSources(obj interface{}) (syncError error) {
 		return fmt.Errorf("casting CatalogSource failed")
 	}
 
-	src, err := registry.NewInMemoryFromConfigMap(o.OpClient, o.namespace, catsrc.Spec.ConfigMap)
+	out := catsrc.DeepCopy()
+	out.Status.LastAttempt = o.now()
+	var src registry.Source
+	var err error
+	switch {
+	case catsrc.Spec.SourceType == v1alpha1.SourceTypeGrpc:
+		clearConfigMapStatus(out)
+		src, err = o.connectRegistry(out)
+	case catsrc.Spec.SourceType == v1alpha1.SourceTypeHTTP:
+		clearConfigMapStatus(out)
+		src, err = o.pollArchive(out)
+	case catsrc.Spec.Verification == nil:
+		out.Status.Verification = nil
+		src, err = o.loadConfigMapCatalog(out, nil)
+	default:
+		src, err = o.loadVerifiedCatalog(out)
+	}
+	recordCatalogHealth(out, src, err)
+
+	if _, updateErr := statusutil.UpdateCatalogSourceStatus(o.client, catsrc, out); updateErr != nil {
+		log.Infof("error updating CatalogSource %s status: %s", out.GetName(), updateErr)
+		if err == nil {
+			err = updateErr
+		}
+	}
 	if err != nil {
+		switch catsrc.Spec.SourceType {
+		case v1alpha1.SourceTypeGrpc:
+			return fmt.Errorf("failed to create catalog source from registry server %s: %s", catsrc.Spec.Address, err)
+		case v1alpha1.SourceTypeHTTP:
+			return fmt.Errorf("failed to create catalog source from catalog archive %s: %s", catsrc.Spec.URL, err)
+		}
+		if set, _ := registry.ConfigMapSetForCatalogSource(catsrc.Spec); len(set.Names) != 1 || set.Selector != nil {
+			return fmt.Errorf("failed to create catalog source from ConfigMaps %s: %s", set, err)
+		}
 		return fmt.Errorf("failed to create catalog source from ConfigMap %s: %s", catsrc.Spec.ConfigMap, err)
 	}
 
 	o.sourcesLock.Lock()
 	defer o.sourcesLock.Unlock()
-	o.sources[registry.SourceKey{Name: catsrc.GetName(), Namespace: catsrc.GetNamespace()}] = src
-	o.sourcesLastUpdate = timeNow()
+	key := registry.SourceKey{Name: catsrc.GetName(), Namespace: catsrc.GetNamespace()}
+	if current, ok := o.sources[key]; !ok || current != src || o.sourcePriorities[key] != catsrc.Spec.Priority {
+		o.sources[key] = src
+		if catsrc.Spec.Priority == 0 {
+			delete(o.sourcePriorities, key)
+		} else {
+			o.sourcePriorities[key] = catsrc.Spec.Priority
+		}
+		o.sourcesLastUpdate = o.now()
+		o.requeueSubscriptionsForCatalogSource(key)
+	}
+	return nil
+}
+
+// recordCatalogHealth records the outcome of loading the catalog of a CatalogSource in its status
+func recordCatalogHealth(catsrc *v1alpha1.CatalogSource, src registry.Source, err error) {
+	if err != nil {
+		reason := v1alpha1.CatalogSourceReasonLoadFailed
+		if registry.IsCatalogVerificationError(err) {
+			reason = v1alpha1.CatalogSourceReasonVerificationFailed
+		}
+		catsrc.Status.SetCondition(v1alpha1.CatalogSourceCondition{
+			Type:    v1alpha1.CatalogSourceHealthy,
+			Status:  v1.ConditionFalse,
+			Reason:  reason,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	catsrc.Status.LastSync = catsrc.Status.LastAttempt
+	catsrc.Status.SetCondition(v1alpha1.CatalogSourceCondition{
+		Type:   v1alpha1.CatalogSourceHealthy,
+		Status: v1.ConditionTrue,
+		Reason: v1alpha1.CatalogSourceReasonLoaded,
+	})
+	catsrc.Status.Contents = nil
+	if catalog, ok := src.(*registry.InMem); ok {
+		contents := catalog.Contents()
+		catsrc.Status.Contents = &contents
+	}
+}
+
+// clearConfigMapStatus forgets the ConfigMaps a CatalogSource was loaded from, once it isn't loaded from ConfigMaps
+func clearConfigMapStatus(catsrc *v1alpha1.CatalogSource) {
+	catsrc.Status.ConfigMapResource = nil
+	catsrc.Status.ConfigMapResources = nil
+	catsrc.Status.Hash = ""
+}
+
+// loadConfigMapCatalog loads the catalog of a CatalogSource from its ConfigMaps, recording them and the hash of their
+// content in its status. Unless the catalog must be verified, the catalog in service is kept when the content hash
+// hasn't changed since it was loaded.
+func (o *Operator) loadConfigMapCatalog(catsrc *v1alpha1.CatalogSource, verifier *registry.CatalogVerifier) (*registry.InMem, error) {
+	set, err := registry.ConfigMapSetForCatalogSource(catsrc.Spec)
+	if err != nil {
+		return nil, err
+	}
+	loader := registry.NewConfigMapCatalogResourceLoader(catsrc.GetNamespace(), o.OpClient)
+	if verifier != nil {
+		loader = registry.NewVerifyingConfigMapCatalogResourceLoader(catsrc.GetNamespace(), o.OpClient, verifier)
+	}
+	if lister := o.configMapLister(catsrc.GetNamespace()); lister != nil {
+		loader = loader.WithLister(lister)
+	}
+	cms, err := loader.GetConfigMaps(set)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := registry.PackageFilterForCatalogSource(catsrc.Spec)
+	refs, hash := registry.ReferenceConfigMaps(cms)
+	if verifier == nil && hash == catsrc.Status.Hash {
+		key := registry.SourceKey{Name: catsrc.GetName(), Namespace: catsrc.GetNamespace()}
+		o.sourcesLock.RLock()
+		current, ok := o.sources[key].(*registry.InMem)
+		o.sourcesLock.RUnlock()
+		if ok && current.FilteredBy(filter) {
+			log.Debugf("catalog ConfigMaps %s of CatalogSource %s unchanged", set, catsrc.GetName())
+			return current, nil
+		}
+	}
+
+	catalog := registry.NewInMem()
+	if err := loader.LoadCatalogResourcesFromConfigMaps(catalog, cms); err != nil {
+		return nil, err
+	}
+	catalog, filtered, err := catalog.Filter(filter)
+	if err != nil {
+		return nil, err
+	}
+	catsrc.Status.Filtered = filtered
+	catsrc.Status.ConfigMapResources = refs
+	catsrc.Status.ConfigMapResource = nil
+	for i := range refs {
+		if refs[i].Name == catsrc.Spec.ConfigMap {
+			ref := refs[i]
+			catsrc.Status.ConfigMapResource = &ref
+		}
+	}
+	catsrc.Status.Hash = hash
+	return catalog, nil
+}
+
+// configMapLister returns a lister of the ConfigMaps in a catalog namespace, or nil if they aren't cached
+func (o *Operator) configMapLister(namespace string) corelisters.ConfigMapNamespaceLister {
+	if lister, ok := o.configMapListers[namespace]; ok {
+		return lister.ConfigMaps(namespace)
+	}
+	if lister, ok := o.configMapListers[metav1.NamespaceAll]; ok {
+		return lister.ConfigMaps(namespace)
+	}
+	return nil
+}
+
+// installPlanLister returns a lister of the InstallPlans in a watched namespace, or nil if they aren't cached
+func (o *Operator) installPlanLister(namespace string) listers.InstallPlanNamespaceLister {
+	if lister, ok := o.ipListers[namespace]; ok {
+		return lister.InstallPlans(namespace)
+	}
+	if lister, ok := o.ipListers[metav1.NamespaceAll]; ok {
+		return lister.InstallPlans(namespace)
+	}
+	return nil
+}
+
+// csvLister returns a lister of the CSVs in a watched namespace, or nil if they aren't cached
+func (o *Operator) csvLister(namespace string) listers.ClusterServiceVersionNamespaceLister {
+	if lister, ok := o.csvListers[namespace]; ok {
+		return lister.ClusterServiceVersions(namespace)
+	}
+	if lister, ok := o.csvListers[metav1.NamespaceAll]; ok {
+		return lister.ClusterServiceVersions(namespace)
+	}
+	return nil
+}
+
+// connectRegistry connects to the registry server of a grpc CatalogSource
+func (o *Operator) connectRegistry(catsrc *v1alpha1.CatalogSource) (registry.Source, error) {
+	if catsrc.Spec.Verification != nil {
+		return nil, fmt.Errorf("verification is only supported for catalogs loaded from a ConfigMap")
+	}
+	if !registry.PackageFilterForCatalogSource(catsrc.Spec).IsZero() {
+		return nil, fmt.Errorf("package filters are only supported for catalogs loaded into memory")
+	}
+	if catsrc.Spec.Address == "" {
+		return nil, fmt.Errorf("no address set")
+	}
+	catsrc.Status.Verification = nil
+	catsrc.Status.Filtered = nil
+
+	key := registry.SourceKey{Name: catsrc.GetName(), Namespace: catsrc.GetNamespace()}
+	o.registriesLock.Lock()
+	src, ok := o.registries[key]
+	if !ok || src.Address() != catsrc.Spec.Address {
+		src = remote.NewClient(catsrc.Spec.Address, remote.ClientConfig{Clock: o.clock})
+		o.registries[key] = src
+	}
+	o.registriesLock.Unlock()
+
+	if err := src.Healthy(); err != nil {
+		return nil, err
+	}
+	// the packages are listed once the client is in service, so they can be served while the server is unreachable
+	if _, err := src.ListPackages(); err != nil {
+		return nil, err
+	}
+	return src, nil
+}
+
+// archiveSource is the catalog archive of an http CatalogSource, and the catalog last loaded from it
+type archiveSource struct {
+	// lock serializes polls of the archive, so a slow fetch only holds up its own CatalogSource
+	lock    sync.Mutex
+	fetcher *registry.ArchiveFetcher
+	catalog *registry.InMem
+}
+
+// pollArchive checks the catalog archive of an http CatalogSource for updates once every poll interval, recording
+// the outcome in its status. The catalog in service stays in service until a newer archive loads.
+func (o *Operator) pollArchive(catsrc *v1alpha1.CatalogSource) (registry.Source, error) {
+	if catsrc.Spec.Verification != nil {
+		return nil, fmt.Errorf("verification is only supported for catalogs loaded from a ConfigMap")
+	}
+	if catsrc.Spec.URL == "" {
+		return nil, fmt.Errorf("no url set")
+	}
+	catsrc.Status.Verification = nil
+	interval := defaultArchivePollInterval
+	if catsrc.Spec.PollInterval != nil && catsrc.Spec.PollInterval.Duration > 0 {
+		interval = catsrc.Spec.PollInterval.Duration
+	}
+
+	key := registry.SourceKey{Name: catsrc.GetName(), Namespace: catsrc.GetNamespace()}
+	o.sourcesLock.RLock()
+	current := o.sources[key]
+	o.sourcesLock.RUnlock()
+
+	o.archivesLock.Lock()
+	archive, ok := o.archives[key]
+	if !ok {
+		archive = &archiveSource{}
+		o.archives[key] = archive
+	}
+	o.archivesLock.Unlock()
+
+	archive.lock.Lock()
+	defer archive.lock.Unlock()
+	filter := registry.PackageFilterForCatalogSource(catsrc.Spec)
+	inService := archive.catalog != nil && current == registry.Source(archive.catalog)
+	if !inService || archive.fetcher.URL != catsrc.Spec.URL || archive.fetcher.Checksum != catsrc.Spec.Checksum || !archive.catalog.FilteredBy(filter) {
+		// start over, so the archive is loaded even if it hasn't changed since it was last fetched
+		archive.fetcher = registry.NewArchiveFetcher(catsrc.Spec.URL, catsrc.Spec.Checksum)
+		archive.catalog = nil
+		inService = false
+	}
+
+	now := o.now()
+	if last := catsrc.Status.Archive; inService && last != nil && last.LastPoll != nil && now.Sub(last.LastPoll.Time) < interval {
+		o.requeueCatalogSourceAfter(catsrc, interval-now.Sub(last.LastPoll.Time))
+		return archive.catalog, nil
+	}
+	defer o.requeueCatalogSourceAfter(catsrc, interval)
+
+	catalog, revision, err := archive.fetcher.Fetch()
+	catsrc.Status.Archive = &v1alpha1.CatalogArchiveStatus{Revision: revision, LastPoll: &now}
+	if err != nil {
+		catsrc.Status.Archive.Message = err.Error()
+		return nil, err
+	}
+	if catalog != nil {
+		filtered, status, err := catalog.Filter(filter)
+		if err != nil {
+			catsrc.Status.Archive.Message = err.Error()
+			return nil, err
+		}
+		archive.catalog = filtered
+		catsrc.Status.Filtered = status
+	}
+	return archive.catalog, nil
+}
+
+func (o *Operator) requeueCatalogSourceAfter(catsrc *v1alpha1.CatalogSource, delay time.Duration) {
+	k, err := cache.DeletionHandlingMetaNamespaceKeyFunc(catsrc)
+	if err != nil {
+		log.Infof("creating key failed: %s", err)
+		return
+	}
+	o.catsrcQueue.AddAfter(k, delay)
+}
+
+// loadVerifiedCatalog loads the catalog of a CatalogSource that requires signed content, recording the outcome in its
+// status. If the content fails verification, the last verified catalog stays in service.
+func (o *Operator) loadVerifiedCatalog(catsrc *v1alpha1.CatalogSource) (*registry.InMem, error) {
+	previous := catsrc.Status.Verification
+	if previous == nil {
+		previous = &v1alpha1.CatalogVerificationStatus{}
+	}
+
+	set, err := registry.ConfigMapSetForCatalogSource(catsrc.Spec)
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := registry.NewCatalogVerifier(catsrc.Spec.Verification.PublicKeys)
+	if err != nil {
+		err = registry.CatalogVerificationError{ConfigMap: set.String(), Message: fmt.Sprintf("invalid trusted keys: %s", err)}
+	} else {
+		var src *registry.InMem
+		src, err = o.loadConfigMapCatalog(catsrc, verifier)
+		if err == nil {
+			catsrc.Status.Verification = &v1alpha1.CatalogVerificationStatus{Verified: true, LastFailure: previous.LastFailure}
+			return src, nil
+		}
+	}
+	if !registry.IsCatalogVerificationError(err) {
+		return nil, err
+	}
+
+	// keep the last verified catalog in service, but never leave content that wasn't verified in service
+	o.sourcesLock.Lock()
+	defer o.sourcesLock.Unlock()
+	key := registry.SourceKey{Name: catsrc.GetName(), Namespace: catsrc.GetNamespace()}
+	_, inService := o.sources[key]
+	if inService && !previous.Verified {
+		delete(o.sources, key)
+		o.sourcesLastUpdate = o.now()
+	}
+
+	// only bump the failure time for new failures, so repeated syncs don't keep rewriting the status
+	lastFailure := previous.LastFailure
+	if lastFailure == nil || previous.Message != err.Error() {
+		now := o.now()
+		lastFailure = &now
+	}
+	catsrc.Status.Verification = &v1alpha1.CatalogVerificationStatus{
+		Verified:    inService && previous.Verified,
+		Message:     err.Error(),
+		LastFailure: lastFailure,
+	}
+	return nil, err
+}
+
+// deleteCatalogSource stops resolving against a deleted CatalogSource
+func (o *Operator) deleteCatalogSource(obj interface{}) error {
+	catsrc, ok := obj.(*v1alpha1.CatalogSource)
+	if !ok {
+		log.Debugf("wrong type: %#v", obj)
+		return fmt.Errorf("casting CatalogSource failed")
+	}
+
+	key := registry.SourceKey{Name: catsrc.GetName(), Namespace: catsrc.GetNamespace()}
+	o.archivesLock.Lock()
+	delete(o.archives, key)
+	o.archivesLock.Unlock()
+	o.registriesLock.Lock()
+	delete(o.registries, key)
+	o.registriesLock.Unlock()
+
+	o.sourcesLock.Lock()
+	defer o.sourcesLock.Unlock()
+	if _, ok := o.sources[key]; ok {
+		log.Infof("removing deleted CatalogSource %s/%s", catsrc.GetNamespace(), catsrc.GetName())
+		delete(o.sources, key)
+		delete(o.sourcePriorities, key)
+		// subscriptions are rechecked when sources change
+		o.sourcesLastUpdate = o.now()
+		o.requeueSubscriptionsForCatalogSource(key)
+	}
+	return nil
+}
+
+// deleteSubscription forgets a deleted Subscription. Its InstallPlans are owned by it and garbage collected.
+func (o *Operator) deleteSubscription(obj interface{}) error {
+	sub, ok := obj.(*v1alpha1.Subscription)
+	if !ok {
+		log.Debugf("wrong type: %#v", obj)
+		return fmt.Errorf("casting Subscription failed")
+	}
+
+	o.subscriptionsLock.Lock()
+	defer o.subscriptionsLock.Unlock()
+	delete(o.subscriptions, registry.SubscriptionKey{Name: sub.GetName(), Namespace: sub.GetNamespace()})
 	return nil
 }
 
@@ -166,8 +669,21 @@ func (o *Operator) syncSubscriptions(obj interface{}) (syncError error) {
 
 	logger.Infof("syncing")
 
+	if sub.GetDeletionTimestamp() != nil {
+		return o.uninstallSubscription(sub)
+	}
+	if updated, err := o.syncUninstallFinalizer(sub); err != nil || updated {
+		return err
+	}
+
 	var updatedSub *v1alpha1.Subscription
-	updatedSub, syncError = o.syncSubscription(sub)
+	// syncSubscription modifies the subscription, which is shared with the informer's cache
+	in := sub.DeepCopy()
+	updatedSub, syncError = o.syncSubscription(in)
+	if updatedSub == nil && syncError == nil {
+		// nothing changed in the catalogs since the last sync, but the installed CSV may have
+		updatedSub = in
+	}
 
 	if updatedSub == nil {
 		return
@@ -175,10 +691,15 @@ func (o *Operator) syncSubscriptions(obj interface{}) (syncError error) {
 	if syncError != nil {
 		logger = logger.WithField("syncError", syncError)
 	}
+	o.setSubscriptionConditions(updatedSub, syncError)
 
-	updatedSub.Status.LastUpdated = timeNow()
+	updatedSub.Status.LastUpdated = o.now()
+	// the status is written after catalogs change even if it's the same, so the next sync knows they've been seen
+	o.sourcesLock.RLock()
+	sourcesLastUpdate := o.sourcesLastUpdate
+	o.sourcesLock.RUnlock()
 	// Update Subscription with status of transition. Log errors if we can't write them to the status.
-	if updatedSubFromApi, err := o.client.OperatorsV1alpha1().Subscriptions(updatedSub.GetNamespace()).UpdateStatus(updatedSub); err != nil {
+	if updatedSubFromApi, err := statusutil.UpdateSubscriptionStatus(o.client, sub, updatedSub, sourcesLastUpdate); err != nil {
 		logger = logger.WithField("updateError", err.Error())
 		updateErr := errors.New("error updating Subscription status: " + err.Error())
 		if syncError == nil {
@@ -216,13 +737,8 @@ func (o *Operator) syncInstallPlans(obj interface{}) (syncError error) {
 		logger = logger.WithField("syncError", syncError)
 	}
 
-	// no changes in status, don't update
-	if outInstallPlan.Status.Phase == plan.Status.Phase {
-		return
-	}
-
-	// Update InstallPlan with status of transition. Log errors if we can't write them to the status.
-	if _, err := o.client.OperatorsV1alpha1().InstallPlans(plan.GetNamespace()).UpdateStatus(outInstallPlan); err != nil {
+	// Update InstallPlan with status of transition, if it changed. Log errors if we can't write them to the status.
+	if _, err := statusutil.UpdateInstallPlanStatus(o.client, plan, outInstallPlan); err != nil {
 		logger = logger.WithField("updateError", err.Error())
 		updateErr := errors.New("error updating InstallPlan status: " + err.Error())
 		if syncError == nil {
@@ -232,11 +748,19 @@ func (o *Operator) syncInstallPlans(obj interface{}) (syncError error) {
 		logger.Info("error transitioning InstallPlan")
 		syncError = fmt.Errorf("error transitioning InstallPlan: %s and error updating InstallPlan status: %s", syncError, updateErr)
 	}
+	switch {
+	case outInstallPlan.Status.Phase == v1alpha1.InstallPlanPhaseWaiting:
+		o.requeueHeldInstallPlan(outInstallPlan)
+	case plan.Status.Phase == v1alpha1.InstallPlanPhaseInstalling && outInstallPlan.Status.Phase == v1alpha1.InstallPlanPhaseInstalling:
+		// still waiting for CRDs to be established
+		o.requeueInstallPlan(outInstallPlan, crdEstablishedRecheckInterval)
+	}
 	return
 }
 
 type installPlanTransitioner interface {
 	ResolvePlan(*v1alpha1.InstallPlan) error
+	HoldPlan(*v1alpha1.InstallPlan) *v1alpha1.InstallPlanHold
 	ExecutePlan(*v1alpha1.InstallPlan) error
 }
 
@@ -270,27 +794,46 @@ func transitionInstallPlanState(transitioner installPlanTransitioner, in v1alpha
 		if out.Spec.Approval == v1alpha1.ApprovalManual && out.Spec.Approved != true {
 			out.Status.Phase = v1alpha1.InstallPlanPhaseRequiresApproval
 		} else {
-			out.Status.Phase = v1alpha1.InstallPlanPhaseInstalling
+			startInstalling(transitioner, out, logger)
 		}
 		return out, nil
 
 	case v1alpha1.InstallPlanPhaseRequiresApproval:
 		if out.Spec.Approved {
-			logger.Debugf("approved, setting to %s", v1alpha1.InstallPlanPhasePlanning)
-			out.Status.Phase = v1alpha1.InstallPlanPhaseInstalling
+			logger.Debugf("approved, setting to %s", v1alpha1.InstallPlanPhaseInstalling)
+			startInstalling(transitioner, out, logger)
 		} else {
 			logger.Debug("not approved, skipping sync")
 		}
 		return out, nil
 
+	case v1alpha1.InstallPlanPhaseWaiting:
+		if hold := transitioner.HoldPlan(out); hold != nil {
+			logger.Debugf("held: %s", hold.Message)
+			out.Status.Hold = hold
+			return out, nil
+		}
+		logger.Debugf("no longer held, setting to %s", v1alpha1.InstallPlanPhaseInstalling)
+		out.Status.Hold = nil
+		out.Status.Phase = v1alpha1.InstallPlanPhaseInstalling
+		return out, nil
+
 	case v1alpha1.InstallPlanPhaseInstalling:
 		logger.Debug("attempting to install")
 		if err := transitioner.ExecutePlan(out); err != nil {
+			reason := v1alpha1.InstallPlanReasonComponentFailed
+			if k8serrors.IsForbidden(err) {
+				reason = v1alpha1.InstallPlanReasonInsufficientPermissions
+			}
 			out.Status.SetCondition(v1alpha1.ConditionFailed(v1alpha1.InstallPlanInstalled,
-				v1alpha1.InstallPlanReasonComponentFailed, err))
+				reason, err))
 			out.Status.Phase = v1alpha1.InstallPlanPhaseFailed
 			return out, err
 		}
+		if !planStepsDone(out) {
+			logger.Debug("waiting for CRDs to be established")
+			return out, nil
+		}
 		out.Status.SetCondition(v1alpha1.ConditionMet(v1alpha1.InstallPlanInstalled))
 		out.Status.Phase = v1alpha1.InstallPlanPhaseComplete
 		return out, nil
@@ -309,6 +852,13 @@ func (o *Operator) ResolvePlan(plan *v1alpha1.InstallPlan) error {
 		return fmt.Errorf("cannot resolve InstallPlan without any Catalog Sources")
 	}
 
+	if plan.Spec.CatalogSourceNamespace != "" {
+		key := registry.SourceKey{Name: plan.Spec.CatalogSource, Namespace: plan.Spec.CatalogSourceNamespace}
+		if !o.sourceVisibleTo(key, plan.Namespace) {
+			return fmt.Errorf("catalog source %s in namespace %s is not visible to namespace %s", key.Name, key.Namespace, plan.Namespace)
+		}
+	}
+
 	// Copy the sources for resolution from the included namespaces
 	includedNamespaces := map[string]struct{}{
 		o.namespace:    {},
@@ -322,12 +872,21 @@ func (o *Operator) ResolvePlan(plan *v1alpha1.InstallPlan) error {
 		return notFoundErr
 	}
 
-	// Set the resolved steps
-	plan.Status.Plan = steps
+	// Secrets come first, for each used catalog source by namespace and name
+	sort.Slice(usedSources, func(i, j int) bool {
+		if usedSources[i].Namespace != usedSources[j].Namespace {
+			return usedSources[i].Namespace < usedSources[j].Namespace
+		}
+		return usedSources[i].Name < usedSources[j].Name
+	})
+	secretSteps := []v1alpha1.Step{}
 	plan.Status.CatalogSources = []string{}
 
 	// Add secrets for each used catalog source
-	for _, sourceKey := range usedSources {
+	for i, sourceKey := range usedSources {
+		if i > 0 && sourceKey == usedSources[i-1] {
+			continue
+		}
 		// Append the used catalog source
 		plan.Status.CatalogSources = append(plan.Status.CatalogSources, sourceKey.Name)
 
@@ -349,20 +908,26 @@ func (o *Operator) ResolvePlan(plan *v1alpha1.InstallPlan) error {
 				return err
 			}
 
-			// Prepend any required secrets to the plan for that catalog source
-			plan.Status.Plan = append([]v1alpha1.Step{{
+			// Add any required secrets to the plan for that catalog source
+			secretSteps = append(secretSteps, v1alpha1.Step{
 				Resolving: "",
 				Resource: v1alpha1.StepResource{
-					Name:    secretName,
-					Kind:    "Secret",
-					Group:   "",
-					Version: "v1",
+					CatalogSource:          sourceKey.Name,
+					CatalogSourceNamespace: sourceKey.Namespace,
+					SourceReason:           "required by the catalog source",
+					Name:                   secretName,
+					Kind:                   "Secret",
+					Group:                  "",
+					Version:                "v1",
 				},
 				Status: status,
-			}}, plan.Status.Plan...)
+			})
 		}
 	}
 
+	// Set the resolved steps, which are ordered by the resolver
+	plan.Status.Plan = append(secretSteps, steps...)
+
 	return nil
 }
 
@@ -372,6 +937,15 @@ func (o *Operator) ExecutePlan(plan *v1alpha1.InstallPlan) error {
 		panic("attempted to install a plan that wasn't in the installing phase")
 	}
 
+	serviceAccount, err := o.installServiceAccount(plan)
+	if err != nil {
+		return err
+	}
+	opClient, crClient, err := o.installClients(plan.GetNamespace(), serviceAccount)
+	if err != nil {
+		return err
+	}
+
 	for i, step := range plan.Status.Plan {
 		switch step.Status {
 		case v1alpha1.StepStatusPresent, v1alpha1.StepStatusCreated:
@@ -391,7 +965,7 @@ func (o *Operator) ExecutePlan(plan *v1alpha1.InstallPlan) error {
 
 				// TODO: check that names are accepted
 				// Attempt to create the CRD.
-				_, err = o.OpClient.ApiextensionsV1beta1Interface().ApiextensionsV1beta1().CustomResourceDefinitions().Create(&crd)
+				created, err := opClient.ApiextensionsV1beta1Interface().ApiextensionsV1beta1().CustomResourceDefinitions().Create(&crd)
 				if k8serrors.IsAlreadyExists(err) {
 					// If it already existed, mark the step as Present.
 					plan.Status.Plan[i].Status = v1alpha1.StepStatusPresent
@@ -401,6 +975,7 @@ func (o *Operator) ExecutePlan(plan *v1alpha1.InstallPlan) error {
 				} else {
 					// If no error occured, mark the step as Created.
 					plan.Status.Plan[i].Status = v1alpha1.StepStatusCreated
+					o.auditPlanStep(plan, serviceAccount, audit.Reference("apiextensions.k8s.io/v1beta1", crdKind, created), "")
 					continue
 				}
 
@@ -412,8 +987,26 @@ func (o *Operator) ExecutePlan(plan *v1alpha1.InstallPlan) error {
 					return err
 				}
 
+				// The CSV is only created once the CRDs it needs are established; the plan is executed again until then.
+				if waiting, err := o.unestablishedCRD(&csv); err != nil {
+					return err
+				} else if waiting != "" {
+					log.Infof("waiting for CRD %s to be established before creating CSV %s", waiting, csv.GetName())
+					return nil
+				}
+
+				// The CSV's install strategy runs with the same ServiceAccount as the plan.
+				if serviceAccount != "" {
+					annotations := csv.GetAnnotations()
+					if annotations == nil {
+						annotations = map[string]string{}
+					}
+					annotations[client.InstallServiceAccountAnnotationKey] = serviceAccount
+					csv.SetAnnotations(annotations)
+				}
+
 				// Attempt to create the CSV.
-				_, err = o.client.OperatorsV1alpha1().ClusterServiceVersions(csv.GetNamespace()).Create(&csv)
+				created, err := crClient.OperatorsV1alpha1().ClusterServiceVersions(csv.GetNamespace()).Create(&csv)
 				if k8serrors.IsAlreadyExists(err) {
 					// If it already existed, mark the step as Present.
 					plan.Status.Plan[i].Status = v1alpha1.StepStatusPresent
@@ -422,11 +1015,16 @@ func (o *Operator) ExecutePlan(plan *v1alpha1.InstallPlan) error {
 				} else {
 					// If no error occurred, mark the step as Created.
 					plan.Status.Plan[i].Status = v1alpha1.StepStatusCreated
+					o.auditPlanStep(plan, serviceAccount, audit.Reference(v1alpha1.ClusterServiceVersionAPIVersion, v1alpha1.ClusterServiceVersionKind, created), string(created.GetUID()))
 				}
 
 			case secretKind:
-				// Get the pre-existing secret.
-				secret, err := o.OpClient.KubernetesInterface().CoreV1().Secrets(o.namespace).Get(step.Resource.Name, metav1.GetOptions{})
+				// Get the pre-existing secret from the namespace of the catalog source that requires it.
+				secretNamespace, err := o.catalogSecretNamespace(step.Resource, plan.Namespace)
+				if err != nil {
+					return err
+				}
+				secret, err := o.OpClient.KubernetesInterface().CoreV1().Secrets(secretNamespace).Get(step.Resource.Name, metav1.GetOptions{})
 				if k8serrors.IsNotFound(err) {
 					return fmt.Errorf("secret %s does not exist", step.Resource.Name)
 				} else if err != nil {
@@ -436,7 +1034,7 @@ func (o *Operator) ExecutePlan(plan *v1alpha1.InstallPlan) error {
 				// Set the namespace to the InstallPlan's namespace and attempt to
 				// create a new secret.
 				secret.Namespace = plan.Namespace
-				_, err = o.OpClient.KubernetesInterface().CoreV1().Secrets(plan.Namespace).Create(&v1.Secret{
+				created, err := opClient.KubernetesInterface().CoreV1().Secrets(plan.Namespace).Create(&v1.Secret{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      secret.Name,
 						Namespace: plan.Namespace,
@@ -452,6 +1050,7 @@ func (o *Operator) ExecutePlan(plan *v1alpha1.InstallPlan) error {
 				} else {
 					// If no error occured, mark the step as Created.
 					plan.Status.Plan[i].Status = v1alpha1.StepStatusCreated
+					o.auditPlanStep(plan, serviceAccount, audit.Reference("v1", secretKind, created), "")
 				}
 
 			default:
@@ -475,6 +1074,143 @@ func (o *Operator) ExecutePlan(plan *v1alpha1.InstallPlan) error {
 	return nil
 }
 
+// unestablishedCRD returns the name of the first CRD owned or required by a CSV that exists but isn't established yet,
+// or "" if there's none. A CRD whose names weren't accepted never will be, which is an error.
+func (o *Operator) unestablishedCRD(csv *v1alpha1.ClusterServiceVersion) (string, error) {
+	crds := o.OpClient.ApiextensionsV1beta1Interface().ApiextensionsV1beta1().CustomResourceDefinitions()
+	for _, desc := range csv.GetAllCRDDescriptions() {
+		crd, err := crds.Get(desc.Name, metav1.GetOptions{})
+		if k8serrors.IsNotFound(err) {
+			// a missing required CRD is reported by the CSV's requirements
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		established := false
+		for _, cond := range crd.Status.Conditions {
+			switch {
+			case cond.Type == v1beta1ext.NamesAccepted && cond.Status == v1beta1ext.ConditionFalse:
+				return "", fmt.Errorf("names of CRD %s were not accepted: %s", crd.GetName(), cond.Message)
+			case cond.Type == v1beta1ext.Established && cond.Status == v1beta1ext.ConditionTrue:
+				established = true
+			}
+		}
+		if !established {
+			return crd.GetName(), nil
+		}
+	}
+	return "", nil
+}
+
+// startInstalling moves an approved InstallPlan to installing, or to waiting while it's held. Holds are only checked
+// before the plan's first step is executed, so that a freeze or a closing maintenance window never leaves a plan
+// half-applied.
+func startInstalling(transitioner installPlanTransitioner, out *v1alpha1.InstallPlan, logger *log.Entry) {
+	if hold := transitioner.HoldPlan(out); hold != nil {
+		logger.Debugf("held, setting to %s: %s", v1alpha1.InstallPlanPhaseWaiting, hold.Message)
+		out.Status.Hold = hold
+		out.Status.Phase = v1alpha1.InstallPlanPhaseWaiting
+		return
+	}
+	out.Status.Phase = v1alpha1.InstallPlanPhaseInstalling
+}
+
+// planStepsDone returns true if every step of a plan was created or found present
+func planStepsDone(plan *v1alpha1.InstallPlan) bool {
+	for _, step := range plan.Status.Plan {
+		switch step.Status {
+		case v1alpha1.StepStatusCreated, v1alpha1.StepStatusPresent:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// requeueInstallPlan syncs an InstallPlan again after a delay
+func (o *Operator) requeueInstallPlan(plan *v1alpha1.InstallPlan, delay time.Duration) {
+	if o.ipQueue == nil {
+		return
+	}
+	k, err := cache.MetaNamespaceKeyFunc(plan)
+	if err != nil {
+		log.Infof("creating key failed: %s", err)
+		return
+	}
+	o.ipQueue.AddAfter(k, delay)
+}
+
+// auditPlanStep records an object created by an InstallPlan, as the ServiceAccount it was installed with if any
+func (o *Operator) auditPlanStep(plan *v1alpha1.InstallPlan, serviceAccount string, object v1.ObjectReference, csvUID string) {
+	record := audit.Record{
+		Action: audit.ActionCreate,
+		Object: object,
+		CorrelationIDs: audit.Correlation{
+			InstallPlan:           string(plan.GetUID()),
+			ClusterServiceVersion: csvUID,
+		},
+	}
+	for _, ref := range plan.GetOwnerReferences() {
+		if ref.Kind == v1alpha1.SubscriptionKind {
+			record.CorrelationIDs.Subscription = string(ref.UID)
+		}
+	}
+	if serviceAccount != "" {
+		record.Actor = client.ServiceAccountUsername(plan.GetNamespace(), serviceAccount)
+	}
+	o.auditor.Record(record)
+}
+
+// installServiceAccount returns the ServiceAccount to execute an InstallPlan with: the one named by its namespace's
+// annotation, or else in its spec. It returns the empty string if neither names one.
+func (o *Operator) installServiceAccount(plan *v1alpha1.InstallPlan) (string, error) {
+	if o.serviceAccountClients == nil {
+		return plan.Spec.ServiceAccountName, nil
+	}
+	return client.InstallServiceAccount(o.namespaceLister, plan.GetNamespace(), plan.Spec.ServiceAccountName)
+}
+
+// catalogSecretNamespace returns the namespace to copy a Secret step's Secret from, once it has checked that the
+// step's CatalogSource is visible to the plan's namespace and requires the Secret. A step's resource is part of the
+// InstallPlan's status, so it's checked before OLM reads a Secret from another namespace on the plan's behalf.
+func (o *Operator) catalogSecretNamespace(resource v1alpha1.StepResource, planNamespace string) (string, error) {
+	key := registry.SourceKey{Name: resource.CatalogSource, Namespace: resource.CatalogSourceNamespace}
+	if key.Namespace == "" {
+		key.Namespace = o.namespace
+	}
+	if !o.sourceVisibleTo(key, planNamespace) {
+		return "", fmt.Errorf("secret %s: catalog source %s in namespace %s is not visible to namespace %s", resource.Name, key.Name, key.Namespace, planNamespace)
+	}
+	catsrc, err := o.client.OperatorsV1alpha1().CatalogSources(key.Namespace).Get(key.Name, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("secret %s: error getting catalog source %s in namespace %s: %s", resource.Name, key.Name, key.Namespace, err)
+	}
+	for _, name := range catsrc.Spec.Secrets {
+		if name == resource.Name {
+			return key.Namespace, nil
+		}
+	}
+	return "", fmt.Errorf("secret %s is not required by catalog source %s in namespace %s", resource.Name, key.Name, key.Namespace)
+}
+
+// installClients returns clients that act as the given ServiceAccount, or OLM's own clients if none is given.
+func (o *Operator) installClients(namespace, serviceAccount string) (operatorclient.ClientInterface, versioned.Interface, error) {
+	if serviceAccount == "" {
+		return o.OpClient, o.client, nil
+	}
+	if o.serviceAccountClients == nil {
+		return nil, nil, fmt.Errorf("installing with service account %s is not supported", serviceAccount)
+	}
+	opClient, crClient, err := o.serviceAccountClients.ClientsForServiceAccount(namespace, serviceAccount)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating clients for install service account %s: %s", serviceAccount, err)
+	}
+	return opClient, crClient, nil
+}
+
+// getSourcesSnapshot copies the sources in the included namespaces in the order they're searched when resolving plan:
+// its preferred source first, then by descending priority, then by name and namespace.
 func (o *Operator) getSourcesSnapshot(plan *v1alpha1.InstallPlan, includedNamespaces map[string]struct{}) []registry.SourceRef {
 	o.sourcesLock.RLock()
 	defer o.sourcesLock.RUnlock()
@@ -483,19 +1219,27 @@ func (o *Operator) getSourcesSnapshot(plan *v1alpha1.InstallPlan, includedNamesp
 	for key, source := range o.sources {
 		// Only copy catalog sources in included namespaces
 		if _, ok := includedNamespaces[key.Namespace]; ok {
-			ref := registry.SourceRef{
+			sourcesSnapshot = append(sourcesSnapshot, registry.SourceRef{
 				Source:    source,
 				SourceKey: key,
-			}
-			if key.Name == plan.Spec.CatalogSource && key.Namespace == plan.Spec.CatalogSourceNamespace {
-				// Prepend preffered catalog source
-				sourcesSnapshot = append([]registry.SourceRef{ref}, sourcesSnapshot...)
-			} else {
-				// Append the catalog source
-				sourcesSnapshot = append(sourcesSnapshot, ref)
-			}
+				Priority:  o.sourcePriorities[key],
+				Preferred: key.Name == plan.Spec.CatalogSource && key.Namespace == plan.Spec.CatalogSourceNamespace,
+			})
 		}
 	}
+	sort.Slice(sourcesSnapshot, func(i, j int) bool {
+		a, b := sourcesSnapshot[i], sourcesSnapshot[j]
+		if a.Preferred != b.Preferred {
+			return a.Preferred
+		}
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		if a.SourceKey.Name != b.SourceKey.Name {
+			return a.SourceKey.Name < b.SourceKey.Name
+		}
+		return a.SourceKey.Namespace < b.SourceKey.Namespace
+	})
 
 	return sourcesSnapshot
 }
@@ -1,19 +1,47 @@
 package catalog
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
 	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/clock"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/util/workqueue"
 
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators/v1alpha1"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/clientset/versioned/fake"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/controller/registry"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/controller/registry/remote"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/operatorclient"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/queueinformer"
 )
 
 type mockTransitioner struct {
-	err error
+	err  error
+	hold *v1alpha1.InstallPlanHold
 }
 
 var _ installPlanTransitioner = &mockTransitioner{}
@@ -22,6 +50,10 @@ func (m *mockTransitioner) ResolvePlan(plan *v1alpha1.InstallPlan) error {
 	return m.err
 }
 
+func (m *mockTransitioner) HoldPlan(plan *v1alpha1.InstallPlan) *v1alpha1.InstallPlanHold {
+	return m.hold
+}
+
 func (m *mockTransitioner) ExecutePlan(plan *v1alpha1.InstallPlan) error {
 	return m.err
 }
@@ -94,7 +126,7 @@ func TestTransitionInstallPlan(t *testing.T) {
 		}
 
 		// Create a transitioner that returns the provided error.
-		transitioner := &mockTransitioner{tt.transError}
+		transitioner := &mockTransitioner{err: tt.transError}
 
 		// Attempt to transition phases.
 		out, _ := transitionInstallPlanState(transitioner, *plan)
@@ -115,6 +147,240 @@ func TestTransitionInstallPlan(t *testing.T) {
 	}
 }
 
+func TestTransitionInstallPlanInsufficientPermissions(t *testing.T) {
+	forbidden := k8serrors.NewForbidden(schema.GroupResource{Resource: "secrets"}, "pull-secret", errors.New("no access"))
+	plan := v1alpha1.InstallPlan{
+		Status: v1alpha1.InstallPlanStatus{
+			Phase:      v1alpha1.InstallPlanPhaseInstalling,
+			Conditions: []v1alpha1.InstallPlanCondition{},
+		},
+	}
+
+	out, err := transitionInstallPlanState(&mockTransitioner{err: forbidden}, plan)
+	require.Equal(t, forbidden, err)
+	require.Equal(t, v1alpha1.InstallPlanPhaseFailed, out.Status.Phase)
+	require.Equal(t, 1, len(out.Status.Conditions))
+	require.Equal(t, v1alpha1.InstallPlanInstalled, out.Status.Conditions[0].Type)
+	require.Equal(t, v1alpha1.InstallPlanReasonInsufficientPermissions, out.Status.Conditions[0].Reason)
+}
+
+func TestTransitionInstallPlanHold(t *testing.T) {
+	hold := &v1alpha1.InstallPlanHold{Reason: v1alpha1.InstallPlanHoldUpgradesFrozen, Message: "frozen"}
+
+	// an automatically approved InstallPlan is held once it's resolved
+	plan := v1alpha1.InstallPlan{
+		Spec:   v1alpha1.InstallPlanSpec{Approval: v1alpha1.ApprovalAutomatic},
+		Status: v1alpha1.InstallPlanStatus{Phase: v1alpha1.InstallPlanPhasePlanning},
+	}
+	out, err := transitionInstallPlanState(&mockTransitioner{hold: hold}, plan)
+	require.NoError(t, err)
+	require.Equal(t, v1alpha1.InstallPlanPhaseWaiting, out.Status.Phase)
+	require.Equal(t, hold, out.Status.Hold)
+
+	out, err = transitionInstallPlanState(&mockTransitioner{hold: hold}, *out)
+	require.NoError(t, err)
+	require.Equal(t, v1alpha1.InstallPlanPhaseWaiting, out.Status.Phase)
+
+	// and installed once it's released
+	out, err = transitionInstallPlanState(&mockTransitioner{}, *out)
+	require.NoError(t, err)
+	require.Equal(t, v1alpha1.InstallPlanPhaseInstalling, out.Status.Phase)
+	require.Nil(t, out.Status.Hold)
+
+	// a manual InstallPlan is held once it's approved
+	plan = v1alpha1.InstallPlan{
+		Spec:   v1alpha1.InstallPlanSpec{Approval: v1alpha1.ApprovalManual, Approved: true},
+		Status: v1alpha1.InstallPlanStatus{Phase: v1alpha1.InstallPlanPhaseRequiresApproval},
+	}
+	out, err = transitionInstallPlanState(&mockTransitioner{hold: hold}, plan)
+	require.NoError(t, err)
+	require.Equal(t, v1alpha1.InstallPlanPhaseWaiting, out.Status.Phase)
+	require.Equal(t, hold, out.Status.Hold)
+
+	// an InstallPlan that started installing isn't held, so it's never left half-applied
+	plan = v1alpha1.InstallPlan{Status: v1alpha1.InstallPlanStatus{Phase: v1alpha1.InstallPlanPhaseInstalling}}
+	out, err = transitionInstallPlanState(&mockTransitioner{hold: hold}, plan)
+	require.NoError(t, err)
+	require.Equal(t, v1alpha1.InstallPlanPhaseComplete, out.Status.Phase)
+	require.Nil(t, out.Status.Hold)
+}
+
+func TestTransitionInstallPlanUnfinishedSteps(t *testing.T) {
+	// a plan whose execution stopped before every step was created stays installing
+	plan := v1alpha1.InstallPlan{Status: v1alpha1.InstallPlanStatus{
+		Phase: v1alpha1.InstallPlanPhaseInstalling,
+		Plan: []v1alpha1.Step{
+			{Resource: v1alpha1.StepResource{Kind: crdKind, Name: "widgets.example.com"}, Status: v1alpha1.StepStatusCreated},
+			{Resource: v1alpha1.StepResource{Kind: v1alpha1.ClusterServiceVersionKind, Name: "widget.v1"}, Status: v1alpha1.StepStatusUnknown},
+		},
+	}}
+	out, err := transitionInstallPlanState(&mockTransitioner{}, plan)
+	require.NoError(t, err)
+	require.Equal(t, v1alpha1.InstallPlanPhaseInstalling, out.Status.Phase)
+	require.Empty(t, out.Status.Conditions)
+
+	out.Status.Plan[1].Status = v1alpha1.StepStatusCreated
+	out, err = transitionInstallPlanState(&mockTransitioner{}, *out)
+	require.NoError(t, err)
+	require.Equal(t, v1alpha1.InstallPlanPhaseComplete, out.Status.Phase)
+}
+
+func TestExecutePlanSecretStep(t *testing.T) {
+	secret := func(namespace, name string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Data:       map[string][]byte{"token": []byte(namespace)},
+		}
+	}
+	catsrc := func(namespace, name string, secrets ...string) *v1alpha1.CatalogSource {
+		return &v1alpha1.CatalogSource{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       v1alpha1.CatalogSourceSpec{Secrets: secrets},
+		}
+	}
+
+	tests := []struct {
+		description    string
+		resource       v1alpha1.StepResource
+		expectedErr    string
+		expectedCopied []byte
+	}{
+		{
+			description:    "CopiesSecret",
+			resource:       v1alpha1.StepResource{CatalogSource: "global", CatalogSourceNamespace: "olm", Name: "pull", Kind: secretKind},
+			expectedCopied: []byte("olm"),
+		},
+		{
+			description: "OtherNamespaceCatalogSource",
+			resource:    v1alpha1.StepResource{CatalogSource: "tenant", CatalogSourceNamespace: "other", Name: "pull", Kind: secretKind},
+			expectedErr: "secret pull: catalog source tenant in namespace other is not visible to namespace ns",
+		},
+		{
+			description: "SecretNotRequired",
+			resource:    v1alpha1.StepResource{CatalogSource: "global", CatalogSourceNamespace: "olm", Name: "admin", Kind: secretKind},
+			expectedErr: "secret admin is not required by catalog source global in namespace olm",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			kubeClient := k8sfake.NewSimpleClientset(secret("olm", "pull"), secret("olm", "admin"), secret("other", "pull"))
+			mockClient := operatorclient.NewMockClientInterface(ctrl)
+			mockClient.EXPECT().KubernetesInterface().Return(kubeClient).AnyTimes()
+			op := &Operator{
+				Operator:  &queueinformer.Operator{OpClient: mockClient},
+				client:    fake.NewSimpleClientset(catsrc("olm", "global", "pull"), catsrc("other", "tenant", "pull")),
+				namespace: "olm",
+			}
+			plan := &v1alpha1.InstallPlan{
+				ObjectMeta: metav1.ObjectMeta{Name: "plan", Namespace: "ns"},
+				Status: v1alpha1.InstallPlanStatus{
+					Phase: v1alpha1.InstallPlanPhaseInstalling,
+					Plan:  []v1alpha1.Step{{Resource: tt.resource, Status: v1alpha1.StepStatusUnknown}},
+				},
+			}
+			err := op.ExecutePlan(plan)
+			if tt.expectedErr != "" {
+				require.EqualError(t, err, tt.expectedErr)
+				_, err := kubeClient.CoreV1().Secrets("ns").Get(tt.resource.Name, metav1.GetOptions{})
+				require.True(t, k8serrors.IsNotFound(err))
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, v1alpha1.StepStatusCreated, plan.Status.Plan[0].Status)
+			copied, err := kubeClient.CoreV1().Secrets("ns").Get(tt.resource.Name, metav1.GetOptions{})
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedCopied, copied.Data["token"])
+		})
+	}
+}
+
+func TestUnestablishedCRD(t *testing.T) {
+	crd := func(name string, conditions ...v1beta1.CustomResourceDefinitionCondition) *v1beta1.CustomResourceDefinition {
+		return &v1beta1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status:     v1beta1.CustomResourceDefinitionStatus{Conditions: conditions},
+		}
+	}
+	established := v1beta1.CustomResourceDefinitionCondition{Type: v1beta1.Established, Status: v1beta1.ConditionTrue}
+	namesAccepted := v1beta1.CustomResourceDefinitionCondition{Type: v1beta1.NamesAccepted, Status: v1beta1.ConditionTrue}
+	conflict := v1beta1.CustomResourceDefinitionCondition{Type: v1beta1.NamesAccepted, Status: v1beta1.ConditionFalse, Message: "plural is already in use"}
+
+	csv := &v1alpha1.ClusterServiceVersion{ObjectMeta: metav1.ObjectMeta{Name: "widget.v1"}}
+	csv.Spec.CustomResourceDefinitions.Owned = []v1alpha1.CRDDescription{{Name: "widgets.example.com"}}
+	csv.Spec.CustomResourceDefinitions.Required = []v1alpha1.CRDDescription{{Name: "gadgets.example.com"}}
+
+	tests := []struct {
+		description string
+		existing    []runtime.Object
+		expected    string
+		expectedErr string
+	}{
+		{
+			description: "Established",
+			existing:    []runtime.Object{crd("widgets.example.com", namesAccepted, established), crd("gadgets.example.com", established)},
+		},
+		{
+			// a missing required CRD is left to the CSV's requirements
+			description: "RequiredMissing",
+			existing:    []runtime.Object{crd("widgets.example.com", established)},
+		},
+		{
+			description: "NotEstablished",
+			existing:    []runtime.Object{crd("widgets.example.com", namesAccepted), crd("gadgets.example.com", established)},
+			expected:    "widgets.example.com",
+		},
+		{
+			description: "NamesNotAccepted",
+			existing:    []runtime.Object{crd("widgets.example.com", conflict)},
+			expectedErr: "names of CRD widgets.example.com were not accepted: plural is already in use",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockClient := operatorclient.NewMockClientInterface(ctrl)
+			mockClient.EXPECT().ApiextensionsV1beta1Interface().Return(apiextensionsfake.NewSimpleClientset(tt.existing...))
+			op := &Operator{Operator: &queueinformer.Operator{OpClient: mockClient}}
+
+			name, err := op.unestablishedCRD(csv)
+			if tt.expectedErr != "" {
+				require.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, name)
+		})
+	}
+}
+
+func TestDeleteHandlers(t *testing.T) {
+	catsrc := &v1alpha1.CatalogSource{ObjectMeta: metav1.ObjectMeta{Name: "catalog", Namespace: "ns"}}
+	sub := &v1alpha1.Subscription{ObjectMeta: metav1.ObjectMeta{Name: "sub", Namespace: "ns"}}
+	op := &Operator{
+		sources: map[registry.SourceKey]registry.Source{
+			{Name: "catalog", Namespace: "ns"}: registry.NewInMem(),
+			{Name: "other", Namespace: "ns"}:   registry.NewInMem(),
+		},
+		subscriptions: map[registry.SubscriptionKey]v1alpha1.Subscription{
+			{Name: "sub", Namespace: "ns"}: *sub,
+		},
+	}
+
+	require.NoError(t, op.deleteCatalogSource(catsrc))
+	require.Len(t, op.sources, 1)
+	require.Contains(t, op.sources, registry.SourceKey{Name: "other", Namespace: "ns"})
+	require.False(t, op.sourcesLastUpdate.IsZero())
+
+	require.NoError(t, op.deleteSubscription(sub))
+	require.Empty(t, op.subscriptions)
+
+	require.Error(t, op.deleteCatalogSource(sub))
+}
+
 func installPlan(names ...string) v1alpha1.InstallPlan {
 	return v1alpha1.InstallPlan{
 		Spec: v1alpha1.InstallPlanSpec{
@@ -164,3 +430,595 @@ func crd(name string) v1beta1.CustomResourceDefinition {
 		},
 	}
 }
+
+func TestSyncCatalogSourcesVerification(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	require.NoError(t, err)
+	trustedKey := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+
+	catalogData := func() *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "catalog", Namespace: "ns"},
+			Data: map[string]string{
+				registry.ConfigMapCSVName:     "- metadata:\n    name: test.v1\n  spec:\n    displayName: Test\n",
+				registry.ConfigMapPackageName: "- packageName: test\n  channels:\n  - name: alpha\n    currentCSV: test.v1\n",
+			},
+		}
+	}
+	signed := catalogData()
+	require.NoError(t, registry.SignCatalogConfigMap(signed, key))
+
+	lastGood := registry.NewInMem()
+	sourceKey := registry.SourceKey{Name: "catsrc", Namespace: "ns"}
+	tests := []struct {
+		description      string
+		configMap        *corev1.ConfigMap
+		status           *v1alpha1.CatalogVerificationStatus
+		inService        bool
+		expectedErr      bool
+		expectedVerified bool
+		expectedMessage  string
+		expectedLastGood bool
+	}{
+		{
+			description:      "Signed",
+			configMap:        signed,
+			expectedVerified: true,
+		},
+		{
+			description:      "UnsignedKeepsLastVerified",
+			configMap:        catalogData(),
+			status:           &v1alpha1.CatalogVerificationStatus{Verified: true},
+			inService:        true,
+			expectedErr:      true,
+			expectedVerified: true,
+			expectedMessage:  "catalog ConfigMap catalog failed verification: no signature found",
+			expectedLastGood: true,
+		},
+		{
+			description:     "UnsignedRemovesUnverified",
+			configMap:       catalogData(),
+			inService:       true,
+			expectedErr:     true,
+			expectedMessage: "catalog ConfigMap catalog failed verification: no signature found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			catsrc := &v1alpha1.CatalogSource{
+				ObjectMeta: metav1.ObjectMeta{Name: "catsrc", Namespace: "ns"},
+				Spec: v1alpha1.CatalogSourceSpec{
+					ConfigMap:    "catalog",
+					Verification: &v1alpha1.CatalogSourceVerification{PublicKeys: []string{trustedKey}},
+				},
+				Status: v1alpha1.CatalogSourceStatus{Verification: tt.status},
+			}
+			clientFake := fake.NewSimpleClientset(catsrc)
+			mockClient := operatorclient.NewMockClientInterface(ctrl)
+			mockClient.EXPECT().KubernetesInterface().Return(k8sfake.NewSimpleClientset(tt.configMap))
+
+			op := &Operator{
+				Operator:  &queueinformer.Operator{OpClient: mockClient},
+				client:    clientFake,
+				namespace: "ns",
+				sources:   map[registry.SourceKey]registry.Source{},
+			}
+			if tt.inService {
+				op.sources[sourceKey] = lastGood
+			}
+
+			err := op.syncCatalogSources(catsrc)
+			if tt.expectedErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			src, ok := op.sources[sourceKey]
+			if tt.expectedLastGood {
+				require.True(t, src == lastGood)
+			} else if tt.expectedErr {
+				require.False(t, ok)
+			} else {
+				require.True(t, ok)
+				require.False(t, src == lastGood)
+			}
+
+			updated, err := clientFake.OperatorsV1alpha1().CatalogSources("ns").Get("catsrc", metav1.GetOptions{})
+			require.NoError(t, err)
+			require.NotNil(t, updated.Status.Verification)
+			require.Equal(t, tt.expectedVerified, updated.Status.Verification.Verified)
+			require.Equal(t, tt.expectedMessage, updated.Status.Verification.Message)
+			require.Equal(t, tt.expectedMessage != "", updated.Status.Verification.LastFailure != nil)
+		})
+	}
+}
+
+func TestSyncCatalogSourcesConfigMapSet(t *testing.T) {
+	configMap := func(name string, labels map[string]string, key, value string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns", Labels: labels},
+			Data:       map[string]string{key: value},
+		}
+	}
+	split := map[string]string{"catalog": "split"}
+	objects := []runtime.Object{
+		configMap("csvs", nil, registry.ConfigMapCSVName, "- metadata:\n    name: test.v1\n  spec:\n    displayName: Test\n"),
+		configMap("packages", split, registry.ConfigMapPackageName, "- packageName: test\n  channels:\n  - name: alpha\n    currentCSV: test.v1\n"),
+	}
+
+	tests := []struct {
+		description string
+		spec        v1alpha1.CatalogSourceSpec
+		expectedErr string
+	}{
+		{
+			description: "ConfigMaps",
+			spec:        v1alpha1.CatalogSourceSpec{ConfigMap: "packages", ConfigMaps: []string{"csvs"}},
+		},
+		{
+			description: "ConfigMapSelector",
+			spec: v1alpha1.CatalogSourceSpec{
+				ConfigMaps:        []string{"csvs"},
+				ConfigMapSelector: &metav1.LabelSelector{MatchLabels: split},
+			},
+		},
+		{
+			description: "MissingReference",
+			spec:        v1alpha1.CatalogSourceSpec{ConfigMapSelector: &metav1.LabelSelector{MatchLabels: split}},
+			expectedErr: `failed to create catalog source from ConfigMaps selected by "catalog=split": Missing CSV with name test.v1`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			catsrc := &v1alpha1.CatalogSource{
+				ObjectMeta: metav1.ObjectMeta{Name: "catsrc", Namespace: "ns"},
+				Spec:       tt.spec,
+			}
+			mockClient := operatorclient.NewMockClientInterface(ctrl)
+			mockClient.EXPECT().KubernetesInterface().Return(k8sfake.NewSimpleClientset(objects...)).AnyTimes()
+			op := &Operator{
+				Operator:  &queueinformer.Operator{OpClient: mockClient},
+				client:    fake.NewSimpleClientset(catsrc),
+				namespace: "ns",
+				sources:   map[registry.SourceKey]registry.Source{},
+			}
+
+			err := op.syncCatalogSources(catsrc)
+			src, ok := op.sources[registry.SourceKey{Name: "catsrc", Namespace: "ns"}]
+			if tt.expectedErr != "" {
+				require.EqualError(t, err, tt.expectedErr)
+				require.False(t, ok)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, ok)
+			csv, err := src.FindCSVForPackageNameUnderChannel("test", "alpha")
+			require.NoError(t, err)
+			require.Equal(t, "test.v1", csv.GetName())
+		})
+	}
+}
+
+func TestSyncCatalogSourcesStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "catalog", Namespace: "ns", UID: "cm-uid", ResourceVersion: "1"},
+		Data: map[string]string{
+			registry.ConfigMapCSVName:     "- metadata:\n    name: test.v1\n  spec:\n    displayName: Test\n",
+			registry.ConfigMapPackageName: "- packageName: test\n  channels:\n  - name: alpha\n    currentCSV: test.v1\n",
+		},
+	}
+	catsrc := &v1alpha1.CatalogSource{
+		ObjectMeta: metav1.ObjectMeta{Name: "catsrc", Namespace: "ns"},
+		Spec:       v1alpha1.CatalogSourceSpec{ConfigMap: "catalog"},
+	}
+	k8sClient := k8sfake.NewSimpleClientset(cm)
+	mockClient := operatorclient.NewMockClientInterface(ctrl)
+	mockClient.EXPECT().KubernetesInterface().Return(k8sClient).AnyTimes()
+	clientFake := fake.NewSimpleClientset(catsrc)
+	fakeClock := clock.NewFakeClock(time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC))
+	op := &Operator{
+		Operator:  &queueinformer.Operator{OpClient: mockClient},
+		client:    clientFake,
+		namespace: "ns",
+		sources:   map[registry.SourceKey]registry.Source{},
+		clock:     fakeClock,
+	}
+	sourceKey := registry.SourceKey{Name: "catsrc", Namespace: "ns"}
+	sync := func() (*v1alpha1.CatalogSource, error) {
+		current, err := clientFake.OperatorsV1alpha1().CatalogSources("ns").Get("catsrc", metav1.GetOptions{})
+		require.NoError(t, err)
+		syncErr := op.syncCatalogSources(current)
+		stored, err := clientFake.OperatorsV1alpha1().CatalogSources("ns").Get("catsrc", metav1.GetOptions{})
+		require.NoError(t, err)
+		return stored, syncErr
+	}
+
+	stored, err := sync()
+	require.NoError(t, err)
+	loaded := op.sources[sourceKey]
+	firstSync := metav1.NewTime(fakeClock.Now())
+	require.NotEmpty(t, stored.Status.Hash)
+	require.Equal(t, &v1alpha1.ConfigMapResourceReference{
+		Name:            "catalog",
+		Namespace:       "ns",
+		UID:             "cm-uid",
+		ResourceVersion: "1",
+		Hash:            stored.Status.ConfigMapResources[0].Hash,
+	}, stored.Status.ConfigMapResource)
+	require.Len(t, stored.Status.ConfigMapResources, 1)
+	require.Equal(t, &v1alpha1.CatalogContentsStatus{Packages: 1, ClusterServiceVersions: 1}, stored.Status.Contents)
+	require.Equal(t, firstSync, stored.Status.LastSync)
+	require.Equal(t, firstSync, stored.Status.LastAttempt)
+	healthy, ok := stored.Status.GetCondition(v1alpha1.CatalogSourceHealthy)
+	require.True(t, ok)
+	require.Equal(t, corev1.ConditionTrue, healthy.Status)
+	require.Equal(t, v1alpha1.CatalogSourceReasonLoaded, healthy.Reason)
+
+	// unchanged content isn't loaded again, and the status isn't rewritten
+	fakeClock.Step(time.Minute)
+	hash := stored.Status.Hash
+	stored, err = sync()
+	require.NoError(t, err)
+	require.True(t, op.sources[sourceKey] == loaded)
+	require.Equal(t, hash, stored.Status.Hash)
+	require.Equal(t, firstSync, stored.Status.LastSync)
+
+	// changed content is loaded
+	cm.Data[registry.ConfigMapCSVName] = "- metadata:\n    name: test.v1\n  spec:\n    displayName: Changed\n"
+	cm.ResourceVersion = "2"
+	_, err = k8sClient.CoreV1().ConfigMaps("ns").Update(cm)
+	require.NoError(t, err)
+	stored, err = sync()
+	require.NoError(t, err)
+	require.False(t, op.sources[sourceKey] == loaded)
+	require.NotEqual(t, hash, stored.Status.Hash)
+	require.Equal(t, "2", stored.Status.ConfigMapResource.ResourceVersion)
+	require.Equal(t, metav1.NewTime(fakeClock.Now()), stored.Status.LastSync)
+
+	// load errors are recorded, and the last catalog loaded stays in service
+	loaded = op.sources[sourceKey]
+	lastSync := stored.Status.LastSync
+	fakeClock.Step(time.Minute)
+	cm.Data[registry.ConfigMapPackageName] = "- packageName: test\n  channels:\n  - name: alpha\n    currentCSV: missing\n"
+	_, err = k8sClient.CoreV1().ConfigMaps("ns").Update(cm)
+	require.NoError(t, err)
+	stored, err = sync()
+	require.EqualError(t, err, "failed to create catalog source from ConfigMap catalog: Missing CSV with name missing")
+	require.True(t, op.sources[sourceKey] == loaded)
+	require.Equal(t, lastSync, stored.Status.LastSync)
+	require.Equal(t, metav1.NewTime(fakeClock.Now()), stored.Status.LastAttempt)
+	healthy, ok = stored.Status.GetCondition(v1alpha1.CatalogSourceHealthy)
+	require.True(t, ok)
+	require.Equal(t, corev1.ConditionFalse, healthy.Status)
+	require.Equal(t, v1alpha1.CatalogSourceReasonLoadFailed, healthy.Reason)
+	require.Equal(t, "Missing CSV with name missing", healthy.Message)
+}
+
+func TestSyncCatalogSourcesFilter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "catalog", Namespace: "ns"},
+		Data: map[string]string{
+			registry.ConfigMapCSVName:     "- metadata:\n    name: test.v1\n- metadata:\n    name: other.v1\n",
+			registry.ConfigMapPackageName: "- packageName: test\n  channels:\n  - name: alpha\n    currentCSV: test.v1\n- packageName: other\n  channels:\n  - name: alpha\n    currentCSV: other.v1\n",
+		},
+	}
+	catsrc := &v1alpha1.CatalogSource{
+		ObjectMeta: metav1.ObjectMeta{Name: "catsrc", Namespace: "ns"},
+		Spec: v1alpha1.CatalogSourceSpec{
+			ConfigMap: "catalog",
+			Exclude:   &v1alpha1.CatalogPackageFilter{Packages: []string{"oth*"}},
+		},
+	}
+	mockClient := operatorclient.NewMockClientInterface(ctrl)
+	mockClient.EXPECT().KubernetesInterface().Return(k8sfake.NewSimpleClientset(cm)).AnyTimes()
+	clientFake := fake.NewSimpleClientset(catsrc)
+	op := &Operator{
+		Operator:  &queueinformer.Operator{OpClient: mockClient},
+		client:    clientFake,
+		namespace: "ns",
+		sources:   map[registry.SourceKey]registry.Source{},
+	}
+	sourceKey := registry.SourceKey{Name: "catsrc", Namespace: "ns"}
+	sync := func(catsrc *v1alpha1.CatalogSource) *v1alpha1.CatalogSource {
+		require.NoError(t, op.syncCatalogSources(catsrc))
+		stored, err := clientFake.OperatorsV1alpha1().CatalogSources("ns").Get("catsrc", metav1.GetOptions{})
+		require.NoError(t, err)
+		return stored
+	}
+
+	stored := sync(catsrc)
+	require.Equal(t, &v1alpha1.CatalogFilterStatus{Packages: []string{"other"}, ClusterServiceVersions: 1}, stored.Status.Filtered)
+	require.Equal(t, &v1alpha1.CatalogContentsStatus{Packages: 1, ClusterServiceVersions: 1}, stored.Status.Contents)
+	_, err := op.sources[sourceKey].FindCSVForPackageNameUnderChannel("other", "alpha")
+	require.Error(t, err)
+	_, err = op.sources[sourceKey].FindCSVByName("other.v1")
+	require.Error(t, err)
+
+	// changing the filters reloads the catalog, though its ConfigMaps haven't changed
+	stored.Spec.Exclude = nil
+	stored, err = clientFake.OperatorsV1alpha1().CatalogSources("ns").Update(stored)
+	require.NoError(t, err)
+	stored = sync(stored)
+	require.Nil(t, stored.Status.Filtered)
+	_, err = op.sources[sourceKey].FindCSVForPackageNameUnderChannel("other", "alpha")
+	require.NoError(t, err)
+
+	// registry servers aren't filtered
+	stored.Spec.SourceType = v1alpha1.SourceTypeGrpc
+	stored.Spec.Address = "localhost:50051"
+	stored.Spec.Include = &v1alpha1.CatalogPackageFilter{}
+	err = op.syncCatalogSources(stored)
+	require.EqualError(t, err, "failed to create catalog source from registry server localhost:50051: package filters are only supported for catalogs loaded into memory")
+}
+
+func TestSyncCatalogSourcesRegistry(t *testing.T) {
+	catalog := registry.NewInMem()
+	catalog.AddOrReplaceService(v1alpha1.ClusterServiceVersion{ObjectMeta: metav1.ObjectMeta{Name: "test.v1"}})
+	server := httptest.NewServer(remote.NewServer(catalog))
+	defer server.Close()
+
+	sourceKey := registry.SourceKey{Name: "catsrc", Namespace: "ns"}
+	tests := []struct {
+		description string
+		address     string
+		expectedErr string
+	}{
+		{
+			description: "Healthy",
+			address:     server.URL,
+		},
+		{
+			description: "NoAddress",
+			expectedErr: "failed to create catalog source from registry server : no address set",
+		},
+		{
+			description: "Unreachable",
+			address:     "http://127.0.0.1:0",
+			expectedErr: "failed to create catalog source from registry server http://127.0.0.1:0: registry server http://127.0.0.1:0 is unreachable",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			catsrc := &v1alpha1.CatalogSource{
+				ObjectMeta: metav1.ObjectMeta{Name: "catsrc", Namespace: "ns"},
+				Spec: v1alpha1.CatalogSourceSpec{
+					SourceType: v1alpha1.SourceTypeGrpc,
+					Address:    tt.address,
+				},
+			}
+			op := &Operator{
+				Operator:   &queueinformer.Operator{},
+				client:     fake.NewSimpleClientset(catsrc),
+				namespace:  "ns",
+				sources:    map[registry.SourceKey]registry.Source{},
+				registries: map[registry.SourceKey]*remote.Client{},
+			}
+
+			err := op.syncCatalogSources(catsrc)
+			if tt.expectedErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.expectedErr)
+				require.NotContains(t, op.sources, sourceKey)
+				return
+			}
+			require.NoError(t, err)
+
+			src, ok := op.sources[sourceKey]
+			require.True(t, ok)
+			csv, err := src.FindCSVByName("test.v1")
+			require.NoError(t, err)
+			require.Equal(t, "test.v1", csv.GetName())
+
+			// the client, and its response cache, are kept across syncs
+			require.NoError(t, op.syncCatalogSources(catsrc))
+			require.True(t, src == op.sources[sourceKey])
+		})
+	}
+}
+
+func catalogArchive(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, data := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(data))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func TestSyncCatalogSourcesArchive(t *testing.T) {
+	archive := catalogArchive(t, map[string]string{
+		"test.clusterserviceversion.yaml": "apiVersion: operators.coreos.com/v1alpha1\nkind: ClusterServiceVersion\nmetadata:\n  name: test.v1\nspec:\n  displayName: Test\n",
+		"test.package.yaml":               "packageName: test\nchannels:\n- name: alpha\n  currentCSV: test.v1\n",
+	})
+	sum := sha256.Sum256(archive)
+	revision := hex.EncodeToString(sum[:])
+
+	fetches := 0
+	var served []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		w.Write(served)
+	}))
+	defer server.Close()
+
+	fakeClock := clock.NewFakeClock(time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC))
+	catsrc := &v1alpha1.CatalogSource{
+		ObjectMeta: metav1.ObjectMeta{Name: "catsrc", Namespace: "ns"},
+		Spec: v1alpha1.CatalogSourceSpec{
+			SourceType:   v1alpha1.SourceTypeHTTP,
+			URL:          server.URL,
+			Checksum:     revision,
+			PollInterval: &metav1.Duration{Duration: time.Minute},
+		},
+	}
+	clientFake := fake.NewSimpleClientset(catsrc)
+	queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	defer queue.ShutDown()
+	op := &Operator{
+		Operator:    &queueinformer.Operator{},
+		client:      clientFake,
+		namespace:   "ns",
+		sources:     map[registry.SourceKey]registry.Source{},
+		archives:    map[registry.SourceKey]*archiveSource{},
+		catsrcQueue: queue,
+		clock:       fakeClock,
+	}
+	sourceKey := registry.SourceKey{Name: "catsrc", Namespace: "ns"}
+	sync := func() error {
+		latest, err := clientFake.OperatorsV1alpha1().CatalogSources("ns").Get("catsrc", metav1.GetOptions{})
+		require.NoError(t, err)
+		return op.syncCatalogSources(latest)
+	}
+	status := func() *v1alpha1.CatalogArchiveStatus {
+		latest, err := clientFake.OperatorsV1alpha1().CatalogSources("ns").Get("catsrc", metav1.GetOptions{})
+		require.NoError(t, err)
+		return latest.Status.Archive
+	}
+
+	// an archive that fails its checksum is never served
+	served = []byte("tampered")
+	require.Error(t, sync())
+	require.NotContains(t, op.sources, sourceKey)
+	require.Contains(t, status().Message, "doesn't match expected checksum")
+
+	served = archive
+	require.NoError(t, sync())
+	src, ok := op.sources[sourceKey]
+	require.True(t, ok)
+	csv, err := src.FindCSVForPackageNameUnderChannel("test", "alpha")
+	require.NoError(t, err)
+	require.Equal(t, "test.v1", csv.GetName())
+	require.Equal(t, revision, status().Revision)
+	require.Equal(t, "", status().Message)
+	require.Equal(t, fakeClock.Now().Unix(), status().LastPoll.Unix())
+	require.Equal(t, 2, fetches)
+
+	// the archive isn't fetched again until the poll interval passes
+	fakeClock.Step(30 * time.Second)
+	require.NoError(t, sync())
+	require.Equal(t, 2, fetches)
+
+	// a failed poll keeps the last good catalog in service
+	fakeClock.Step(time.Minute)
+	served = []byte("tampered")
+	require.Error(t, sync())
+	require.Equal(t, 3, fetches)
+	require.True(t, op.sources[sourceKey] == src)
+	require.Equal(t, revision, status().Revision)
+	require.Contains(t, status().Message, "doesn't match expected checksum")
+	require.Equal(t, fakeClock.Now().Unix(), status().LastPoll.Unix())
+}
+
+func TestPollArchiveConcurrently(t *testing.T) {
+	archive := catalogArchive(t, map[string]string{
+		"test.clusterserviceversion.yaml": "apiVersion: operators.coreos.com/v1alpha1\nkind: ClusterServiceVersion\nmetadata:\n  name: test.v1\nspec:\n  displayName: Test\n",
+		"test.package.yaml":               "packageName: test\nchannels:\n- name: alpha\n  currentCSV: test.v1\n",
+	})
+	sum := sha256.Sum256(archive)
+	fetching := make(chan struct{})
+	release := make(chan struct{})
+	var releaseOnce sync.Once
+	unblock := func() { releaseOnce.Do(func() { close(release) }) }
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			close(fetching)
+			<-release
+		}
+		w.Write(archive)
+	}))
+	defer server.Close()
+	defer unblock()
+
+	queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	defer queue.ShutDown()
+	op := &Operator{
+		Operator:    &queueinformer.Operator{},
+		client:      fake.NewSimpleClientset(),
+		sources:     map[registry.SourceKey]registry.Source{},
+		archives:    map[registry.SourceKey]*archiveSource{},
+		catsrcQueue: queue,
+	}
+	catsrc := func(name string) *v1alpha1.CatalogSource {
+		return &v1alpha1.CatalogSource{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"},
+			Spec: v1alpha1.CatalogSourceSpec{
+				SourceType: v1alpha1.SourceTypeHTTP,
+				URL:        server.URL + "/" + name,
+				Checksum:   hex.EncodeToString(sum[:]),
+			},
+		}
+	}
+
+	errc := make(chan error)
+	go func() {
+		_, err := op.pollArchive(catsrc("slow"))
+		errc <- err
+	}()
+	<-fetching
+
+	// while one archive is being fetched, other CatalogSources are polled and deleted
+	fast, err := op.pollArchive(catsrc("fast"))
+	require.NoError(t, err)
+	require.NotNil(t, fast)
+	require.NoError(t, op.deleteCatalogSource(catsrc("fast")))
+
+	unblock()
+	require.NoError(t, <-errc)
+}
+
+func TestGetSourcesSnapshotOrder(t *testing.T) {
+	keys := []registry.SourceKey{
+		{Name: "b", Namespace: "olm"},
+		{Name: "a", Namespace: "olm"},
+		{Name: "a", Namespace: "ns"},
+		{Name: "preferred", Namespace: "ns"},
+		{Name: "z", Namespace: "olm"},
+		{Name: "y", Namespace: "olm"},
+		{Name: "hidden", Namespace: "other"},
+	}
+	op := &Operator{
+		namespace:        "olm",
+		sources:          map[registry.SourceKey]registry.Source{},
+		sourcePriorities: map[registry.SourceKey]int{keys[4]: 10, keys[5]: 10, keys[3]: -1},
+	}
+	for _, key := range keys {
+		op.sources[key] = registry.NewInMem()
+	}
+	plan := &v1alpha1.InstallPlan{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns"},
+		Spec:       v1alpha1.InstallPlanSpec{CatalogSource: "preferred", CatalogSourceNamespace: "ns"},
+	}
+
+	// the order doesn't depend on map iteration
+	for i := 0; i < 10; i++ {
+		snapshot := op.getSourcesSnapshot(plan, map[string]struct{}{"olm": {}, "ns": {}})
+		order := []string{}
+		for _, ref := range snapshot {
+			order = append(order, ref.SourceKey.Namespace+"/"+ref.SourceKey.Name)
+		}
+		require.Equal(t, []string{"ns/preferred", "olm/y", "olm/z", "ns/a", "olm/a", "olm/b"}, order)
+		require.True(t, snapshot[0].Preferred)
+		require.Equal(t, -1, snapshot[0].Priority)
+		require.Equal(t, 10, snapshot[1].Priority)
+	}
+}
@@ -6,20 +6,38 @@ import (
 
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators/v1alpha1"
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/controller/registry"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/versionrange"
 
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/ownerutil"
 	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
 )
 
 var (
 	ErrNilSubscription = errors.New("invalid Subscription object: <nil>")
 )
 
+// subscriptionError is an error syncing a Subscription that's reported by one of its conditions
+type subscriptionError struct {
+	condition v1alpha1.SubscriptionConditionType
+	reason    v1alpha1.SubscriptionConditionReason
+	err       error
+}
+
+func (e subscriptionError) Error() string {
+	return e.err.Error()
+}
+
 const (
 	PackageLabel = "alm-package"
 	CatalogLabel = "alm-catalog"
 	ChannelLabel = "alm-channel"
+
+	// csvIndex indexes Subscriptions by the namespace/name of the CSVs they mirror or are about to mirror
+	csvIndex = "csv"
 )
 
 // FIXME(alecmerdler): Rewrite this whole block to be more clear
@@ -30,23 +48,44 @@ func (o *Operator) syncSubscription(sub *v1alpha1.Subscription) (*v1alpha1.Subsc
 
 	sub = ensureLabels(sub)
 
+	o.sourcesLock.Lock()
+	defer o.sourcesLock.Unlock()
+
 	// Only sync if catalog has been updated since last sync time
-	if o.sourcesLastUpdate.Before(&sub.Status.LastUpdated) && sub.Status.State == v1alpha1.SubscriptionStateAtLatest {
+	if o.sourcesLastUpdate.Before(&sub.Status.LastUpdated) && sub.Status.State == v1alpha1.SubscriptionStateAtLatest && !channelChanged(sub) && sub.Status.BlockedCSV == "" {
 		log.Infof("skipping sync: no new updates to catalog since last sync at %s",
 			sub.Status.LastUpdated.String())
 		return nil, nil
 	}
 
-	o.sourcesLock.Lock()
-	defer o.sourcesLock.Unlock()
-
 	catalogNamespace := sub.Spec.CatalogSourceNamespace
 	if catalogNamespace == "" {
 		catalogNamespace = o.namespace
 	}
-	catalog, ok := o.sources[registry.SourceKey{Name: sub.Spec.CatalogSource, Namespace: catalogNamespace}]
+	catalogKey := registry.SourceKey{Name: sub.Spec.CatalogSource, Namespace: catalogNamespace}
+	if !o.sourceVisibleTo(catalogKey, sub.GetNamespace()) {
+		return sub, subscriptionError{
+			condition: v1alpha1.SubscriptionCatalogSourceUnavailable,
+			reason:    v1alpha1.SubscriptionReasonCatalogSourceNotVisible,
+			err:       fmt.Errorf("catalog source %s in namespace %s is not visible to namespace %s", sub.Spec.CatalogSource, catalogNamespace, sub.GetNamespace()),
+		}
+	}
+	catalog, ok := o.sources[catalogKey]
 	if !ok {
-		return sub, fmt.Errorf("unknown catalog source %s in namespace %s", sub.Spec.CatalogSource, catalogNamespace)
+		return sub, subscriptionError{
+			condition: v1alpha1.SubscriptionCatalogSourceUnavailable,
+			reason:    v1alpha1.SubscriptionReasonCatalogSourceNotFound,
+			err:       fmt.Errorf("unknown catalog source %s in namespace %s", sub.Spec.CatalogSource, catalogNamespace),
+		}
+	}
+
+	versionRange, err := subscriptionVersionRange(sub)
+	if err != nil {
+		return sub, subscriptionError{
+			condition: v1alpha1.SubscriptionResolutionFailed,
+			reason:    v1alpha1.SubscriptionReasonInvalidVersionRange,
+			err:       err,
+		}
 	}
 
 	// Find latest CSV if no CSVs are installed already
@@ -56,13 +95,31 @@ func (o *Operator) syncSubscription(sub *v1alpha1.Subscription) (*v1alpha1.Subsc
 		} else {
 			csv, err := catalog.FindCSVForPackageNameUnderChannel(sub.Spec.Package, sub.Spec.Channel)
 			if err != nil {
-				return sub, fmt.Errorf("failed to find CSV for package %s in channel %s: %v", sub.Spec.Package, sub.Spec.Channel, err)
+				return sub, subscriptionError{
+					condition: v1alpha1.SubscriptionResolutionFailed,
+					reason:    v1alpha1.SubscriptionReasonPackageNotFound,
+					err:       fmt.Errorf("failed to find CSV for package %s in channel %s: %v", sub.Spec.Package, sub.Spec.Channel, err),
+				}
 			}
 			if csv == nil {
-				return sub, fmt.Errorf("failed to find CSV for package %s in channel %s: nil CSV", sub.Spec.Package, sub.Spec.Channel)
+				return sub, subscriptionError{
+					condition: v1alpha1.SubscriptionResolutionFailed,
+					reason:    v1alpha1.SubscriptionReasonPackageNotFound,
+					err:       fmt.Errorf("failed to find CSV for package %s in channel %s: nil CSV", sub.Spec.Package, sub.Spec.Channel),
+				}
 			}
-			sub.Status.CurrentCSV = csv.GetName()
+			latest, blocked, err := latestInVersionRange(catalog, csv, versionRange)
+			if err != nil {
+				return sub, subscriptionError{
+					condition: v1alpha1.SubscriptionResolutionFailed,
+					reason:    v1alpha1.SubscriptionReasonNoVersionInRange,
+					err:       fmt.Errorf("failed to find CSV for package %s in channel %s: %v", sub.Spec.Package, sub.Spec.Channel, err),
+				}
+			}
+			setBlockedCSV(sub, blocked)
+			sub.Status.CurrentCSV = latest.GetName()
 		}
+		sub.Status.Channel = sub.Spec.Channel
 		sub.Status.State = v1alpha1.SubscriptionStateUpgradeAvailable
 		return sub, nil
 	}
@@ -86,6 +143,20 @@ func (o *Operator) syncSubscription(sub *v1alpha1.Subscription) (*v1alpha1.Subsc
 
 		// Install CSV if doesn't exist
 		sub.Status.State = v1alpha1.SubscriptionStateUpgradePending
+		existing, err := o.existingInstallPlan(sub)
+		if err != nil {
+			return sub, fmt.Errorf("failed to list installplans for %s: %v", sub.Status.CurrentCSV, err)
+		}
+		if existing != nil {
+			log.Infof("installplan %s for %s already exists", existing.GetName(), sub.Status.CurrentCSV)
+			sub.Status.Install = &v1alpha1.InstallPlanReference{
+				UID:        existing.GetUID(),
+				Name:       existing.GetName(),
+				APIVersion: v1alpha1.SchemeGroupVersion.String(),
+				Kind:       v1alpha1.InstallPlanKind,
+			}
+			return sub, nil
+		}
 		ip := &v1alpha1.InstallPlan{
 			ObjectMeta: metav1.ObjectMeta{},
 			Spec: v1alpha1.InstallPlanSpec{
@@ -101,6 +172,9 @@ func (o *Operator) syncSubscription(sub *v1alpha1.Subscription) (*v1alpha1.Subsc
 		ip.Spec.CatalogSource = sub.Spec.CatalogSource
 		ip.Spec.CatalogSourceNamespace = sub.Spec.CatalogSourceNamespace
 
+		// Install with the subscription's service account, if it names one
+		ip.Spec.ServiceAccountName = sub.Spec.ServiceAccountName
+
 		res, err := o.client.OperatorsV1alpha1().InstallPlans(sub.GetNamespace()).Create(ip)
 		if err != nil {
 			return sub, fmt.Errorf("failed to ensure current CSV %s installed: %v", sub.Status.CurrentCSV, err)
@@ -117,16 +191,33 @@ func (o *Operator) syncSubscription(sub *v1alpha1.Subscription) (*v1alpha1.Subsc
 		return sub, nil
 	}
 
+	// Subscriptions from before channels were recorded are on their spec's channel
+	if sub.Status.Channel == "" {
+		sub.Status.Channel = sub.Spec.Channel
+	}
+	if channelChanged(sub) {
+		return switchChannel(sub, catalog, versionRange)
+	}
+
 	// Poll catalog for an update
 	repl, err := catalog.FindReplacementCSVForPackageNameUnderChannel(sub.Spec.Package, sub.Spec.Channel, sub.Status.CurrentCSV)
 	if err != nil {
 		sub.Status.State = v1alpha1.SubscriptionStateAtLatest
+		setBlockedCSV(sub, nil)
 		return sub, fmt.Errorf("failed to lookup replacement CSV for %s: %v", sub.Status.CurrentCSV, err)
 	}
 	if repl == nil {
 		sub.Status.State = v1alpha1.SubscriptionStateAtLatest
+		setBlockedCSV(sub, nil)
 		return sub, fmt.Errorf("nil replacement CSV for %s returned from catalog", sub.Status.CurrentCSV)
 	}
+	if !inVersionRange(versionRange, repl) {
+		// the current CSV is the latest the version range allows
+		sub.Status.State = v1alpha1.SubscriptionStateAtLatest
+		setBlockedCSV(sub, repl)
+		return sub, nil
+	}
+	setBlockedCSV(sub, nil)
 
 	// Update subscription with new latest
 	sub.Status.CurrentCSV = repl.GetName()
@@ -135,6 +226,347 @@ func (o *Operator) syncSubscription(sub *v1alpha1.Subscription) (*v1alpha1.Subsc
 	return sub, nil
 }
 
+// existingInstallPlan returns the InstallPlan a Subscription already created for its CurrentCSV, if any. Subscriptions
+// are synced from the informer's cache, which may not have the status that references the plan yet.
+func (o *Operator) existingInstallPlan(sub *v1alpha1.Subscription) (*v1alpha1.InstallPlan, error) {
+	plans, err := o.client.OperatorsV1alpha1().InstallPlans(sub.GetNamespace()).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range plans.Items {
+		plan := &plans.Items[i]
+		if ownerutil.IsOwnedBy(plan, sub) && containsString(plan.Spec.ClusterServiceVersionNames, sub.Status.CurrentCSV) {
+			return plan, nil
+		}
+	}
+	return nil, nil
+}
+
+// subscriptionVersionRange returns the parsed version range of a Subscription, or nil if it has none
+func subscriptionVersionRange(sub *v1alpha1.Subscription) (*versionrange.Range, error) {
+	if sub.Spec.VersionRange == "" {
+		return nil, nil
+	}
+	r, err := versionrange.Parse(sub.Spec.VersionRange)
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// inVersionRange reports whether the version of a CSV is in a version range. Every version is in a nil range.
+func inVersionRange(r *versionrange.Range, csv *v1alpha1.ClusterServiceVersion) bool {
+	return r == nil || r.Contains(csv.Spec.Version)
+}
+
+// latestInVersionRange walks a channel back from its head and returns the first CSV in the version range, along with
+// the CSV that replaces it in the channel, if that one was skipped for being outside the range.
+func latestInVersionRange(catalog registry.Source, head *v1alpha1.ClusterServiceVersion, r *versionrange.Range) (latest, blocked *v1alpha1.ClusterServiceVersion, err error) {
+	seen := map[string]bool{}
+	for csv := head; csv != nil && !seen[csv.GetName()]; {
+		if inVersionRange(r, csv) {
+			return csv, blocked, nil
+		}
+		seen[csv.GetName()] = true
+		blocked = csv
+		if csv.Spec.Replaces == "" {
+			break
+		}
+		if csv, err = catalog.FindCSVByName(csv.Spec.Replaces); err != nil {
+			return nil, nil, err
+		}
+	}
+	return nil, nil, fmt.Errorf("no CSV satisfies version range %s", r)
+}
+
+// setBlockedCSV records the CSV that would replace the Subscription's current CSV but is outside its version range
+func setBlockedCSV(sub *v1alpha1.Subscription, blocked *v1alpha1.ClusterServiceVersion) {
+	if blocked == nil {
+		sub.Status.BlockedCSV, sub.Status.BlockedVersion = "", ""
+		return
+	}
+	sub.Status.BlockedCSV, sub.Status.BlockedVersion = blocked.GetName(), blocked.Spec.Version.String()
+}
+
+// channelChanged reports whether the channel of a Subscription changed since its current CSV was taken
+func channelChanged(sub *v1alpha1.Subscription) bool {
+	return sub.Status.CurrentCSV != "" && sub.Status.Channel != "" && sub.Status.Channel != sub.Spec.Channel
+}
+
+// switchChannel moves a Subscription whose channel changed onto its new channel, upgrading to the lowest CSV in the
+// new channel that replaces or skips the installed CSV. Without such an upgrade edge, the installed CSV is kept and the
+// Subscription stays on its old channel: switching channels never downgrades.
+func switchChannel(sub *v1alpha1.Subscription, catalog registry.Source, versionRange *versionrange.Range) (*v1alpha1.Subscription, error) {
+	channelSwitch := &v1alpha1.SubscriptionChannelSwitch{
+		From:    sub.Status.Channel,
+		To:      sub.Spec.Channel,
+		FromCSV: sub.Status.CurrentCSV,
+	}
+	sub.Status.ChannelSwitch = channelSwitch
+
+	upgrade, err := findChannelUpgrade(catalog, sub.Spec.Package, sub.Spec.Channel, sub.Status.CurrentCSV)
+	if err == nil && upgrade != nil && !inVersionRange(versionRange, upgrade) {
+		err = fmt.Errorf("%s at version %s is outside version range %s", upgrade.GetName(), upgrade.Spec.Version, versionRange)
+	}
+	if err != nil {
+		channelSwitch.Message = err.Error()
+		return sub, subscriptionError{
+			condition: v1alpha1.SubscriptionResolutionFailed,
+			reason:    v1alpha1.SubscriptionReasonChannelSwitchFailed,
+			err:       fmt.Errorf("failed to switch from channel %s to %s: %s", channelSwitch.From, channelSwitch.To, err),
+		}
+	}
+	sub.Status.Channel = sub.Spec.Channel
+	if upgrade == nil {
+		// the installed CSV is the head of the new channel
+		channelSwitch.ToCSV = sub.Status.CurrentCSV
+		sub.Status.State = v1alpha1.SubscriptionStateAtLatest
+		return sub, nil
+	}
+	channelSwitch.ToCSV = upgrade.GetName()
+	sub.Status.CurrentCSV = upgrade.GetName()
+	sub.Status.Install = nil
+	sub.Status.State = v1alpha1.SubscriptionStateUpgradeAvailable
+	return sub, nil
+}
+
+// findChannelUpgrade returns the lowest CSV in a channel that replaces or skips the installed CSV, walking the channel
+// from its head back to the installed CSV or the end of the channel. A channel whose oldest CSVs were pruned from the
+// catalog ends at the last CSV that's still in it. It returns nil if the installed CSV is the head.
+func findChannelUpgrade(catalog registry.Source, packageName, channelName, installed string) (*v1alpha1.ClusterServiceVersion, error) {
+	head, err := catalog.FindCSVForPackageNameUnderChannel(packageName, channelName)
+	if err != nil {
+		return nil, err
+	}
+	if head == nil {
+		return nil, fmt.Errorf("nil CSV for package %s in channel %s returned from catalog", packageName, channelName)
+	}
+
+	var upgrade *v1alpha1.ClusterServiceVersion
+	seen := map[string]bool{}
+	for csv := head; csv != nil && !seen[csv.GetName()]; {
+		seen[csv.GetName()] = true
+		if csv.GetName() == installed {
+			// anything below the installed CSV would be a downgrade
+			return upgrade, nil
+		}
+		if csv.Spec.Replaces == installed || containsString(csv.Spec.Skips, installed) {
+			upgrade = csv
+		}
+		if csv.Spec.Replaces == "" {
+			break
+		}
+		replaces := csv.Spec.Replaces
+		if csv, err = catalog.FindCSVByName(replaces); err != nil {
+			log.Debugf("channel %s of package %s ends at pruned CSV %s: %s", channelName, packageName, replaces, err)
+			break
+		}
+	}
+	if upgrade == nil {
+		return nil, fmt.Errorf("no upgrade edge from %s: no CSV in channel %s replaces or skips it", installed, channelName)
+	}
+	return upgrade, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// setSubscriptionConditions sets the conditions of a Subscription from the outcome of its sync, the phase of its
+// InstallPlan and the phase of its installed CSV, which it mirrors in the Subscription's status. Healthy rolls them up:
+// it's true only if the installed CSV succeeded and every failure condition is false.
+func (o *Operator) setSubscriptionConditions(sub *v1alpha1.Subscription, syncErr error) {
+	failed, _ := syncErr.(subscriptionError)
+	for _, condType := range []v1alpha1.SubscriptionConditionType{v1alpha1.SubscriptionCatalogSourceUnavailable, v1alpha1.SubscriptionResolutionFailed} {
+		if failed.condition == condType {
+			sub.Status.SetCondition(subscriptionConditionTrue(condType, failed.reason, failed.Error()))
+		} else {
+			sub.Status.SetCondition(subscriptionConditionFalse(condType))
+		}
+	}
+
+	blocked := subscriptionConditionFalse(v1alpha1.SubscriptionUpgradeBlocked)
+	if sub.Status.BlockedCSV != "" {
+		blocked = subscriptionConditionTrue(v1alpha1.SubscriptionUpgradeBlocked, v1alpha1.SubscriptionReasonOutsideVersionRange,
+			fmt.Sprintf("%s at version %s is available, but outside version range %s", sub.Status.BlockedCSV, sub.Status.BlockedVersion, sub.Spec.VersionRange))
+	}
+	sub.Status.SetCondition(blocked)
+
+	pending := subscriptionConditionFalse(v1alpha1.SubscriptionInstallPlanPending)
+	planFailed := subscriptionConditionFalse(v1alpha1.SubscriptionInstallPlanFailed)
+	if sub.Status.Install != nil {
+		ip, err := o.getInstallPlan(sub.GetNamespace(), sub.Status.Install.Name)
+		switch {
+		case err != nil:
+			pending.Reason, pending.Message = v1alpha1.SubscriptionReasonInstallPlanNotFound, err.Error()
+		case ip.Status.Phase == v1alpha1.InstallPlanPhaseFailed:
+			planFailed = subscriptionConditionTrue(v1alpha1.SubscriptionInstallPlanFailed, "", "")
+			for _, cond := range ip.Status.Conditions {
+				if cond.Status == corev1.ConditionFalse {
+					planFailed.Reason, planFailed.Message = v1alpha1.SubscriptionConditionReason(cond.Reason), cond.Message
+				}
+			}
+		case ip.Status.Phase == v1alpha1.InstallPlanPhaseWaiting && ip.Status.Hold != nil:
+			pending = subscriptionConditionTrue(v1alpha1.SubscriptionInstallPlanPending, v1alpha1.SubscriptionConditionReason(ip.Status.Hold.Reason),
+				fmt.Sprintf("InstallPlan %s is waiting: %s", ip.GetName(), ip.Status.Hold.Message))
+		case ip.Status.Phase != v1alpha1.InstallPlanPhaseComplete:
+			pending = subscriptionConditionTrue(v1alpha1.SubscriptionInstallPlanPending, v1alpha1.SubscriptionConditionReason(ip.Status.Phase),
+				fmt.Sprintf("InstallPlan %s is in phase %s", ip.GetName(), ip.Status.Phase))
+		}
+	}
+	sub.Status.SetCondition(pending)
+	sub.Status.SetCondition(planFailed)
+
+	healthy := subscriptionConditionFalse(v1alpha1.SubscriptionHealthy)
+	healthy.Reason = v1alpha1.SubscriptionReasonCSVNotFound
+	csvFailed := subscriptionConditionFalse(v1alpha1.SubscriptionCSVFailed)
+	sub.Status.InstalledCSVPhase, sub.Status.InstalledCSVReason = "", ""
+	if sub.Status.CurrentCSV != "" {
+		csv, err := o.installedCSV(sub)
+		if err == nil {
+			sub.Status.InstalledCSVPhase, sub.Status.InstalledCSVReason = csv.Status.Phase, csv.Status.Reason
+			switch csv.Status.Phase {
+			case v1alpha1.CSVPhaseFailed:
+				csvFailed = subscriptionConditionTrue(v1alpha1.SubscriptionCSVFailed, v1alpha1.SubscriptionConditionReason(csv.Status.Reason), csv.Status.Message)
+				healthy.Reason, healthy.Message = v1alpha1.SubscriptionConditionReason(v1alpha1.SubscriptionCSVFailed), csv.Status.Message
+			case v1alpha1.CSVPhaseSucceeded:
+				healthy = subscriptionConditionTrue(v1alpha1.SubscriptionHealthy, v1alpha1.SubscriptionReasonCSVSucceeded, "")
+			default:
+				healthy.Reason = v1alpha1.SubscriptionReasonCSVNotSucceeded
+				healthy.Message = fmt.Sprintf("CSV %s is in phase %s", csv.GetName(), csv.Status.Phase)
+			}
+		} else {
+			healthy.Message = err.Error()
+		}
+	}
+	sub.Status.SetCondition(csvFailed)
+
+	if healthy.Status == corev1.ConditionTrue {
+		for _, cond := range sub.Status.Conditions {
+			informational := cond.Type == v1alpha1.SubscriptionHealthy || cond.Type == v1alpha1.SubscriptionUpgradeBlocked
+			if !informational && cond.Status == corev1.ConditionTrue {
+				healthy = subscriptionConditionFalse(v1alpha1.SubscriptionHealthy)
+				healthy.Reason, healthy.Message = v1alpha1.SubscriptionConditionReason(cond.Type), cond.Message
+				break
+			}
+		}
+	}
+	sub.Status.SetCondition(healthy)
+}
+
+// installedCSV returns the CSV a Subscription has installed and records its name in InstalledCSVName. That's the CSV
+// named by CurrentCSV once it exists; until then, e.g. while an upgrade's InstallPlan is pending, it's the CSV that was
+// installed before.
+func (o *Operator) installedCSV(sub *v1alpha1.Subscription) (*v1alpha1.ClusterServiceVersion, error) {
+	csv, err := o.getCSV(sub.GetNamespace(), sub.Status.CurrentCSV)
+	if err == nil {
+		sub.Status.InstalledCSVName = csv.GetName()
+		return csv, nil
+	}
+	if !k8serrors.IsNotFound(err) || sub.Status.InstalledCSVName == "" || sub.Status.InstalledCSVName == sub.Status.CurrentCSV {
+		return nil, err
+	}
+	return o.getCSV(sub.GetNamespace(), sub.Status.InstalledCSVName)
+}
+
+// getInstallPlan reads an InstallPlan from the informer's cache, or from the cluster if it isn't cached. Subscriptions
+// are requeued when their InstallPlans change, so a plan missing from the cache is caught up with.
+func (o *Operator) getInstallPlan(namespace, name string) (*v1alpha1.InstallPlan, error) {
+	if lister := o.installPlanLister(namespace); lister != nil {
+		return lister.Get(name)
+	}
+	return o.client.OperatorsV1alpha1().InstallPlans(namespace).Get(name, metav1.GetOptions{})
+}
+
+// getCSV reads a CSV from the informer's cache, or from the cluster if it isn't cached
+func (o *Operator) getCSV(namespace, name string) (*v1alpha1.ClusterServiceVersion, error) {
+	if lister := o.csvLister(namespace); lister != nil {
+		return lister.Get(name)
+	}
+	return o.client.OperatorsV1alpha1().ClusterServiceVersions(namespace).Get(name, metav1.GetOptions{})
+}
+
+// indexSubscriptionByCSV is a cache.IndexFunc that returns the CSVs a Subscription mirrors or is about to mirror
+func indexSubscriptionByCSV(obj interface{}) ([]string, error) {
+	sub, ok := obj.(*v1alpha1.Subscription)
+	if !ok {
+		return nil, fmt.Errorf("casting Subscription failed")
+	}
+	keys := []string{}
+	for _, name := range []string{sub.Status.CurrentCSV, sub.Status.InstalledCSVName} {
+		if name != "" && !containsString(keys, sub.GetNamespace()+"/"+name) {
+			keys = append(keys, sub.GetNamespace()+"/"+name)
+		}
+	}
+	return keys, nil
+}
+
+// requeueSubscriptionsForCSV queues the Subscriptions that mirror a CSV that changed, so that their conditions don't
+// wait for the next resync
+func (o *Operator) requeueSubscriptionsForCSV(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	csv, ok := obj.(*v1alpha1.ClusterServiceVersion)
+	if !ok {
+		return
+	}
+	for _, indexer := range o.subIndexers {
+		subs, err := indexer.ByIndex(csvIndex, csv.GetNamespace()+"/"+csv.GetName())
+		if err != nil {
+			log.Infof("error listing Subscriptions to CSV %s/%s: %s", csv.GetNamespace(), csv.GetName(), err)
+			continue
+		}
+		for _, obj := range subs {
+			k, err := cache.MetaNamespaceKeyFunc(obj)
+			if err != nil {
+				log.Infof("creating key failed: %s", err)
+				continue
+			}
+			o.subQueue.Add(k)
+		}
+	}
+}
+
+// requeueSubscriptionForInstallPlan queues the Subscription that owns an InstallPlan that changed, so that its
+// conditions don't wait for the next resync
+func (o *Operator) requeueSubscriptionForInstallPlan(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	plan, ok := obj.(*v1alpha1.InstallPlan)
+	if !ok {
+		return
+	}
+	for _, ref := range plan.GetOwnerReferences() {
+		if ref.Kind == v1alpha1.SubscriptionKind {
+			o.subQueue.Add(plan.GetNamespace() + "/" + ref.Name)
+		}
+	}
+}
+
+// subscriptionRequeueHandler calls requeue for every event of an informer
+func subscriptionRequeueHandler(requeue func(obj interface{})) cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc:    requeue,
+		UpdateFunc: func(_, obj interface{}) { requeue(obj) },
+		DeleteFunc: requeue,
+	}
+}
+
+func subscriptionConditionTrue(condType v1alpha1.SubscriptionConditionType, reason v1alpha1.SubscriptionConditionReason, message string) v1alpha1.SubscriptionCondition {
+	return v1alpha1.SubscriptionCondition{Type: condType, Status: corev1.ConditionTrue, Reason: reason, Message: message}
+}
+
+func subscriptionConditionFalse(condType v1alpha1.SubscriptionConditionType) v1alpha1.SubscriptionCondition {
+	return v1alpha1.SubscriptionCondition{Type: condType, Status: corev1.ConditionFalse}
+}
+
 func ensureLabels(sub *v1alpha1.Subscription) *v1alpha1.Subscription {
 	labels := sub.GetLabels()
 	if labels == nil {
@@ -7,18 +7,24 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
 	core "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
 
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators/v1alpha1"
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/clientset/versioned/fake"
+	listers "github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/listers/operators/v1alpha1"
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/controller/registry"
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/controller/registry/resolver"
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/fakes"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/ownerutil"
 	"k8s.io/apimachinery/pkg/util/diff"
 )
 
@@ -113,6 +119,22 @@ func TestSyncSubscription(t *testing.T) {
 			}},
 			expected: expected{err: "unknown catalog source flying-unicorns in namespace ns"},
 		},
+		{
+			name:    "invalid input",
+			subName: "catalog source in another tenant namespace",
+			initial: initial{catalogName: "flying-unicorns"},
+			args: args{subscription: &v1alpha1.Subscription{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "fairy-land",
+					Name:      "test-subscription",
+				},
+				Spec: &v1alpha1.SubscriptionSpec{
+					CatalogSource:          "flying-unicorns",
+					CatalogSourceNamespace: "goblin-land",
+				},
+			}},
+			expected: expected{err: "catalog source flying-unicorns in namespace goblin-land is not visible to namespace fairy-land"},
+		},
 		{
 			name:    "no updates",
 			subName: "subscription synced already since last catalog update and at latest CSV",
@@ -283,6 +305,7 @@ func TestSyncSubscription(t *testing.T) {
 						LastUpdated: earliestTime,
 						Install:     nil,
 						State:       v1alpha1.SubscriptionStateUpgradeAvailable,
+						Channel:     "magical",
 					},
 				},
 				err: "",
@@ -330,6 +353,7 @@ func TestSyncSubscription(t *testing.T) {
 						LastUpdated: earliestTime,
 						Install:     nil,
 						State:       v1alpha1.SubscriptionStateUpgradeAvailable,
+						Channel:     "magical",
 					},
 				},
 				err: "",
@@ -816,6 +840,7 @@ func TestSyncSubscription(t *testing.T) {
 						CurrentCSV: "next",
 						Install:    nil,
 						State:      v1alpha1.SubscriptionStateUpgradeAvailable,
+						Channel:    "magical",
 					},
 				},
 			},
@@ -863,6 +888,12 @@ func TestSyncSubscription(t *testing.T) {
 
 			if tt.expected.installPlan != nil {
 				expectedActions = append(expectedActions,
+					core.NewListAction(
+						schema.GroupVersionResource{Group: "operators.coreos.com", Version: "v1alpha1", Resource: "installplans"},
+						schema.GroupVersionKind{Group: "operators.coreos.com", Version: "v1alpha1", Kind: "InstallPlan"},
+						tt.expected.namespace,
+						metav1.ListOptions{},
+					),
 					core.NewCreateAction(
 						schema.GroupVersionResource{Group: "operators.coreos.com", Version: "v1alpha1", Resource: "installplans"},
 						tt.expected.namespace,
@@ -980,3 +1011,661 @@ func TestSyncSubscription(t *testing.T) {
 
 	}
 }
+
+// prunedSource is a catalog a CSV has been pruned from, without updating the CSV that replaces it
+type prunedSource struct {
+	registry.Source
+	pruned string
+}
+
+func (s prunedSource) FindCSVByName(name string) (*v1alpha1.ClusterServiceVersion, error) {
+	if name == s.pruned {
+		return nil, fmt.Errorf("not found: ClusterServiceVersion %s", name)
+	}
+	return s.Source.FindCSVByName(name)
+}
+
+func TestSyncSubscriptionChannelSwitch(t *testing.T) {
+	// alpha is v1 -> v2 -> v3, beta stops at v2, and stable's s1 and s2 can upgrade from alpha's CSVs. pruned is
+	// p2 -> p3 -> p4, but p2 has been pruned from the catalog.
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "catalog", Namespace: "ns"},
+		Data: map[string]string{
+			registry.ConfigMapCSVName: `- metadata:
+    name: v1
+- metadata:
+    name: v2
+  spec:
+    replaces: v1
+- metadata:
+    name: v3
+  spec:
+    replaces: v2
+- metadata:
+    name: s1
+  spec:
+    skips: [v1, v2]
+- metadata:
+    name: s2
+  spec:
+    replaces: s1
+    skips: [v2]
+- metadata:
+    name: p2
+- metadata:
+    name: p3
+  spec:
+    replaces: p2
+    skips: [v3]
+- metadata:
+    name: p4
+  spec:
+    replaces: p3
+`,
+			registry.ConfigMapPackageName: `- packageName: rainbows
+  channels:
+  - name: alpha
+    currentCSV: v3
+  - name: beta
+    currentCSV: v2
+  - name: stable
+    currentCSV: s2
+  - name: pruned
+    currentCSV: p4
+`,
+		},
+	}
+	catalog := registry.NewInMem()
+	loader := registry.NewConfigMapCatalogResourceLoader("ns", nil)
+	require.NoError(t, loader.LoadCatalogResourcesFromConfigMap(catalog, cm))
+	pruned := prunedSource{Source: catalog, pruned: "p2"}
+
+	tests := []struct {
+		description     string
+		installed       string
+		from, to        string
+		expectedCSV     string
+		expectedChannel string
+		expectedState   v1alpha1.SubscriptionState
+		expectedSwitch  *v1alpha1.SubscriptionChannelSwitch
+		expectedErr     string
+	}{
+		{
+			description:     "LowestSkippingCSV",
+			installed:       "v2",
+			from:            "alpha",
+			to:              "stable",
+			expectedCSV:     "s1",
+			expectedChannel: "stable",
+			expectedState:   v1alpha1.SubscriptionStateUpgradeAvailable,
+			expectedSwitch:  &v1alpha1.SubscriptionChannelSwitch{From: "alpha", To: "stable", FromCSV: "v2", ToCSV: "s1"},
+		},
+		{
+			description:     "ReplacingCSV",
+			installed:       "v1",
+			from:            "alpha",
+			to:              "beta",
+			expectedCSV:     "v2",
+			expectedChannel: "beta",
+			expectedState:   v1alpha1.SubscriptionStateUpgradeAvailable,
+			expectedSwitch:  &v1alpha1.SubscriptionChannelSwitch{From: "alpha", To: "beta", FromCSV: "v1", ToCSV: "v2"},
+		},
+		{
+			description:     "AtHead",
+			installed:       "v2",
+			from:            "alpha",
+			to:              "beta",
+			expectedCSV:     "v2",
+			expectedChannel: "beta",
+			expectedState:   v1alpha1.SubscriptionStateAtLatest,
+			expectedSwitch:  &v1alpha1.SubscriptionChannelSwitch{From: "alpha", To: "beta", FromCSV: "v2", ToCSV: "v2"},
+		},
+		{
+			description:     "NoUpgradeEdge",
+			installed:       "v3",
+			from:            "alpha",
+			to:              "stable",
+			expectedCSV:     "v3",
+			expectedChannel: "alpha",
+			expectedState:   v1alpha1.SubscriptionStateAtLatest,
+			expectedSwitch: &v1alpha1.SubscriptionChannelSwitch{From: "alpha", To: "stable", FromCSV: "v3",
+				Message: "no upgrade edge from v3: no CSV in channel stable replaces or skips it"},
+			expectedErr: "failed to switch from channel alpha to stable: no upgrade edge from v3: no CSV in channel stable replaces or skips it",
+		},
+		{
+			description:     "NoDowngrade",
+			installed:       "v3",
+			from:            "alpha",
+			to:              "beta",
+			expectedCSV:     "v3",
+			expectedChannel: "alpha",
+			expectedState:   v1alpha1.SubscriptionStateAtLatest,
+			expectedSwitch: &v1alpha1.SubscriptionChannelSwitch{From: "alpha", To: "beta", FromCSV: "v3",
+				Message: "no upgrade edge from v3: no CSV in channel beta replaces or skips it"},
+			expectedErr: "failed to switch from channel alpha to beta: no upgrade edge from v3: no CSV in channel beta replaces or skips it",
+		},
+		{
+			description:     "PrunedChannel",
+			installed:       "v3",
+			from:            "alpha",
+			to:              "pruned",
+			expectedCSV:     "p3",
+			expectedChannel: "pruned",
+			expectedState:   v1alpha1.SubscriptionStateUpgradeAvailable,
+			expectedSwitch:  &v1alpha1.SubscriptionChannelSwitch{From: "alpha", To: "pruned", FromCSV: "v3", ToCSV: "p3"},
+		},
+		{
+			description:     "NoUpgradeEdgeInPrunedChannel",
+			installed:       "v2",
+			from:            "alpha",
+			to:              "pruned",
+			expectedCSV:     "v2",
+			expectedChannel: "alpha",
+			expectedState:   v1alpha1.SubscriptionStateAtLatest,
+			expectedSwitch: &v1alpha1.SubscriptionChannelSwitch{From: "alpha", To: "pruned", FromCSV: "v2",
+				Message: "no upgrade edge from v2: no CSV in channel pruned replaces or skips it"},
+			expectedErr: "failed to switch from channel alpha to pruned: no upgrade edge from v2: no CSV in channel pruned replaces or skips it",
+		},
+		{
+			description:     "UnknownChannel",
+			installed:       "v3",
+			from:            "alpha",
+			to:              "missing",
+			expectedCSV:     "v3",
+			expectedChannel: "alpha",
+			expectedState:   v1alpha1.SubscriptionStateAtLatest,
+			expectedSwitch: &v1alpha1.SubscriptionChannelSwitch{From: "alpha", To: "missing", FromCSV: "v3",
+				Message: "Unknown channel missing in package rainbows"},
+			expectedErr: "failed to switch from channel alpha to missing: Unknown channel missing in package rainbows",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			clientFake := fake.NewSimpleClientset(&v1alpha1.ClusterServiceVersion{
+				ObjectMeta: metav1.ObjectMeta{Name: tt.installed, Namespace: "fairy-land"},
+			})
+			op := &Operator{
+				client:    clientFake,
+				namespace: "ns",
+				sources: map[registry.SourceKey]registry.Source{
+					{Name: "flying-unicorns", Namespace: "ns"}: pruned,
+				},
+			}
+
+			// a Subscription at the latest CSV of its old channel is synced again once its channel changes
+			sub := &v1alpha1.Subscription{
+				ObjectMeta: metav1.ObjectMeta{Name: "sub", Namespace: "fairy-land"},
+				Spec:       &v1alpha1.SubscriptionSpec{CatalogSource: "flying-unicorns", Package: "rainbows", Channel: tt.to},
+				Status: v1alpha1.SubscriptionStatus{
+					CurrentCSV:  tt.installed,
+					Channel:     tt.from,
+					State:       v1alpha1.SubscriptionStateAtLatest,
+					LastUpdated: metav1.Now(),
+				},
+			}
+			synced, err := op.syncSubscription(sub)
+			if tt.expectedErr != "" {
+				require.EqualError(t, err, tt.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.expectedCSV, synced.Status.CurrentCSV)
+			require.Equal(t, tt.expectedChannel, synced.Status.Channel)
+			require.Equal(t, tt.expectedState, synced.Status.State)
+			require.Equal(t, tt.expectedSwitch, synced.Status.ChannelSwitch)
+		})
+	}
+}
+
+func TestSyncSubscriptionVersionRange(t *testing.T) {
+	// stable is r.v1 at 0.9.0 -> r.v2 at 0.9.5 -> r.v3 at 1.0.0
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "catalog", Namespace: "ns"},
+		Data: map[string]string{
+			registry.ConfigMapCSVName: `- metadata:
+    name: r.v1
+  spec:
+    version: 0.9.0
+- metadata:
+    name: r.v2
+  spec:
+    version: 0.9.5
+    replaces: r.v1
+- metadata:
+    name: r.v3
+  spec:
+    version: 1.0.0
+    replaces: r.v2
+`,
+			registry.ConfigMapPackageName: `- packageName: rainbows
+  channels:
+  - name: stable
+    currentCSV: r.v3
+`,
+		},
+	}
+	catalog := registry.NewInMem()
+	loader := registry.NewConfigMapCatalogResourceLoader("ns", nil)
+	require.NoError(t, loader.LoadCatalogResourcesFromConfigMap(catalog, cm))
+
+	tests := []struct {
+		description     string
+		installed       string
+		blocked         string
+		versionRange    string
+		expectedCSV     string
+		expectedState   v1alpha1.SubscriptionState
+		expectedBlocked string
+		expectedVersion string
+		expectedErr     string
+	}{
+		{
+			description:     "InstallLatestInRange",
+			versionRange:    "0.9.x",
+			expectedCSV:     "r.v2",
+			expectedState:   v1alpha1.SubscriptionStateUpgradeAvailable,
+			expectedBlocked: "r.v3",
+			expectedVersion: "1.0.0",
+		},
+		{
+			description:   "UpgradeInRange",
+			installed:     "r.v1",
+			versionRange:  "0.9.x",
+			expectedCSV:   "r.v2",
+			expectedState: v1alpha1.SubscriptionStateUpgradeAvailable,
+		},
+		{
+			description:     "UpgradeOutsideRange",
+			installed:       "r.v2",
+			versionRange:    "<1.0.0",
+			expectedCSV:     "r.v2",
+			expectedState:   v1alpha1.SubscriptionStateAtLatest,
+			expectedBlocked: "r.v3",
+			expectedVersion: "1.0.0",
+		},
+		{
+			// a blocked Subscription is synced again without a catalog update, so widening its range unblocks it
+			description:   "RangeWidened",
+			installed:     "r.v2",
+			blocked:       "r.v3",
+			versionRange:  "<=1.0.0",
+			expectedCSV:   "r.v3",
+			expectedState: v1alpha1.SubscriptionStateUpgradeAvailable,
+		},
+		{
+			description:  "NoVersionInRange",
+			versionRange: "<0.9.0",
+			expectedErr:  "failed to find CSV for package rainbows in channel stable: no CSV satisfies version range <0.9.0",
+		},
+		{
+			description:  "InvalidRange",
+			installed:    "r.v2",
+			versionRange: "1.a",
+			expectedCSV:  "r.v2",
+			expectedErr:  `invalid version range "1.a": invalid version "1.a"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			clientFake := fake.NewSimpleClientset(&v1alpha1.ClusterServiceVersion{
+				ObjectMeta: metav1.ObjectMeta{Name: tt.installed, Namespace: "fairy-land"},
+			})
+			op := &Operator{
+				client:    clientFake,
+				namespace: "ns",
+				sources: map[registry.SourceKey]registry.Source{
+					{Name: "flying-unicorns", Namespace: "ns"}: catalog,
+				},
+			}
+
+			sub := &v1alpha1.Subscription{
+				ObjectMeta: metav1.ObjectMeta{Name: "sub", Namespace: "fairy-land"},
+				Spec: &v1alpha1.SubscriptionSpec{
+					CatalogSource: "flying-unicorns",
+					Package:       "rainbows",
+					Channel:       "stable",
+					VersionRange:  tt.versionRange,
+				},
+			}
+			if tt.installed != "" {
+				sub.Status = v1alpha1.SubscriptionStatus{
+					CurrentCSV:  tt.installed,
+					Channel:     "stable",
+					State:       v1alpha1.SubscriptionStateUpgradePending,
+					LastUpdated: metav1.Now(),
+				}
+			}
+			if tt.blocked != "" {
+				sub.Status.State = v1alpha1.SubscriptionStateAtLatest
+				sub.Status.BlockedCSV = tt.blocked
+			}
+			synced, err := op.syncSubscription(sub)
+			if tt.expectedErr != "" {
+				require.EqualError(t, err, tt.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.expectedCSV, synced.Status.CurrentCSV)
+			require.Equal(t, tt.expectedBlocked, synced.Status.BlockedCSV)
+			require.Equal(t, tt.expectedVersion, synced.Status.BlockedVersion)
+			if tt.expectedErr == "" {
+				require.Equal(t, tt.expectedState, synced.Status.State)
+			}
+		})
+	}
+}
+
+func TestSyncSubscriptionExistingInstallPlan(t *testing.T) {
+	sub := &v1alpha1.Subscription{
+		ObjectMeta: metav1.ObjectMeta{Name: "sub", Namespace: "ns", UID: "sub-uid"},
+		Spec:       &v1alpha1.SubscriptionSpec{CatalogSource: "flying-unicorns", Package: "rainbows", Channel: "magical"},
+		Status:     v1alpha1.SubscriptionStatus{CurrentCSV: "rainbows.v1", State: v1alpha1.SubscriptionStateUpgradeAvailable},
+	}
+	// the plan was created by a sync whose status isn't in the informer's cache yet
+	plan := &v1alpha1.InstallPlan{
+		ObjectMeta: metav1.ObjectMeta{Name: "install-rainbows.v1-abcde", Namespace: "ns", UID: "plan-uid"},
+		Spec:       v1alpha1.InstallPlanSpec{ClusterServiceVersionNames: []string{"rainbows.v1"}},
+	}
+	ownerutil.AddNonBlockingOwner(plan, sub)
+	clientFake := fake.NewSimpleClientset(sub, plan)
+
+	op := &Operator{
+		client:    clientFake,
+		namespace: "ns",
+		sources: map[registry.SourceKey]registry.Source{
+			{Name: "flying-unicorns", Namespace: "ns"}: registry.NewInMem(),
+		},
+	}
+
+	out, err := op.syncSubscription(sub.DeepCopy())
+	require.NoError(t, err)
+	require.EqualValues(t, v1alpha1.SubscriptionStateUpgradePending, out.Status.State)
+	require.NotNil(t, out.Status.Install)
+	require.Equal(t, plan.GetName(), out.Status.Install.Name)
+	require.Equal(t, plan.GetUID(), out.Status.Install.UID)
+
+	plans, err := clientFake.OperatorsV1alpha1().InstallPlans("ns").List(metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, plans.Items, 1)
+}
+
+func TestSyncSubscriptionsSkipsUnchangedCatalogs(t *testing.T) {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "catalog", Namespace: "ns"},
+		Data: map[string]string{
+			registry.ConfigMapCSVName: `- metadata:
+    name: rainbows.v1
+`,
+			registry.ConfigMapPackageName: `- packageName: rainbows
+  channels:
+  - name: magical
+    currentCSV: rainbows.v1
+`,
+		},
+	}
+	catalog := registry.NewInMem()
+	loader := registry.NewConfigMapCatalogResourceLoader("ns", nil)
+	require.NoError(t, loader.LoadCatalogResourcesFromConfigMap(catalog, cm))
+
+	sub := &v1alpha1.Subscription{
+		ObjectMeta: metav1.ObjectMeta{Name: "sub", Namespace: "ns"},
+		Spec:       &v1alpha1.SubscriptionSpec{CatalogSource: "flying-unicorns", Package: "rainbows", Channel: "magical"},
+		Status:     v1alpha1.SubscriptionStatus{CurrentCSV: "rainbows.v1", State: v1alpha1.SubscriptionStateAtLatest},
+	}
+	csv := &v1alpha1.ClusterServiceVersion{
+		ObjectMeta: metav1.ObjectMeta{Name: "rainbows.v1", Namespace: "ns"},
+		Status:     v1alpha1.ClusterServiceVersionStatus{Phase: v1alpha1.CSVPhaseSucceeded},
+	}
+	clientFake := fake.NewSimpleClientset(sub, csv)
+	fakeClock := clock.NewFakeClock(time.Unix(10, 0))
+	op := &Operator{
+		client:    clientFake,
+		namespace: "ns",
+		clock:     fakeClock,
+		sources: map[registry.SourceKey]registry.Source{
+			{Name: "flying-unicorns", Namespace: "ns"}: catalog,
+		},
+		sourcesLastUpdate: metav1.Unix(5, 0),
+		subscriptions:     map[registry.SubscriptionKey]v1alpha1.Subscription{},
+	}
+	stored := func() *v1alpha1.Subscription {
+		out, err := clientFake.OperatorsV1alpha1().Subscriptions("ns").Get("sub", metav1.GetOptions{})
+		require.NoError(t, err)
+		return out
+	}
+
+	// the Subscription is already at the latest CSV of its channel, which the catalog reports as an error
+	require.Error(t, op.syncSubscriptions(stored()))
+	require.Equal(t, int64(10), stored().Status.LastUpdated.Unix())
+
+	// a catalog change that doesn't affect the Subscription still records that it's been seen
+	op.sourcesLastUpdate = metav1.Unix(15, 0)
+	fakeClock.SetTime(time.Unix(20, 0))
+	require.Error(t, op.syncSubscriptions(stored()))
+	require.Equal(t, int64(20), stored().Status.LastUpdated.Unix())
+
+	// so without another catalog change, the next sync is skipped
+	out, err := op.syncSubscription(stored())
+	require.NoError(t, err)
+	require.Nil(t, out)
+}
+
+func TestSetSubscriptionConditions(t *testing.T) {
+	ip := func(phase v1alpha1.InstallPlanPhase, conditions ...v1alpha1.InstallPlanCondition) *v1alpha1.InstallPlan {
+		return &v1alpha1.InstallPlan{
+			ObjectMeta: metav1.ObjectMeta{Name: "install", Namespace: "ns"},
+			Status:     v1alpha1.InstallPlanStatus{Phase: phase, Conditions: conditions},
+		}
+	}
+	csvNamed := func(name string, phase v1alpha1.ClusterServiceVersionPhase, reason v1alpha1.ConditionReason) *v1alpha1.ClusterServiceVersion {
+		return &v1alpha1.ClusterServiceVersion{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"},
+			Status:     v1alpha1.ClusterServiceVersionStatus{Phase: phase, Reason: reason, Message: "message"},
+		}
+	}
+	csv := func(phase v1alpha1.ClusterServiceVersionPhase, reason v1alpha1.ConditionReason) *v1alpha1.ClusterServiceVersion {
+		return csvNamed("rainbows.v1", phase, reason)
+	}
+
+	tests := []struct {
+		description    string
+		existing       []runtime.Object
+		syncErr        error
+		blocked        string
+		installed      string
+		expectedCSV    string
+		expectedPhase  v1alpha1.ClusterServiceVersionPhase
+		expectedReason v1alpha1.ConditionReason
+		// expected maps the conditions expected true to their reasons; every other condition is expected false
+		expected map[v1alpha1.SubscriptionConditionType]v1alpha1.SubscriptionConditionReason
+	}{
+		{
+			description:    "Healthy",
+			existing:       []runtime.Object{ip(v1alpha1.InstallPlanPhaseComplete), csv(v1alpha1.CSVPhaseSucceeded, v1alpha1.CSVReasonInstallSuccessful)},
+			syncErr:        errors.New("failed to lookup replacement CSV for rainbows.v1: not found"),
+			expectedCSV:    "rainbows.v1",
+			expectedPhase:  v1alpha1.CSVPhaseSucceeded,
+			expectedReason: v1alpha1.CSVReasonInstallSuccessful,
+			expected: map[v1alpha1.SubscriptionConditionType]v1alpha1.SubscriptionConditionReason{
+				v1alpha1.SubscriptionHealthy: v1alpha1.SubscriptionReasonCSVSucceeded,
+			},
+		},
+		{
+			// a blocked upgrade doesn't make the Subscription unhealthy
+			description:    "UpgradeBlocked",
+			existing:       []runtime.Object{ip(v1alpha1.InstallPlanPhaseComplete), csv(v1alpha1.CSVPhaseSucceeded, v1alpha1.CSVReasonInstallSuccessful)},
+			blocked:        "rainbows.v2",
+			expectedCSV:    "rainbows.v1",
+			expectedPhase:  v1alpha1.CSVPhaseSucceeded,
+			expectedReason: v1alpha1.CSVReasonInstallSuccessful,
+			expected: map[v1alpha1.SubscriptionConditionType]v1alpha1.SubscriptionConditionReason{
+				v1alpha1.SubscriptionUpgradeBlocked: v1alpha1.SubscriptionReasonOutsideVersionRange,
+				v1alpha1.SubscriptionHealthy:        v1alpha1.SubscriptionReasonCSVSucceeded,
+			},
+		},
+		{
+			description: "CatalogSourceUnavailable",
+			existing:    []runtime.Object{ip(v1alpha1.InstallPlanPhaseComplete), csv(v1alpha1.CSVPhaseSucceeded, v1alpha1.CSVReasonInstallSuccessful)},
+			syncErr: subscriptionError{
+				condition: v1alpha1.SubscriptionCatalogSourceUnavailable,
+				reason:    v1alpha1.SubscriptionReasonCatalogSourceNotFound,
+				err:       errors.New("unknown catalog source flying-unicorns in namespace ns"),
+			},
+			expectedCSV:    "rainbows.v1",
+			expectedPhase:  v1alpha1.CSVPhaseSucceeded,
+			expectedReason: v1alpha1.CSVReasonInstallSuccessful,
+			expected: map[v1alpha1.SubscriptionConditionType]v1alpha1.SubscriptionConditionReason{
+				v1alpha1.SubscriptionCatalogSourceUnavailable: v1alpha1.SubscriptionReasonCatalogSourceNotFound,
+			},
+		},
+		{
+			description: "ResolutionFailed",
+			syncErr: subscriptionError{
+				condition: v1alpha1.SubscriptionResolutionFailed,
+				reason:    v1alpha1.SubscriptionReasonPackageNotFound,
+				err:       errors.New("failed to find CSV for package rainbows in channel magical: not found"),
+			},
+			expected: map[v1alpha1.SubscriptionConditionType]v1alpha1.SubscriptionConditionReason{
+				v1alpha1.SubscriptionResolutionFailed: v1alpha1.SubscriptionReasonPackageNotFound,
+			},
+		},
+		{
+			description: "InstallPlanPending",
+			existing:    []runtime.Object{ip(v1alpha1.InstallPlanPhaseRequiresApproval)},
+			expected: map[v1alpha1.SubscriptionConditionType]v1alpha1.SubscriptionConditionReason{
+				v1alpha1.SubscriptionInstallPlanPending: "RequiresApproval",
+			},
+		},
+		{
+			description: "InstallPlanFailed",
+			existing: []runtime.Object{ip(v1alpha1.InstallPlanPhaseFailed, v1alpha1.ConditionFailed(v1alpha1.InstallPlanInstalled,
+				v1alpha1.InstallPlanReasonInsufficientPermissions, errors.New("forbidden")))},
+			expected: map[v1alpha1.SubscriptionConditionType]v1alpha1.SubscriptionConditionReason{
+				v1alpha1.SubscriptionInstallPlanFailed: "InsufficientPermissions",
+			},
+		},
+		{
+			description:    "CSVFailed",
+			existing:       []runtime.Object{ip(v1alpha1.InstallPlanPhaseComplete), csv(v1alpha1.CSVPhaseFailed, v1alpha1.CSVReasonComponentUnhealthy)},
+			expectedCSV:    "rainbows.v1",
+			expectedPhase:  v1alpha1.CSVPhaseFailed,
+			expectedReason: v1alpha1.CSVReasonComponentUnhealthy,
+			expected: map[v1alpha1.SubscriptionConditionType]v1alpha1.SubscriptionConditionReason{
+				v1alpha1.SubscriptionCSVFailed: "ComponentUnhealthy",
+			},
+		},
+		{
+			// the upgrade's CSV doesn't exist yet, so the CSV being upgraded from is still mirrored
+			description:    "UpgradePending",
+			existing:       []runtime.Object{ip(v1alpha1.InstallPlanPhaseInstalling), csvNamed("rainbows.v0", v1alpha1.CSVPhaseSucceeded, v1alpha1.CSVReasonInstallSuccessful)},
+			installed:      "rainbows.v0",
+			expectedCSV:    "rainbows.v0",
+			expectedPhase:  v1alpha1.CSVPhaseSucceeded,
+			expectedReason: v1alpha1.CSVReasonInstallSuccessful,
+			expected: map[v1alpha1.SubscriptionConditionType]v1alpha1.SubscriptionConditionReason{
+				v1alpha1.SubscriptionInstallPlanPending: "Installing",
+			},
+		},
+		{
+			description:    "UpgradeInstalled",
+			existing:       []runtime.Object{ip(v1alpha1.InstallPlanPhaseComplete), csvNamed("rainbows.v0", v1alpha1.CSVPhaseReplacing, v1alpha1.CSVReasonBeingReplaced), csv(v1alpha1.CSVPhaseInstalling, v1alpha1.CSVReasonInstallSuccessful)},
+			installed:      "rainbows.v0",
+			expectedCSV:    "rainbows.v1",
+			expectedPhase:  v1alpha1.CSVPhaseInstalling,
+			expectedReason: v1alpha1.CSVReasonInstallSuccessful,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			// InstallPlans and CSVs are read from the informers' caches rather than the cluster
+			ipIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			csvIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			for _, obj := range tt.existing {
+				switch obj.(type) {
+				case *v1alpha1.InstallPlan:
+					require.NoError(t, ipIndexer.Add(obj))
+				case *v1alpha1.ClusterServiceVersion:
+					require.NoError(t, csvIndexer.Add(obj))
+				}
+			}
+			op := &Operator{
+				client:     fake.NewSimpleClientset(),
+				namespace:  "ns",
+				ipListers:  map[string]listers.InstallPlanLister{metav1.NamespaceAll: listers.NewInstallPlanLister(ipIndexer)},
+				csvListers: map[string]listers.ClusterServiceVersionLister{metav1.NamespaceAll: listers.NewClusterServiceVersionLister(csvIndexer)},
+			}
+			sub := &v1alpha1.Subscription{
+				ObjectMeta: metav1.ObjectMeta{Name: "sub", Namespace: "ns"},
+				Spec:       &v1alpha1.SubscriptionSpec{CatalogSource: "flying-unicorns", Package: "rainbows", Channel: "magical"},
+				Status: v1alpha1.SubscriptionStatus{
+					CurrentCSV:       "rainbows.v1",
+					Install:          &v1alpha1.InstallPlanReference{Name: "install"},
+					BlockedCSV:       tt.blocked,
+					InstalledCSVName: tt.installed,
+				},
+			}
+
+			op.setSubscriptionConditions(sub, tt.syncErr)
+			require.Equal(t, tt.expectedCSV, sub.Status.InstalledCSVName)
+			require.Equal(t, tt.expectedPhase, sub.Status.InstalledCSVPhase)
+			require.Equal(t, tt.expectedReason, sub.Status.InstalledCSVReason)
+			require.Len(t, sub.Status.Conditions, 7)
+			for _, cond := range sub.Status.Conditions {
+				reason, ok := tt.expected[cond.Type]
+				if !ok {
+					require.Equal(t, corev1.ConditionFalse, cond.Status, "condition %s", cond.Type)
+					continue
+				}
+				require.Equal(t, corev1.ConditionTrue, cond.Status, "condition %s", cond.Type)
+				require.Equal(t, reason, cond.Reason, "condition %s", cond.Type)
+			}
+
+			// conditions that don't change keep their transition time
+			transitioned := sub.Status.Conditions[0].LastTransitionTime
+			op.setSubscriptionConditions(sub, tt.syncErr)
+			require.Len(t, sub.Status.Conditions, 7)
+			require.Equal(t, transitioned, sub.Status.Conditions[0].LastTransitionTime)
+		})
+	}
+}
+
+func TestRequeueSubscriptions(t *testing.T) {
+	op := &Operator{subQueue: workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())}
+	defer op.subQueue.ShutDown()
+
+	subIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{csvIndex: indexSubscriptionByCSV})
+	for _, sub := range []*v1alpha1.Subscription{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "installed", Namespace: "ns"},
+			Status:     v1alpha1.SubscriptionStatus{CurrentCSV: "rainbows.v1", InstalledCSVName: "rainbows.v1"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "upgrading", Namespace: "ns"},
+			Status:     v1alpha1.SubscriptionStatus{CurrentCSV: "rainbows.v2", InstalledCSVName: "rainbows.v1"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "installed", Namespace: "other"},
+			Status:     v1alpha1.SubscriptionStatus{CurrentCSV: "rainbows.v1", InstalledCSVName: "rainbows.v1"},
+		},
+	} {
+		require.NoError(t, subIndexer.Add(sub))
+	}
+	op.subIndexers = []cache.Indexer{subIndexer}
+
+	csv := func(name string) *v1alpha1.ClusterServiceVersion {
+		return &v1alpha1.ClusterServiceVersion{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"}}
+	}
+	op.requeueSubscriptionsForCSV(csv("rainbows.v1"))
+	require.Equal(t, []string{"ns/installed", "ns/upgrading"}, drain(op.subQueue))
+	op.requeueSubscriptionsForCSV(cache.DeletedFinalStateUnknown{Key: "ns/rainbows.v2", Obj: csv("rainbows.v2")})
+	require.Equal(t, []string{"ns/upgrading"}, drain(op.subQueue))
+	op.requeueSubscriptionsForCSV(csv("rainbows.v3"))
+	require.Empty(t, drain(op.subQueue))
+
+	plan := &v1alpha1.InstallPlan{ObjectMeta: metav1.ObjectMeta{Name: "install", Namespace: "ns"}}
+	op.requeueSubscriptionForInstallPlan(plan)
+	require.Empty(t, drain(op.subQueue))
+	ownerutil.AddNonBlockingOwner(plan, &v1alpha1.Subscription{ObjectMeta: metav1.ObjectMeta{Name: "upgrading", Namespace: "ns"}})
+	op.requeueSubscriptionForInstallPlan(plan)
+	require.Equal(t, []string{"ns/upgrading"}, drain(op.subQueue))
+}
@@ -0,0 +1,356 @@
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators/v1alpha1"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/statusutil"
+)
+
+// uninstallRecheckInterval is how often an uninstall waiting for resources to be deleted is checked again
+const uninstallRecheckInterval = 30 * time.Second
+
+// syncUninstallFinalizer adds the uninstall finalizer to a Subscription whose uninstall policy removes anything, and
+// removes it from one whose policy orphans. Returns true if the Subscription was updated, which syncs it again.
+func (o *Operator) syncUninstallFinalizer(sub *v1alpha1.Subscription) (bool, error) {
+	want := sub.GetUninstallPolicy() != v1alpha1.UninstallPolicyOrphan
+	if hasUninstallFinalizer(sub) == want {
+		return false, nil
+	}
+	return true, o.setUninstallFinalizer(sub, want)
+}
+
+// uninstallSubscription carries out the uninstall policy of a deleted Subscription, recording its progress in the
+// Subscription's status, and lets the deletion finish once it's done
+func (o *Operator) uninstallSubscription(sub *v1alpha1.Subscription) error {
+	if !hasUninstallFinalizer(sub) {
+		return nil
+	}
+	logger := log.WithFields(log.Fields{
+		"sub":       sub.GetName(),
+		"namespace": sub.GetNamespace(),
+	})
+
+	out := sub.DeepCopy()
+	var err error
+	if out.Status.Uninstall == nil {
+		out.Status.Uninstall, err = o.startUninstall(out)
+	}
+	done := false
+	if err == nil {
+		done, err = o.uninstall(out.GetNamespace(), out.Status.Uninstall)
+	}
+	if err != nil {
+		logger = logger.WithField("uninstallError", err)
+	}
+
+	latest := sub
+	if out.Status.Uninstall != nil {
+		out.Status.LastUpdated = o.now()
+		updated, updateErr := statusutil.UpdateSubscriptionStatus(o.client, sub, out, metav1.Time{})
+		if updateErr != nil && !k8serrors.IsNotFound(updateErr) {
+			logger.WithField("updateError", updateErr.Error()).Info("error updating Subscription status")
+			if err == nil {
+				return errors.New("error updating Subscription status: " + updateErr.Error())
+			}
+		}
+		if updated != nil {
+			latest = updated
+		}
+	}
+	if err != nil {
+		logger.Info("error uninstalling Subscription")
+		return err
+	}
+	if !done {
+		logger.Infof("uninstall waiting: %s", out.Status.Uninstall.Message)
+		o.requeueSubscription(sub, uninstallRecheckInterval)
+		return nil
+	}
+
+	logger.Info("uninstalled")
+	if err := o.setUninstallFinalizer(latest, false); err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// startUninstall records what a deleted Subscription's uninstall policy removes
+func (o *Operator) startUninstall(sub *v1alpha1.Subscription) (*v1alpha1.SubscriptionUninstall, error) {
+	u := &v1alpha1.SubscriptionUninstall{Policy: sub.GetUninstallPolicy()}
+	switch u.Policy {
+	case v1alpha1.UninstallPolicyOperator:
+		u.Phase = v1alpha1.SubscriptionUninstallRemovingOperator
+	case v1alpha1.UninstallPolicyAll:
+		u.Phase = v1alpha1.SubscriptionUninstallWaitingForCustomResources
+	default:
+		u.Phase = v1alpha1.SubscriptionUninstallComplete
+		return u, nil
+	}
+
+	// the CSVs are read before they're removed, since their owned CRDs are removed after them
+	csvs, err := o.replacementChain(sub)
+	if err != nil {
+		return nil, err
+	}
+	crds := map[string]struct{}{}
+	for _, csv := range csvs {
+		u.ClusterServiceVersions = append(u.ClusterServiceVersions, csv.GetName())
+		if u.Policy != v1alpha1.UninstallPolicyAll {
+			continue
+		}
+		for _, crd := range csv.Spec.CustomResourceDefinitions.Owned {
+			if _, ok := crds[crd.Name]; !ok {
+				crds[crd.Name] = struct{}{}
+				u.CustomResourceDefinitions = append(u.CustomResourceDefinitions, crd.Name)
+			}
+		}
+	}
+	return u, nil
+}
+
+// replacementChain returns the CSVs in a Subscription's namespace that replace its current CSV, the current CSV, and
+// the CSVs it replaces, directly or through others, newest first
+func (o *Operator) replacementChain(sub *v1alpha1.Subscription) ([]v1alpha1.ClusterServiceVersion, error) {
+	current := sub.Status.CurrentCSV
+	if current == "" {
+		return nil, nil
+	}
+	list, err := o.client.OperatorsV1alpha1().ClusterServiceVersions(sub.GetNamespace()).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing CSVs: %s", err)
+	}
+	byName := map[string]*v1alpha1.ClusterServiceVersion{}
+	replacedBy := map[string]*v1alpha1.ClusterServiceVersion{}
+	for i := range list.Items {
+		csv := &list.Items[i]
+		byName[csv.GetName()] = csv
+		if csv.Spec.Replaces != "" {
+			replacedBy[csv.Spec.Replaces] = csv
+		}
+	}
+
+	chain := []v1alpha1.ClusterServiceVersion{}
+	seen := map[string]bool{current: true}
+	for csv := replacedBy[current]; csv != nil && !seen[csv.GetName()]; csv = replacedBy[csv.GetName()] {
+		seen[csv.GetName()] = true
+		chain = append([]v1alpha1.ClusterServiceVersion{*csv}, chain...)
+	}
+	if csv, ok := byName[current]; ok {
+		chain = append(chain, *csv)
+		for csv = byName[csv.Spec.Replaces]; csv != nil && !seen[csv.GetName()]; csv = byName[csv.Spec.Replaces] {
+			seen[csv.GetName()] = true
+			chain = append(chain, *csv)
+		}
+	}
+	return chain, nil
+}
+
+// uninstall advances an uninstall through its phases. Returns true once it's complete, or false with the uninstall's
+// message set to what it's waiting for.
+func (o *Operator) uninstall(namespace string, u *v1alpha1.SubscriptionUninstall) (bool, error) {
+	for {
+		switch u.Phase {
+		case v1alpha1.SubscriptionUninstallWaitingForCustomResources:
+			remaining, err := o.remainingCustomResources(u.CustomResourceDefinitions)
+			if err != nil {
+				u.Message = err.Error()
+				return false, err
+			}
+			if remaining != "" {
+				u.Message = fmt.Sprintf("waiting for the custom resources of %s to be deleted", remaining)
+				return false, nil
+			}
+			u.Phase = v1alpha1.SubscriptionUninstallRemovingOperator
+		case v1alpha1.SubscriptionUninstallRemovingOperator:
+			remaining, err := o.removeCSVs(namespace, u.ClusterServiceVersions)
+			if err != nil {
+				u.Message = err.Error()
+				return false, err
+			}
+			if len(remaining) > 0 {
+				u.Message = fmt.Sprintf("waiting for CSVs %s to be deleted", strings.Join(remaining, ", "))
+				return false, nil
+			}
+			u.Phase = v1alpha1.SubscriptionUninstallComplete
+			if u.Policy == v1alpha1.UninstallPolicyAll {
+				u.Phase = v1alpha1.SubscriptionUninstallRemovingCustomResourceDefinitions
+			}
+		case v1alpha1.SubscriptionUninstallRemovingCustomResourceDefinitions:
+			if err := o.removeCRDs(namespace, u.ClusterServiceVersions, u.CustomResourceDefinitions); err != nil {
+				u.Message = err.Error()
+				return false, err
+			}
+			u.Phase = v1alpha1.SubscriptionUninstallComplete
+		default:
+			u.Message = ""
+			return true, nil
+		}
+	}
+}
+
+// remainingCustomResources returns the name of the first of the CRDs that has any custom resources left, or "" if none
+// has
+func (o *Operator) remainingCustomResources(crdNames []string) (string, error) {
+	crds := o.OpClient.ApiextensionsV1beta1Interface().ApiextensionsV1beta1().CustomResourceDefinitions()
+	for _, name := range crdNames {
+		crd, err := crds.Get(name, metav1.GetOptions{})
+		if k8serrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("error getting CRD %s: %s", name, err)
+		}
+		version := crd.Spec.Version
+		if version == "" && len(crd.Spec.Versions) > 0 {
+			version = crd.Spec.Versions[0].Name
+		}
+		// one custom resource is enough to keep waiting
+		list, err := o.OpClient.ListCustomResourceInAllNamespaces(crd.Spec.Group, version, crd.Spec.Names.Plural, 1)
+		if err != nil {
+			return "", fmt.Errorf("error listing custom resources of CRD %s: %s", name, err)
+		}
+		if len(list.Items) > 0 {
+			return name, nil
+		}
+	}
+	return "", nil
+}
+
+// removeCSVs deletes CSVs, whose deployments and RBAC are owned by them and garbage collected. Returns the CSVs that
+// aren't gone yet.
+func (o *Operator) removeCSVs(namespace string, names []string) ([]string, error) {
+	remaining := []string{}
+	for _, name := range names {
+		gone, err := o.removeCSV(namespace, name)
+		if err != nil {
+			return nil, err
+		}
+		if !gone {
+			remaining = append(remaining, name)
+		}
+	}
+	return remaining, nil
+}
+
+// removeCSV deletes a CSV. Returns true once the CSV is gone.
+func (o *Operator) removeCSV(namespace, name string) (bool, error) {
+	csvs := o.client.OperatorsV1alpha1().ClusterServiceVersions(namespace)
+	csv, err := csvs.Get(name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error getting CSV %s: %s", name, err)
+	}
+	if csv.GetDeletionTimestamp() != nil {
+		return false, nil
+	}
+	if err := csvs.Delete(name, &metav1.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+		return false, fmt.Errorf("error deleting CSV %s: %s", name, err)
+	}
+	// a CSV without finalizers is gone right away
+	if _, err := csvs.Get(name, metav1.GetOptions{}); !k8serrors.IsNotFound(err) {
+		return false, nil
+	}
+	return true, nil
+}
+
+// removeCRDs deletes the CRDs that were owned by removed CSVs, except for those another CSV in the cluster owns or
+// requires
+func (o *Operator) removeCRDs(namespace string, csvNames, crdNames []string) error {
+	if len(crdNames) == 0 {
+		return nil
+	}
+	removed := map[string]struct{}{}
+	for _, name := range csvNames {
+		removed[name] = struct{}{}
+	}
+	csvs, err := o.client.OperatorsV1alpha1().ClusterServiceVersions(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error listing CSVs: %s", err)
+	}
+	usedElsewhere := map[string]string{}
+	for _, csv := range csvs.Items {
+		if _, ok := removed[csv.GetName()]; ok && csv.GetNamespace() == namespace {
+			continue
+		}
+		for _, crd := range csv.GetAllCRDDescriptions() {
+			usedElsewhere[crd.Name] = csv.GetNamespace() + "/" + csv.GetName()
+		}
+	}
+
+	crds := o.OpClient.ApiextensionsV1beta1Interface().ApiextensionsV1beta1().CustomResourceDefinitions()
+	for _, name := range crdNames {
+		if user, ok := usedElsewhere[name]; ok {
+			log.Infof("not removing CRD %s, which CSV %s also owns or requires", name, user)
+			continue
+		}
+		if err := crds.Delete(name, &metav1.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("error deleting CRD %s: %s", name, err)
+		}
+	}
+	return nil
+}
+
+// setUninstallFinalizer adds or removes the uninstall finalizer of the latest version of a Subscription
+func (o *Operator) setUninstallFinalizer(sub *v1alpha1.Subscription, set bool) error {
+	subs := o.client.OperatorsV1alpha1().Subscriptions(sub.GetNamespace())
+	next := sub.DeepCopy()
+	return retry.RetryOnConflict(statusutil.Backoff, func() error {
+		if next == nil {
+			latest, err := subs.Get(sub.GetName(), metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			next = latest
+		}
+		if hasUninstallFinalizer(next) == set {
+			return nil
+		}
+		finalizers := []string{}
+		for _, f := range next.GetFinalizers() {
+			if f != v1alpha1.SubscriptionUninstallFinalizer {
+				finalizers = append(finalizers, f)
+			}
+		}
+		if set {
+			finalizers = append(finalizers, v1alpha1.SubscriptionUninstallFinalizer)
+		}
+		next.SetFinalizers(finalizers)
+		_, err := subs.Update(next)
+		next = nil
+		return err
+	})
+}
+
+func hasUninstallFinalizer(sub *v1alpha1.Subscription) bool {
+	for _, f := range sub.GetFinalizers() {
+		if f == v1alpha1.SubscriptionUninstallFinalizer {
+			return true
+		}
+	}
+	return false
+}
+
+// requeueSubscription syncs a Subscription again after a delay
+func (o *Operator) requeueSubscription(sub *v1alpha1.Subscription, delay time.Duration) {
+	if o.subQueue == nil {
+		return
+	}
+	k, err := cache.MetaNamespaceKeyFunc(sub)
+	if err != nil {
+		log.Infof("creating key failed: %s", err)
+		return
+	}
+	o.subQueue.AddAfter(k, delay)
+}
@@ -0,0 +1,224 @@
+package catalog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators/v1alpha1"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/clientset/versioned/fake"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/operatorclient"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/queueinformer"
+)
+
+func TestSyncSubscriptionUninstall(t *testing.T) {
+	now := metav1.NewTime(time.Date(2018, 7, 7, 12, 0, 0, 0, time.UTC))
+
+	subscription := func(policy v1alpha1.SubscriptionUninstallPolicy, finalizer, deleted bool) *v1alpha1.Subscription {
+		sub := &v1alpha1.Subscription{
+			ObjectMeta: metav1.ObjectMeta{Name: "sub", Namespace: "ns"},
+			Spec:       &v1alpha1.SubscriptionSpec{CatalogSource: "src", Package: "widget", UninstallPolicy: policy},
+			Status:     v1alpha1.SubscriptionStatus{CurrentCSV: "widget.v1"},
+		}
+		if finalizer {
+			sub.SetFinalizers([]string{v1alpha1.SubscriptionUninstallFinalizer})
+		}
+		if deleted {
+			sub.SetDeletionTimestamp(&now)
+		}
+		return sub
+	}
+	csv := func(namespace, name string, owned ...string) *v1alpha1.ClusterServiceVersion {
+		csv := &v1alpha1.ClusterServiceVersion{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+		for _, crd := range owned {
+			csv.Spec.CustomResourceDefinitions.Owned = append(csv.Spec.CustomResourceDefinitions.Owned, v1alpha1.CRDDescription{Name: crd})
+		}
+		return csv
+	}
+	replacing := func(csv *v1alpha1.ClusterServiceVersion, replaces string) *v1alpha1.ClusterServiceVersion {
+		csv.Spec.Replaces = replaces
+		return csv
+	}
+	requiring := func(csv *v1alpha1.ClusterServiceVersion, required ...string) *v1alpha1.ClusterServiceVersion {
+		for _, crd := range required {
+			csv.Spec.CustomResourceDefinitions.Required = append(csv.Spec.CustomResourceDefinitions.Required, v1alpha1.CRDDescription{Name: crd})
+		}
+		return csv
+	}
+	crd := func(plural string) *v1beta1.CustomResourceDefinition {
+		return &v1beta1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: plural + ".example.com"},
+			Spec: v1beta1.CustomResourceDefinitionSpec{
+				Group:   "example.com",
+				Version: "v1",
+				Names:   v1beta1.CustomResourceDefinitionNames{Plural: plural},
+			},
+		}
+	}
+
+	tests := []struct {
+		description       string
+		sub               *v1alpha1.Subscription
+		existing          []runtime.Object
+		customResources   int
+		expectedFinalizer bool
+		expectedUninstall *v1alpha1.SubscriptionUninstall
+		expectedCSVs      []string
+		expectedCRDs      []string
+	}{
+		{
+			description:       "AddsFinalizer",
+			sub:               subscription(v1alpha1.UninstallPolicyAll, false, false),
+			existing:          []runtime.Object{csv("ns", "widget.v1", "widgets.example.com")},
+			expectedFinalizer: true,
+			expectedCSVs:      []string{"widget.v1"},
+			expectedCRDs:      []string{"widgets.example.com", "gadgets.example.com"},
+		},
+		{
+			description:  "RemovesFinalizerWhenOrphaned",
+			sub:          subscription(v1alpha1.UninstallPolicyOrphan, true, false),
+			existing:     []runtime.Object{csv("ns", "widget.v1", "widgets.example.com")},
+			expectedCSVs: []string{"widget.v1"},
+			expectedCRDs: []string{"widgets.example.com", "gadgets.example.com"},
+		},
+		{
+			description:  "DeletedWithoutFinalizer",
+			sub:          subscription(v1alpha1.UninstallPolicyOrphan, false, true),
+			existing:     []runtime.Object{csv("ns", "widget.v1", "widgets.example.com")},
+			expectedCSVs: []string{"widget.v1"},
+			expectedCRDs: []string{"widgets.example.com", "gadgets.example.com"},
+		},
+		{
+			description:  "RemovesOperator",
+			sub:          subscription(v1alpha1.UninstallPolicyOperator, true, true),
+			existing:     []runtime.Object{csv("ns", "widget.v1", "widgets.example.com")},
+			expectedCSVs: []string{},
+			expectedCRDs: []string{"widgets.example.com", "gadgets.example.com"},
+			expectedUninstall: &v1alpha1.SubscriptionUninstall{
+				Policy:                 v1alpha1.UninstallPolicyOperator,
+				Phase:                  v1alpha1.SubscriptionUninstallComplete,
+				ClusterServiceVersions: []string{"widget.v1"},
+			},
+		},
+		{
+			description:       "WaitsForCustomResources",
+			sub:               subscription(v1alpha1.UninstallPolicyAll, true, true),
+			existing:          []runtime.Object{csv("ns", "widget.v1", "widgets.example.com")},
+			customResources:   2,
+			expectedFinalizer: true,
+			expectedCSVs:      []string{"widget.v1"},
+			expectedCRDs:      []string{"widgets.example.com", "gadgets.example.com"},
+			expectedUninstall: &v1alpha1.SubscriptionUninstall{
+				Policy:                    v1alpha1.UninstallPolicyAll,
+				Phase:                     v1alpha1.SubscriptionUninstallWaitingForCustomResources,
+				ClusterServiceVersions:    []string{"widget.v1"},
+				CustomResourceDefinitions: []string{"widgets.example.com"},
+				Message:                   "waiting for the custom resources of widgets.example.com to be deleted",
+			},
+		},
+		{
+			description: "RemovesAll",
+			sub:         subscription(v1alpha1.UninstallPolicyAll, true, true),
+			existing: []runtime.Object{
+				csv("ns", "widget.v1", "widgets.example.com", "gadgets.example.com"),
+				csv("other", "gadget.v1", "gadgets.example.com"),
+			},
+			expectedCSVs: []string{"gadget.v1"},
+			expectedCRDs: []string{"gadgets.example.com"},
+			expectedUninstall: &v1alpha1.SubscriptionUninstall{
+				Policy:                    v1alpha1.UninstallPolicyAll,
+				Phase:                     v1alpha1.SubscriptionUninstallComplete,
+				ClusterServiceVersions:    []string{"widget.v1"},
+				CustomResourceDefinitions: []string{"widgets.example.com", "gadgets.example.com"},
+			},
+		},
+		{
+			description: "RemovesReplacementChain",
+			sub:         subscription(v1alpha1.UninstallPolicyOperator, true, true),
+			existing: []runtime.Object{
+				replacing(csv("ns", "widget.v2", "widgets.example.com"), "widget.v1"),
+				replacing(csv("ns", "widget.v1", "widgets.example.com"), "widget.v0"),
+				csv("ns", "widget.v0", "widgets.example.com"),
+				csv("ns", "gadget.v1", "gadgets.example.com"),
+			},
+			expectedCSVs: []string{"gadget.v1"},
+			expectedCRDs: []string{"widgets.example.com", "gadgets.example.com"},
+			expectedUninstall: &v1alpha1.SubscriptionUninstall{
+				Policy:                 v1alpha1.UninstallPolicyOperator,
+				Phase:                  v1alpha1.SubscriptionUninstallComplete,
+				ClusterServiceVersions: []string{"widget.v2", "widget.v1", "widget.v0"},
+			},
+		},
+		{
+			description: "KeepsRequiredCRDs",
+			sub:         subscription(v1alpha1.UninstallPolicyAll, true, true),
+			existing: []runtime.Object{
+				csv("ns", "widget.v1", "widgets.example.com", "gadgets.example.com"),
+				requiring(csv("other", "gadget-user.v1"), "gadgets.example.com"),
+			},
+			expectedCSVs: []string{"gadget-user.v1"},
+			expectedCRDs: []string{"gadgets.example.com"},
+			expectedUninstall: &v1alpha1.SubscriptionUninstall{
+				Policy:                    v1alpha1.UninstallPolicyAll,
+				Phase:                     v1alpha1.SubscriptionUninstallComplete,
+				ClusterServiceVersions:    []string{"widget.v1"},
+				CustomResourceDefinitions: []string{"widgets.example.com", "gadgets.example.com"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			extClient := apiextensionsfake.NewSimpleClientset(crd("widgets"), crd("gadgets"))
+			mockClient := operatorclient.NewMockClientInterface(ctrl)
+			mockClient.EXPECT().ApiextensionsV1beta1Interface().Return(extClient).AnyTimes()
+			customResources := &operatorclient.CustomResourceList{}
+			for i := 0; i < tt.customResources; i++ {
+				customResources.Items = append(customResources.Items, &unstructured.Unstructured{})
+			}
+			mockClient.EXPECT().ListCustomResourceInAllNamespaces(gomock.Any(), gomock.Any(), gomock.Any(), int64(1)).Return(customResources, nil).AnyTimes()
+
+			clientFake := fake.NewSimpleClientset(append(tt.existing, tt.sub)...)
+			op := &Operator{
+				Operator: &queueinformer.Operator{OpClient: mockClient},
+				client:   clientFake,
+				clock:    clock.NewFakeClock(now.Time),
+			}
+
+			require.NoError(t, op.syncSubscriptions(tt.sub))
+
+			sub, err := clientFake.OperatorsV1alpha1().Subscriptions("ns").Get("sub", metav1.GetOptions{})
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedFinalizer, hasUninstallFinalizer(sub))
+			require.Equal(t, tt.expectedUninstall, sub.Status.Uninstall)
+
+			csvs := []string{}
+			for _, namespace := range []string{"ns", "other"} {
+				list, err := clientFake.OperatorsV1alpha1().ClusterServiceVersions(namespace).List(metav1.ListOptions{})
+				require.NoError(t, err)
+				for _, csv := range list.Items {
+					csvs = append(csvs, csv.GetName())
+				}
+			}
+			require.ElementsMatch(t, tt.expectedCSVs, csvs)
+
+			crds := []string{}
+			list, err := extClient.ApiextensionsV1beta1().CustomResourceDefinitions().List(metav1.ListOptions{})
+			require.NoError(t, err)
+			for _, crd := range list.Items {
+				crds = append(crds, crd.GetName())
+			}
+			require.ElementsMatch(t, tt.expectedCRDs, crds)
+		})
+	}
+}
@@ -9,7 +9,9 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/client-go/informers"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 
@@ -19,7 +21,10 @@ import (
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/informers/externalversions"
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/controller/annotator"
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/controller/install"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/audit"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/operatorclient"
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/queueinformer"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/statusutil"
 )
 
 var ErrRequirementsNotMet = errors.New("requirements were not met")
@@ -28,15 +33,45 @@ const (
 	FallbackWakeupInterval = 30 * time.Second
 )
 
+// for test stubbing
+var timeNow = metav1.Now
+
 type Operator struct {
 	*queueinformer.Operator
 	csvQueue  workqueue.RateLimitingInterface
 	client    versioned.Interface
 	resolver  install.StrategyResolverInterface
 	annotator *annotator.Annotator
+	// serviceAccountClients creates clients for installing with a user-supplied ServiceAccount; nil installs
+	// everything with OLM's own identity
+	serviceAccountClients client.ServiceAccountClientFactory
+	// namespaceLister reads the install ServiceAccounts namespaces require
+	namespaceLister corelisters.NamespaceLister
+	// auditor records the objects OLM creates, updates and deletes; nil records nothing
+	auditor *audit.Auditor
+	// clock tells the time for install timeouts; nil uses timeNow
+	clock clock.Clock
 }
 
-func NewOperator(kubeconfig string, wakeupInterval time.Duration, annotations map[string]string, namespaces []string) (*Operator, error) {
+func NewOperator(kubeconfig string, wakeupInterval time.Duration, annotations map[string]string, namespaces []string, imageRelocation *install.ImageRelocationConfig, auditor *audit.Auditor, queueConfig queueinformer.Config) (*Operator, error) {
+	// Create a new client for ALM types (CRs)
+	crClient, err := client.NewClient(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create a factory for clients that impersonate install ServiceAccounts
+	serviceAccountClients, err := client.NewServiceAccountClientFactory(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewOperatorFromClients(operatorclient.NewClient(kubeconfig), crClient, serviceAccountClients, clock.RealClock{}, wakeupInterval, annotations, namespaces, imageRelocation, auditor, queueConfig)
+}
+
+// NewOperatorFromClients creates an Operator that manages the cluster the given clients talk to, and reads the time
+// from clk. A nil serviceAccountClients installs everything with OLM's own identity.
+func NewOperatorFromClients(opClient operatorclient.ClientInterface, crClient versioned.Interface, serviceAccountClients client.ServiceAccountClientFactory, clk clock.Clock, wakeupInterval time.Duration, annotations map[string]string, namespaces []string, imageRelocation *install.ImageRelocationConfig, auditor *audit.Auditor, queueConfig queueinformer.Config) (*Operator, error) {
 	if wakeupInterval < 0 {
 		wakeupInterval = FallbackWakeupInterval
 	}
@@ -44,36 +79,39 @@ func NewOperator(kubeconfig string, wakeupInterval time.Duration, annotations ma
 		namespaces = []string{metav1.NamespaceAll}
 	}
 
-	// Create a new client for ALM types (CRs)
-	crClient, err := client.NewClient(kubeconfig)
-	if err != nil {
-		return nil, err
-	}
-
-	queueOperator, err := queueinformer.NewOperator(kubeconfig)
+	queueOperator, err := queueinformer.NewOperatorFromClient(opClient, queueConfig)
 	if err != nil {
 		return nil, err
 	}
 	namespaceAnnotator := annotator.NewAnnotator(queueOperator.OpClient, annotations)
 
 	op := &Operator{
-		Operator:  queueOperator,
-		client:    crClient,
-		resolver:  &install.StrategyResolver{},
-		annotator: namespaceAnnotator,
+		Operator:              queueOperator,
+		client:                crClient,
+		resolver:              &install.StrategyResolver{ImageRelocation: imageRelocation, Auditor: auditor},
+		annotator:             namespaceAnnotator,
+		serviceAccountClients: serviceAccountClients,
+		auditor:               auditor,
+		clock:                 clk,
 	}
 
+	// cache namespaces to read the install ServiceAccounts they name
+	namespaceInformer := informers.NewSharedInformerFactory(queueOperator.OpClient.KubernetesInterface(), wakeupInterval).Core().V1().Namespaces()
+	op.namespaceLister = namespaceInformer.Lister()
+
 	// if watching all namespaces, set up a watch to annotate new namespaces
 	if len(namespaces) == 1 && namespaces[0] == metav1.NamespaceAll {
 		log.Debug("watching all namespaces, setting up queue")
-		namespaceInformer := informers.NewSharedInformerFactory(queueOperator.OpClient.KubernetesInterface(), wakeupInterval).Core().V1().Namespaces().Informer()
 		queueInformer := queueinformer.NewInformer(
-			workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "namespaces"),
-			namespaceInformer,
+			queueOperator.NewQueue("namespaces"),
+			namespaceInformer.Informer(),
 			op.annotateNamespace,
 			nil,
+			nil,
 		)
 		op.RegisterQueueInformer(queueInformer)
+	} else {
+		op.RegisterInformer(namespaceInformer.Informer())
 	}
 
 	// annotate namespaces that ALM operator manages
@@ -91,11 +129,12 @@ func NewOperator(kubeconfig string, wakeupInterval time.Duration, annotations ma
 
 	// csvInformers for each namespace all use the same backing queue
 	// queue keys are namespaced
-	csvQueue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "clusterserviceversions")
+	csvQueue := queueOperator.NewQueue("clusterserviceversions")
 	queueInformers := queueinformer.New(
 		csvQueue,
 		csvInformers,
 		op.syncClusterServiceVersion,
+		op.deleteClusterServiceVersion,
 		nil,
 	)
 	for _, informer := range queueInformers {
@@ -105,6 +144,14 @@ func NewOperator(kubeconfig string, wakeupInterval time.Duration, annotations ma
 	return op, nil
 }
 
+// now returns the current time from the operator's clock
+func (a *Operator) now() metav1.Time {
+	if a.clock == nil {
+		return timeNow()
+	}
+	return metav1.NewTime(a.clock.Now())
+}
+
 func (a *Operator) requeueCSV(csv *v1alpha1.ClusterServiceVersion) {
 	k, err := cache.DeletionHandlingMetaNamespaceKeyFunc(csv)
 	if err != nil {
@@ -116,6 +163,22 @@ func (a *Operator) requeueCSV(csv *v1alpha1.ClusterServiceVersion) {
 	return
 }
 
+// deleteClusterServiceVersion requeues the CSVs that replace a deleted CSV, since they may have been waiting on it
+func (a *Operator) deleteClusterServiceVersion(obj interface{}) error {
+	clusterServiceVersion, ok := obj.(*v1alpha1.ClusterServiceVersion)
+	if !ok {
+		log.Debugf("wrong type: %#v", obj)
+		return fmt.Errorf("casting ClusterServiceVersion failed")
+	}
+
+	for _, csv := range a.csvsInNamespace(clusterServiceVersion.GetNamespace()) {
+		if csv.Spec.Replaces == clusterServiceVersion.GetName() {
+			a.requeueCSV(csv)
+		}
+	}
+	return nil
+}
+
 // syncClusterServiceVersion is the method that gets called when we see a CSV event in the cluster
 func (a *Operator) syncClusterServiceVersion(obj interface{}) (syncError error) {
 	clusterServiceVersion, ok := obj.(*v1alpha1.ClusterServiceVersion)
@@ -131,14 +194,8 @@ func (a *Operator) syncClusterServiceVersion(obj interface{}) (syncError error)
 
 	outCSV, syncError := a.transitionCSVState(*clusterServiceVersion)
 
-	// no changes in status, don't update
-	if outCSV.Status.Phase == clusterServiceVersion.Status.Phase && outCSV.Status.Reason == clusterServiceVersion.Status.Reason && outCSV.Status.Message == clusterServiceVersion.Status.Message {
-		return
-	}
-
-	// Update CSV with status of transition. Log errors if we can't write them to the status.
-	_, err := a.client.OperatorsV1alpha1().ClusterServiceVersions(clusterServiceVersion.GetNamespace()).UpdateStatus(outCSV)
-	if err != nil {
+	// Update CSV with status of transition, if it changed. Log errors if we can't write them to the status.
+	if _, err := statusutil.UpdateClusterServiceVersionStatus(a.client, clusterServiceVersion, outCSV); err != nil {
 		updateErr := errors.New("error updating ClusterServiceVersion status: " + err.Error())
 		if syncError == nil {
 			logger.Info(updateErr)
@@ -196,6 +253,12 @@ func (a *Operator) transitionCSVState(in v1alpha1.ClusterServiceVersion) (out *v
 		}
 
 		if syncError = installer.Install(strategy); syncError != nil {
+			if install.IsErrorInsufficientPermissions(syncError) {
+				// the install ServiceAccount may be granted the missing permissions, so retry rather than fail
+				out.SetPhase(v1alpha1.CSVPhaseInstallReady, v1alpha1.CSVReasonInsufficientPermissions, fmt.Sprintf("install strategy failed: %s", syncError))
+				a.requeueCSV(out)
+				return
+			}
 			out.SetPhase(v1alpha1.CSVPhaseFailed, v1alpha1.CSVReasonComponentFailed, fmt.Sprintf("install strategy failed: %s", syncError))
 			return
 		}
@@ -212,6 +275,9 @@ func (a *Operator) transitionCSVState(in v1alpha1.ClusterServiceVersion) (out *v
 
 		if installErr := a.updateInstallStatus(out, installer, strategy, v1alpha1.CSVReasonWaiting); installErr == nil {
 			logger.WithField("strategy", out.Spec.InstallStrategy.StrategyName).Infof("install strategy successful")
+		} else if out.InstallTimedOut(a.now()) {
+			logger.WithField("timeout", out.Spec.InstallTimeout.Duration).Info("install timed out")
+			out.SetPhase(v1alpha1.CSVPhaseFailed, v1alpha1.CSVReasonInstallCheckFailed, fmt.Sprintf("install timed out after %s: %s", out.Spec.InstallTimeout.Duration, installErr))
 		}
 
 	case v1alpha1.CSVPhaseSucceeded:
@@ -247,6 +313,12 @@ func (a *Operator) transitionCSVState(in v1alpha1.ClusterServiceVersion) (out *v
 		syncError := a.OpClient.DeleteCustomResource(v1alpha1.GroupName, v1alpha1.GroupVersion, out.GetNamespace(), v1alpha1.ClusterServiceVersionKind, out.GetName())
 		if syncError != nil {
 			logger.Debugf("unable to get delete csv marked for deletion: %s", syncError.Error())
+		} else {
+			a.auditor.Record(audit.Record{
+				Action:         audit.ActionDelete,
+				Object:         audit.Reference(v1alpha1.ClusterServiceVersionAPIVersion, v1alpha1.ClusterServiceVersionKind, out),
+				CorrelationIDs: audit.Correlation{ClusterServiceVersion: string(out.GetUID())},
+			})
 		}
 	}
 
@@ -317,6 +389,7 @@ func (a *Operator) checkReplacementsAndUpdateStatus(csv *v1alpha1.ClusterService
 
 func (a *Operator) updateInstallStatus(csv *v1alpha1.ClusterServiceVersion, installer install.StrategyInstaller, strategy install.Strategy, requeueConditionReason v1alpha1.ConditionReason) error {
 	installed, strategyErr := installer.CheckInstalled(strategy)
+	a.updateDeploymentProgress(csv, installer, strategy)
 	if installed {
 		// if there's no error, we're successfully running
 		if csv.Status.Phase != v1alpha1.CSVPhaseSucceeded {
@@ -333,6 +406,13 @@ func (a *Operator) updateInstallStatus(csv *v1alpha1.ClusterServiceVersion, inst
 
 	// if there's an error checking install that shouldn't fail the strategy, requeue with message
 	if strategyErr != nil {
+		// surface diagnosed pod failures (e.g. crash loops, image pull errors) with their own reason
+		if install.IsErrorComponentFailure(strategyErr) {
+			requeueConditionReason = v1alpha1.CSVReasonComponentFailing
+		}
+		if install.IsErrorInsufficientPermissions(strategyErr) {
+			requeueConditionReason = v1alpha1.CSVReasonInsufficientPermissions
+		}
 		csv.SetPhase(v1alpha1.CSVPhaseInstalling, requeueConditionReason, fmt.Sprintf("installing: %s", strategyErr))
 		a.requeueCSV(csv)
 		return strategyErr
@@ -341,6 +421,20 @@ func (a *Operator) updateInstallStatus(csv *v1alpha1.ClusterServiceVersion, inst
 	return nil
 }
 
+// updateDeploymentProgress records the rollout state of the strategy's deployments, if the installer can report it
+func (a *Operator) updateDeploymentProgress(csv *v1alpha1.ClusterServiceVersion, installer install.StrategyInstaller, strategy install.Strategy) {
+	reporter, ok := installer.(install.StrategyProgressReporter)
+	if !ok {
+		return
+	}
+	progress, err := reporter.InstallProgress(strategy)
+	if err != nil {
+		log.Debugf("unable to get install progress for %s: %s", csv.GetName(), err)
+		return
+	}
+	csv.SetDeploymentProgress(progress)
+}
+
 // parseStrategiesAndUpdateStatus returns a StrategyInstaller and a Strategy for a CSV if it can, else it sets a status on the CSV and returns
 func (a *Operator) parseStrategiesAndUpdateStatus(csv *v1alpha1.ClusterServiceVersion) (install.StrategyInstaller, install.Strategy, install.Strategy) {
 	strategy, err := a.resolver.UnmarshalStrategy(csv.Spec.InstallStrategy)
@@ -362,11 +456,39 @@ func (a *Operator) parseStrategiesAndUpdateStatus(csv *v1alpha1.ClusterServiceVe
 		a.requeueCSV(previousCSV)
 	}
 
+	opClient, err := a.installClient(csv)
+	if err != nil {
+		// never fall back to OLM's own permissions if the install ServiceAccount can't be used
+		csv.SetPhase(csv.Status.Phase, v1alpha1.CSVReasonInsufficientPermissions, err.Error())
+		a.requeueCSV(csv)
+		return nil, nil, nil
+	}
+
 	strName := strategy.GetStrategyName()
-	installer := a.resolver.InstallerForStrategy(strName, a.OpClient, csv, previousStrategy)
+	installer := a.resolver.InstallerForStrategy(strName, opClient, csv, previousStrategy)
 	return installer, strategy, previousStrategy
 }
 
+// installClient returns the client to install a CSV with: one impersonating the ServiceAccount named by its namespace
+// or else by the CSV, or OLM's own if neither names one
+func (a *Operator) installClient(csv *v1alpha1.ClusterServiceVersion) (operatorclient.ClientInterface, error) {
+	if a.serviceAccountClients == nil {
+		return a.OpClient, nil
+	}
+	serviceAccount, err := client.InstallServiceAccount(a.namespaceLister, csv.GetNamespace(), csv.GetAnnotations()[client.InstallServiceAccountAnnotationKey])
+	if err != nil {
+		return nil, err
+	}
+	if serviceAccount == "" {
+		return a.OpClient, nil
+	}
+	opClient, _, err := a.serviceAccountClients.ClientsForServiceAccount(csv.GetNamespace(), serviceAccount)
+	if err != nil {
+		return nil, fmt.Errorf("error creating client for install service account %s: %s", serviceAccount, err)
+	}
+	return opClient, nil
+}
+
 func (a *Operator) requirementStatus(csv *v1alpha1.ClusterServiceVersion) (met bool, statuses []v1alpha1.RequirementStatus) {
 	met = true
 	for _, r := range csv.GetAllCRDDescriptions() {
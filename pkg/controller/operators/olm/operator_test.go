@@ -4,18 +4,23 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators/v1alpha1"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/client"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/clientfakes"
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/clientset/versioned/fake"
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/controller/annotator"
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/controller/install"
@@ -683,6 +688,28 @@ func TestCSVStateTransitionsFromInstallReady(t *testing.T) {
 			err:         fmt.Errorf("error installing component"),
 			description: "InstallStrategy/NotReplacing/ComponentFailed",
 		},
+		{
+			in: withStatus(withSpec(testCSV(""),
+				&v1alpha1.ClusterServiceVersionSpec{
+					InstallStrategy: v1alpha1.NamedInstallStrategy{
+						StrategyName:    "teststrategy",
+						StrategySpecRaw: []byte(`"test":"spec"`),
+					},
+				}),
+				&v1alpha1.ClusterServiceVersionStatus{
+					Phase: v1alpha1.CSVPhaseInstallReady,
+				}),
+			out: withStatus(testCSV(""), &v1alpha1.ClusterServiceVersionStatus{
+				Phase:   v1alpha1.CSVPhaseInstallReady,
+				Message: "install strategy failed: InsufficientPermissions: roles is forbidden",
+				Reason:  v1alpha1.CSVReasonInsufficientPermissions,
+			}),
+			state: clusterState{
+				installErr: install.StrategyError{Reason: install.StrategyErrReasonInsufficientPermissions, Message: "roles is forbidden"},
+			},
+			err:         install.StrategyError{Reason: install.StrategyErrReasonInsufficientPermissions, Message: "roles is forbidden"},
+			description: "InstallStrategy/NotReplacing/InsufficientPermissions",
+		},
 		{
 			in: withStatus(withSpec(testCSV(""),
 				&v1alpha1.ClusterServiceVersionSpec{
@@ -776,6 +803,104 @@ func TestCSVStateTransitionsFromInstallReady(t *testing.T) {
 	}
 }
 
+func TestInstallClient(t *testing.T) {
+	namespace := "ns"
+	tests := []struct {
+		csvAnnotation       string
+		namespaceAnnotation string
+		factoryErr          error
+		serviceAccount      string
+		err                 string
+		description         string
+	}{
+		{
+			description: "NoServiceAccount",
+		},
+		{
+			namespaceAnnotation: "ns-installer",
+			serviceAccount:      "ns-installer",
+			description:         "NamespaceServiceAccount",
+		},
+		{
+			csvAnnotation:  "csv-installer",
+			serviceAccount: "csv-installer",
+			description:    "CSVServiceAccount",
+		},
+		{
+			csvAnnotation:       "csv-installer",
+			namespaceAnnotation: "ns-installer",
+			serviceAccount:      "ns-installer",
+			description:         "NamespaceServiceAccountOverridesCSV",
+		},
+		{
+			csvAnnotation: "csv-installer",
+			factoryErr:    fmt.Errorf("bad config"),
+			err:           "error creating client for install service account csv-installer: bad config",
+			description:   "FactoryError",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockOp := NewMockALMOperator(ctrl)
+
+			ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}
+			if tt.namespaceAnnotation != "" {
+				ns.SetAnnotations(map[string]string{client.InstallServiceAccountAnnotationKey: tt.namespaceAnnotation})
+			}
+			namespaces := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			require.NoError(t, namespaces.Add(ns))
+			mockOp.namespaceLister = corelisters.NewNamespaceLister(namespaces)
+
+			scopedClient := operatorclient.NewMockClientInterface(ctrl)
+			factory := new(clientfakes.FakeServiceAccountClientFactory)
+			factory.ClientsForServiceAccountReturns(scopedClient, nil, tt.factoryErr)
+			mockOp.serviceAccountClients = factory
+
+			csv := testCSV("")
+			csv.SetNamespace(namespace)
+			if tt.csvAnnotation != "" {
+				csv.SetAnnotations(map[string]string{client.InstallServiceAccountAnnotationKey: tt.csvAnnotation})
+			}
+
+			opClient, err := mockOp.installClient(csv)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			if tt.serviceAccount == "" {
+				require.Equal(t, 0, factory.ClientsForServiceAccountCallCount())
+				require.Equal(t, mockOp.OpClient, opClient)
+				return
+			}
+			require.Equal(t, scopedClient, opClient)
+			gotNamespace, name := factory.ClientsForServiceAccountArgsForCall(0)
+			require.Equal(t, namespace, gotNamespace)
+			require.Equal(t, tt.serviceAccount, name)
+		})
+	}
+}
+
+func TestDeleteClusterServiceVersion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockOp := NewMockALMOperator(ctrl)
+
+	deleted := testCSV("prev")
+	replacing := withSpec(testCSV("next"), &v1alpha1.ClusterServiceVersionSpec{Replaces: "prev"})
+	unrelated := withSpec(testCSV("other"), &v1alpha1.ClusterServiceVersionSpec{Replaces: "something-else"})
+	mockCSVsInNamespace(t, mockOp.MockOpClient, deleted.GetNamespace(), []*v1alpha1.ClusterServiceVersion{replacing, unrelated}, nil)
+
+	require.NoError(t, mockOp.deleteClusterServiceVersion(deleted))
+
+	// only the CSV waiting on the deleted one is requeued
+	key, _ := mockOp.csvQueue.Get()
+	require.Equal(t, "next", key)
+	require.Equal(t, 0, mockOp.csvQueue.Len())
+}
+
 func TestCSVStateTransitionsFromInstalling(t *testing.T) {
 	type clusterState struct {
 		csvsInNamespace []*v1alpha1.ClusterServiceVersion
@@ -884,6 +1009,77 @@ func TestCSVStateTransitionsFromInstalling(t *testing.T) {
 			},
 			description: "InstallStrategy/NotReplacing/UnrecoverableError",
 		},
+		{
+			in: withStatus(withSpec(testCSV(""),
+				&v1alpha1.ClusterServiceVersionSpec{
+					InstallStrategy: v1alpha1.NamedInstallStrategy{
+						StrategyName:    "teststrategy",
+						StrategySpecRaw: []byte(`"test":"spec"`),
+					},
+				}),
+				&v1alpha1.ClusterServiceVersionStatus{
+					Phase: v1alpha1.CSVPhaseInstalling,
+				}),
+			out: withStatus(testCSV(""), &v1alpha1.ClusterServiceVersionStatus{
+				Phase:   v1alpha1.CSVPhaseInstalling,
+				Message: "installing: CrashLoopBackOff: deployment dep: pod p container c: CrashLoopBackOff",
+				Reason:  v1alpha1.CSVReasonComponentFailing,
+			}),
+			state: clusterState{
+				checkInstallErr: &install.StrategyError{Reason: install.StrategyErrReasonCrashLoopBackOff, Message: "deployment dep: pod p container c: CrashLoopBackOff"},
+			},
+			description: "InstallStrategy/NotReplacing/ComponentFailing",
+		},
+		{
+			in: withStatus(withSpec(testCSV(""),
+				&v1alpha1.ClusterServiceVersionSpec{
+					InstallStrategy: v1alpha1.NamedInstallStrategy{
+						StrategyName:    "teststrategy",
+						StrategySpecRaw: []byte(`"test":"spec"`),
+					},
+					InstallTimeout: &metav1.Duration{Duration: time.Minute},
+				}),
+				&v1alpha1.ClusterServiceVersionStatus{
+					Phase: v1alpha1.CSVPhaseInstalling,
+					InstallProgress: &v1alpha1.InstallProgress{
+						StartTime: metav1.NewTime(time.Now().Add(-2 * time.Minute)),
+					},
+				}),
+			out: withStatus(testCSV(""), &v1alpha1.ClusterServiceVersionStatus{
+				Phase:   v1alpha1.CSVPhaseFailed,
+				Message: "install timed out after 1m0s: error installing component",
+				Reason:  v1alpha1.CSVReasonInstallCheckFailed,
+			}),
+			state: clusterState{
+				checkInstallErr: fmt.Errorf("error installing component"),
+			},
+			description: "InstallStrategy/NotReplacing/InstallTimedOut",
+		},
+		{
+			in: withStatus(withSpec(testCSV(""),
+				&v1alpha1.ClusterServiceVersionSpec{
+					InstallStrategy: v1alpha1.NamedInstallStrategy{
+						StrategyName:    "teststrategy",
+						StrategySpecRaw: []byte(`"test":"spec"`),
+					},
+					InstallTimeout: &metav1.Duration{Duration: time.Hour},
+				}),
+				&v1alpha1.ClusterServiceVersionStatus{
+					Phase: v1alpha1.CSVPhaseInstalling,
+					InstallProgress: &v1alpha1.InstallProgress{
+						StartTime: metav1.NewTime(time.Now().Add(-2 * time.Minute)),
+					},
+				}),
+			out: withStatus(testCSV(""), &v1alpha1.ClusterServiceVersionStatus{
+				Phase:   v1alpha1.CSVPhaseInstalling,
+				Message: "installing: error installing component",
+				Reason:  v1alpha1.CSVReasonWaiting,
+			}),
+			state: clusterState{
+				checkInstallErr: fmt.Errorf("error installing component"),
+			},
+			description: "InstallStrategy/NotReplacing/WithinInstallTimeout",
+		},
 		{
 			in: withStatus(withSpec(testCSV(""),
 				&v1alpha1.ClusterServiceVersionSpec{
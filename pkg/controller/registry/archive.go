@@ -0,0 +1,254 @@
+package registry
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// MaxArchiveSize is the largest catalog archive that will be fetched or unpacked, compressed or not
+	MaxArchiveSize = 128 << 20
+
+	// ChecksumSuffix is appended to the URL of a catalog archive to find its published checksum
+	ChecksumSuffix = ".sha256"
+
+	// DefaultArchiveFetchTimeout bounds each request for a catalog archive or its checksum
+	DefaultArchiveFetchTimeout = time.Minute
+)
+
+// NewInMemoryFromArchive loads a catalog from a tar archive, optionally gzipped, of the directory layout that
+// DirectoryCatalogResourceLoader understands. The archive is unpacked in memory.
+func NewInMemoryFromArchive(archive []byte) (*InMem, error) {
+	files, err := unpackArchive(archive)
+	if err != nil {
+		return nil, err
+	}
+	loader := DirectoryCatalogResourceLoader{
+		Catalog:  NewInMem(),
+		Walk:     files.walk,
+		ReadFile: files.readFile,
+	}
+	if err := loader.LoadCatalogResources("."); err != nil {
+		return nil, err
+	}
+	return loader.Catalog, nil
+}
+
+// archiveFiles are the regular files in a catalog archive, by cleaned path
+type archiveFiles map[string]archiveFile
+
+type archiveFile struct {
+	info os.FileInfo
+	data []byte
+}
+
+func unpackArchive(archive []byte) (archiveFiles, error) {
+	var r io.Reader = bytes.NewReader(archive)
+	if len(archive) > 2 && archive[0] == 0x1f && archive[1] == 0x8b {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("error decompressing catalog archive: %s", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+	// refuse archives that unpack to more than the size limit
+	limited := &io.LimitedReader{R: r, N: MaxArchiveSize + 1}
+
+	files := archiveFiles{}
+	tr := tar.NewReader(limited)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if limited.N <= 0 {
+			return nil, fmt.Errorf("catalog archive unpacks to more than %d bytes", MaxArchiveSize)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading catalog archive: %s", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := ioutil.ReadAll(tr)
+		if limited.N <= 0 {
+			return nil, fmt.Errorf("catalog archive unpacks to more than %d bytes", MaxArchiveSize)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s from catalog archive: %s", header.Name, err)
+		}
+		files[path.Clean(strings.TrimPrefix(header.Name, "/"))] = archiveFile{info: header.FileInfo(), data: data}
+	}
+	return files, nil
+}
+
+// walk calls walkFn for each file in the archive in lexical order, like filepath.Walk. Files in hidden directories
+// are skipped, as filepath.Walk would skip them with the loader's walk funcs.
+func (f archiveFiles) walk(root string, walkFn filepath.WalkFunc) error {
+	names := make([]string, 0, len(f))
+	for name := range f {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if inHiddenDir(name) {
+			continue
+		}
+		if err := walkFn(name, f[name].info, nil); err != nil && err != filepath.SkipDir {
+			return err
+		}
+	}
+	return nil
+}
+
+func inHiddenDir(name string) bool {
+	dirs := strings.Split(path.Dir(name), "/")
+	for _, dir := range dirs {
+		if strings.HasPrefix(dir, ".") && dir != "." {
+			return true
+		}
+	}
+	return false
+}
+
+func (f archiveFiles) readFile(filename string) ([]byte, error) {
+	file, ok := f[filename]
+	if !ok {
+		return nil, fmt.Errorf("%s not found in catalog archive", filename)
+	}
+	return file.data, nil
+}
+
+// ArchiveFetcher fetches a catalog archive published at a URL. Conditional requests skip downloading an archive that
+// hasn't changed since it was last fetched.
+type ArchiveFetcher struct {
+	// URL of the catalog archive
+	URL string
+	// Checksum is the expected sha256 of the archive, hex encoded. If empty, the checksum published next to the
+	// archive, at URL with ChecksumSuffix appended, is expected.
+	Checksum string
+
+	client *http.Client
+
+	// validators of the last archive fetched, sent with conditional requests
+	etag         string
+	lastModified string
+	revision     string
+}
+
+// NewArchiveFetcher returns a fetcher of the catalog archive at url
+func NewArchiveFetcher(url, checksum string) *ArchiveFetcher {
+	return &ArchiveFetcher{
+		URL:      url,
+		Checksum: checksum,
+		client:   &http.Client{Timeout: DefaultArchiveFetchTimeout},
+	}
+}
+
+// Fetch fetches and loads the catalog archive. It returns a nil catalog if the archive hasn't changed since it was
+// last loaded. The revision is the sha256 of the archive last loaded.
+func (f *ArchiveFetcher) Fetch() (catalog *InMem, revision string, err error) {
+	req, err := http.NewRequest(http.MethodGet, f.URL, nil)
+	if err != nil {
+		return nil, f.revision, fmt.Errorf("invalid catalog archive URL %s: %s", f.URL, err)
+	}
+	if f.etag != "" {
+		req.Header.Set("If-None-Match", f.etag)
+	}
+	if f.lastModified != "" {
+		req.Header.Set("If-Modified-Since", f.lastModified)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, f.revision, fmt.Errorf("error fetching catalog archive: %s", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified && f.revision != "" {
+		log.Debugf("catalog archive %s not modified", f.URL)
+		return nil, f.revision, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, f.revision, fmt.Errorf("error fetching catalog archive: %s", resp.Status)
+	}
+	archive, err := readLimited(resp.Body)
+	if err != nil {
+		return nil, f.revision, fmt.Errorf("error fetching catalog archive: %s", err)
+	}
+
+	sum := sha256.Sum256(archive)
+	actual := hex.EncodeToString(sum[:])
+	expected, err := f.expectedChecksum()
+	if err != nil {
+		return nil, f.revision, err
+	}
+	if actual != expected {
+		return nil, f.revision, fmt.Errorf("catalog archive checksum %s doesn't match expected checksum %s", actual, expected)
+	}
+
+	if actual == f.revision {
+		// served again without validators, but unchanged
+		f.etag, f.lastModified = resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+		return nil, f.revision, nil
+	}
+	catalog, err = NewInMemoryFromArchive(archive)
+	if err != nil {
+		return nil, f.revision, fmt.Errorf("error loading catalog archive %s: %s", actual, err)
+	}
+	f.etag, f.lastModified, f.revision = resp.Header.Get("ETag"), resp.Header.Get("Last-Modified"), actual
+	return catalog, f.revision, nil
+}
+
+func (f *ArchiveFetcher) expectedChecksum() (string, error) {
+	if f.Checksum != "" {
+		return strings.ToLower(f.Checksum), nil
+	}
+
+	resp, err := f.client.Get(f.URL + ChecksumSuffix)
+	if err != nil {
+		return "", fmt.Errorf("error fetching catalog archive checksum: %s", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("error fetching catalog archive checksum: %s", resp.Status)
+	}
+	// the checksum file is in sha256sum's format: the checksum, then the file name
+	line, err := bufio.NewReader(io.LimitReader(resp.Body, 1024)).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("error fetching catalog archive checksum: %s", err)
+	}
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("catalog archive checksum is empty")
+	}
+	return strings.ToLower(fields[0]), nil
+}
+
+func readLimited(r io.Reader) ([]byte, error) {
+	data, err := ioutil.ReadAll(io.LimitReader(r, MaxArchiveSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxArchiveSize {
+		return nil, fmt.Errorf("catalog archive is larger than %d bytes", MaxArchiveSize)
+	}
+	return data, nil
+}
@@ -0,0 +1,144 @@
+package registry
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testCatalogDir = "../../../deploy/chart/catalog_resources/ocs"
+
+// archiveDirectory returns a gzipped tar archive of the files in a directory, plus any extra files given
+func archiveDirectory(t *testing.T, dir string, extra map[string]string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	add := func(name string, data []byte) {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write(data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		add("catalog/"+rel, data)
+		return nil
+	}))
+	for name, data := range extra {
+		add(name, []byte(data))
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestNewInMemoryFromArchive(t *testing.T) {
+	expected, err := NewInMemoryFromDirectory(testCatalogDir)
+	require.NoError(t, err)
+
+	archive := archiveDirectory(t, testCatalogDir, map[string]string{
+		// hidden files and directories are skipped, like they are in directories
+		".hidden/broken.crd.yaml":      "not a crd",
+		"catalog/.broken.package.yaml": "not a package",
+		"catalog/README.md":            "not a catalog resource",
+	})
+	catalog, err := NewInMemoryFromArchive(archive)
+	require.NoError(t, err)
+	require.Equal(t, expected, catalog)
+
+	_, err = NewInMemoryFromArchive([]byte("not an archive"))
+	require.Error(t, err)
+
+	broken := archiveDirectory(t, testCatalogDir, map[string]string{"catalog/broken.crd.yaml": "not a crd"})
+	_, err = NewInMemoryFromArchive(broken)
+	require.Error(t, err)
+}
+
+// archiveServer serves a catalog archive with an ETag, and its checksum
+type archiveServer struct {
+	archive  []byte
+	checksum string
+	requests int
+	fetches  int
+}
+
+func (s *archiveServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/catalog.tar.gz"+ChecksumSuffix {
+		fmt.Fprintf(w, "%s  catalog.tar.gz\n", s.checksum)
+		return
+	}
+	s.requests++
+	etag := `"` + checksum(s.archive) + `"`
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	s.fetches++
+	w.Header().Set("ETag", etag)
+	w.Write(s.archive)
+}
+
+func TestArchiveFetcher(t *testing.T) {
+	archive := archiveDirectory(t, testCatalogDir, nil)
+	content := &archiveServer{archive: archive, checksum: checksum(archive)}
+	server := httptest.NewServer(content)
+	defer server.Close()
+
+	fetcher := NewArchiveFetcher(server.URL+"/catalog.tar.gz", "")
+	catalog, revision, err := fetcher.Fetch()
+	require.NoError(t, err)
+	require.NotNil(t, catalog)
+	require.Equal(t, checksum(archive), revision)
+	require.Contains(t, catalog.AllPackages(), "etcd")
+
+	// unchanged archives aren't downloaded again
+	catalog, revision, err = fetcher.Fetch()
+	require.NoError(t, err)
+	require.Nil(t, catalog)
+	require.Equal(t, checksum(archive), revision)
+	require.Equal(t, 2, content.requests)
+	require.Equal(t, 1, content.fetches)
+
+	// archives that don't match their checksum aren't loaded
+	updated := archiveDirectory(t, testCatalogDir, map[string]string{"catalog/README.md": "updated"})
+	content.archive = updated
+	_, revision, err = fetcher.Fetch()
+	require.EqualError(t, err, fmt.Sprintf("catalog archive checksum %s doesn't match expected checksum %s", checksum(updated), checksum(archive)))
+	require.Equal(t, checksum(archive), revision)
+
+	content.checksum = checksum(updated)
+	catalog, revision, err = fetcher.Fetch()
+	require.NoError(t, err)
+	require.NotNil(t, catalog)
+	require.Equal(t, checksum(updated), revision)
+
+	// a pinned checksum overrides the published one
+	pinned := NewArchiveFetcher(server.URL+"/catalog.tar.gz", checksum(archive))
+	_, _, err = pinned.Fetch()
+	require.Error(t, err)
+}
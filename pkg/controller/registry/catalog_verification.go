@@ -0,0 +1,133 @@
+package registry
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+
+	"k8s.io/api/core/v1"
+)
+
+// ConfigMapSignatureName is the ConfigMap key holding the base64 encoded signature over the rest of the catalog
+const ConfigMapSignatureName = "signature"
+
+// CatalogVerificationError is returned when catalog content can't be shown to be signed by a trusted key
+type CatalogVerificationError struct {
+	ConfigMap string
+	Message   string
+}
+
+func (e CatalogVerificationError) Error() string {
+	return fmt.Sprintf("catalog ConfigMap %s failed verification: %s", e.ConfigMap, e.Message)
+}
+
+// IsCatalogVerificationError checks if an error is a CatalogVerificationError
+func IsCatalogVerificationError(err error) bool {
+	switch err.(type) {
+	case CatalogVerificationError, *CatalogVerificationError:
+		return true
+	}
+	return false
+}
+
+// CatalogVerifier checks detached catalog signatures against a set of trusted public keys
+type CatalogVerifier struct {
+	// keys maps key fingerprints to keys
+	keys map[string]crypto.PublicKey
+}
+
+// NewCatalogVerifier creates a CatalogVerifier that trusts the given PEM encoded RSA or ECDSA public keys
+func NewCatalogVerifier(pemKeys []string) (*CatalogVerifier, error) {
+	if len(pemKeys) == 0 {
+		return nil, fmt.Errorf("no trusted public keys given")
+	}
+	verifier := &CatalogVerifier{keys: map[string]crypto.PublicKey{}}
+	for i, pemKey := range pemKeys {
+		block, _ := pem.Decode([]byte(pemKey))
+		if block == nil {
+			return nil, fmt.Errorf("public key %d is not PEM encoded", i)
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing public key %d: %s", i, err)
+		}
+		switch key.(type) {
+		case *rsa.PublicKey, *ecdsa.PublicKey:
+		default:
+			return nil, fmt.Errorf("public key %d has unsupported type %T", i, key)
+		}
+		verifier.keys[fingerprint(block.Bytes)] = key
+	}
+	return verifier, nil
+}
+
+// Verify checks that the ConfigMap's signature was made over its content by one of the trusted keys, and returns the
+// fingerprint of that key
+func (v *CatalogVerifier) Verify(cm *v1.ConfigMap) (string, error) {
+	encoded, ok := cm.Data[ConfigMapSignatureName]
+	if !ok {
+		return "", CatalogVerificationError{ConfigMap: cm.GetName(), Message: "no signature found"}
+	}
+	signature, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", CatalogVerificationError{ConfigMap: cm.GetName(), Message: fmt.Sprintf("signature is not base64 encoded: %s", err)}
+	}
+
+	digest := CatalogContentDigest(cm)
+	for fp, key := range v.keys {
+		switch k := key.(type) {
+		case *rsa.PublicKey:
+			if rsa.VerifyPKCS1v15(k, crypto.SHA256, digest, signature) == nil {
+				return fp, nil
+			}
+		case *ecdsa.PublicKey:
+			var sig ecdsaSignature
+			if rest, err := asn1.Unmarshal(signature, &sig); err != nil || len(rest) > 0 || sig.R == nil || sig.S == nil {
+				continue
+			}
+			if ecdsa.Verify(k, digest, sig.R, sig.S) {
+				return fp, nil
+			}
+		}
+	}
+	return "", CatalogVerificationError{ConfigMap: cm.GetName(), Message: "signature does not match any trusted key"}
+}
+
+// ecdsaSignature is the ASN.1 encoding of an ECDSA signature, as produced by ecdsa.PrivateKey.Sign
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+// CatalogContentDigest is the SHA-256 digest that catalog signatures are made over. It covers every data and
+// binaryData entry of the ConfigMap except the signature itself, in key order.
+func CatalogContentDigest(cm *v1.ConfigMap) []byte {
+	return configMapDigest(cm, ConfigMapSignatureName)
+}
+
+// SignCatalogConfigMap signs the catalog content of the ConfigMap with an RSA or ECDSA private key and stores the
+// signature in the ConfigMap
+func SignCatalogConfigMap(cm *v1.ConfigMap, key crypto.Signer) error {
+	signature, err := key.Sign(rand.Reader, CatalogContentDigest(cm), crypto.SHA256)
+	if err != nil {
+		return fmt.Errorf("error signing catalog ConfigMap %s: %s", cm.GetName(), err)
+	}
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	cm.Data[ConfigMapSignatureName] = base64.StdEncoding.EncodeToString(signature)
+	return nil
+}
+
+func fingerprint(der []byte) string {
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:])
+}
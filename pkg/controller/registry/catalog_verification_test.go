@@ -0,0 +1,118 @@
+package registry
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func publicKeyPEM(t *testing.T, key crypto.Signer) string {
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func catalogConfigMap() *v1.ConfigMap {
+	return &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "catalog"},
+		Data: map[string]string{
+			ConfigMapCRDName:     "[]",
+			ConfigMapCSVName:     "- metadata:\n    name: test.v1\n  spec:\n    displayName: Test\n",
+			ConfigMapPackageName: "- packageName: test\n  channels:\n  - name: alpha\n    currentCSV: test.v1\n",
+		},
+	}
+}
+
+func TestCatalogVerifier(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	untrustedKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	verifier, err := NewCatalogVerifier([]string{publicKeyPEM(t, rsaKey), publicKeyPEM(t, ecdsaKey)})
+	require.NoError(t, err)
+
+	for _, key := range []crypto.Signer{rsaKey, ecdsaKey} {
+		cm := catalogConfigMap()
+		require.NoError(t, SignCatalogConfigMap(cm, key))
+		_, err := verifier.Verify(cm)
+		require.NoError(t, err)
+
+		// any change to the content invalidates the signature
+		cm.Data[ConfigMapCSVName] = "- metadata:\n    name: injected\n"
+		_, err = verifier.Verify(cm)
+		require.True(t, IsCatalogVerificationError(err))
+	}
+
+	cm := catalogConfigMap()
+	_, err = verifier.Verify(cm)
+	require.EqualError(t, err, "catalog ConfigMap catalog failed verification: no signature found")
+
+	require.NoError(t, SignCatalogConfigMap(cm, untrustedKey))
+	_, err = verifier.Verify(cm)
+	require.EqualError(t, err, "catalog ConfigMap catalog failed verification: signature does not match any trusted key")
+
+	_, err = NewCatalogVerifier(nil)
+	require.Error(t, err)
+	_, err = NewCatalogVerifier([]string{"not a key"})
+	require.Error(t, err)
+}
+
+func TestCatalogVerifierECDSA(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	verifier, err := NewCatalogVerifier([]string{publicKeyPEM(t, key)})
+	require.NoError(t, err)
+
+	sign := func(cm *v1.ConfigMap, signature []byte) {
+		cm.Data[ConfigMapSignatureName] = base64.StdEncoding.EncodeToString(signature)
+	}
+	cm := catalogConfigMap()
+	r, s, err := ecdsa.Sign(rand.Reader, key, CatalogContentDigest(cm))
+	require.NoError(t, err)
+	signature, err := asn1.Marshal(ecdsaSignature{R: r, S: s})
+	require.NoError(t, err)
+
+	sign(cm, signature)
+	_, err = verifier.Verify(cm)
+	require.NoError(t, err)
+
+	// signatures that aren't exactly one ASN.1 encoded (r, s) pair are rejected
+	for _, invalid := range [][]byte{[]byte("not asn.1"), append(signature, 0), {}} {
+		sign(cm, invalid)
+		_, err = verifier.Verify(cm)
+		require.EqualError(t, err, "catalog ConfigMap catalog failed verification: signature does not match any trusted key")
+	}
+}
+
+func TestVerifyingConfigMapLoader(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	verifier, err := NewCatalogVerifier([]string{publicKeyPEM(t, key)})
+	require.NoError(t, err)
+	loader := NewVerifyingConfigMapCatalogResourceLoader("ns", nil, verifier)
+
+	unsigned := catalogConfigMap()
+	catalog := NewInMem()
+	err = loader.LoadCatalogResourcesFromConfigMap(catalog, unsigned)
+	require.True(t, IsCatalogVerificationError(err))
+	require.Empty(t, catalog.AllPackages())
+
+	signed := catalogConfigMap()
+	require.NoError(t, SignCatalogConfigMap(signed, key))
+	require.NoError(t, loader.LoadCatalogResourcesFromConfigMap(catalog, signed))
+	require.Contains(t, catalog.AllPackages(), "test")
+}
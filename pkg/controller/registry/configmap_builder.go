@@ -0,0 +1,111 @@
+package registry
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// catalogFileEntries are the ConfigMap entries that catalog resource files are collected in, by file suffix
+var catalogFileEntries = map[string]string{
+	".crd.yaml":                   ConfigMapCRDName,
+	".clusterserviceversion.yaml": ConfigMapCSVName,
+	".package.yaml":               ConfigMapPackageName,
+}
+
+// NewCatalogConfigMapFromDirectory builds a catalog ConfigMap from a directory of catalog resources laid out as
+// DirectoryCatalogResourceLoader expects. If compress is set, the entries are gzip compressed into binaryData instead
+// of data. The ConfigMap is loaded before it's returned, so it's known to hold a valid catalog.
+func NewCatalogConfigMapFromDirectory(directory, name, namespace string, compress bool) (*v1.ConfigMap, error) {
+	entries := map[string][]json.RawMessage{}
+	err := filepath.Walk(directory, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(info.Name(), ".") && path != directory {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+		for suffix, key := range catalogFileEntries {
+			if !strings.HasSuffix(path, suffix) {
+				continue
+			}
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			resource, err := yaml.YAMLToJSON(data)
+			if err != nil {
+				return fmt.Errorf("error parsing %s: %s", path, err)
+			}
+			entries[key] = append(entries[key], resource)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading catalog directory %s: %s", directory, err)
+	}
+
+	cm := &v1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+	}
+	for key, resources := range entries {
+		list, err := json.Marshal(resources)
+		if err != nil {
+			return nil, err
+		}
+		entry, err := yaml.JSONToYAML(list)
+		if err != nil {
+			return nil, err
+		}
+		if !compress {
+			if cm.Data == nil {
+				cm.Data = map[string]string{}
+			}
+			cm.Data[key] = string(entry)
+			continue
+		}
+		if cm.BinaryData == nil {
+			cm.BinaryData = map[string][]byte{}
+		}
+		if cm.BinaryData[key], err = gzipEntry(entry); err != nil {
+			return nil, err
+		}
+	}
+
+	loader := NewConfigMapCatalogResourceLoader(namespace, nil)
+	if err := loader.LoadCatalogResourcesFromConfigMap(NewInMem(), cm); err != nil {
+		return nil, fmt.Errorf("error loading catalog built from %s: %s", directory, err)
+	}
+	return cm, nil
+}
+
+func gzipEntry(entry []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	gz, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := gz.Write(entry); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,68 @@
+package registry
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCatalogConfigMapFromDirectory(t *testing.T) {
+	expected, err := NewInMemoryFromDirectory(testCatalogDir)
+	require.NoError(t, err)
+
+	for _, compress := range []bool{false, true} {
+		cm, err := NewCatalogConfigMapFromDirectory(testCatalogDir, "catalog", "ns", compress)
+		require.NoError(t, err)
+		require.Equal(t, "catalog", cm.GetName())
+		require.Equal(t, "ns", cm.GetNamespace())
+		if compress {
+			require.Empty(t, cm.Data)
+			require.Len(t, cm.BinaryData, 3)
+		} else {
+			require.Len(t, cm.Data, 3)
+			require.Empty(t, cm.BinaryData)
+		}
+
+		catalog := NewInMem()
+		loader := NewConfigMapCatalogResourceLoader("ns", nil)
+		require.NoError(t, loader.LoadCatalogResourcesFromConfigMap(catalog, cm))
+		require.Equal(t, expected, catalog)
+	}
+
+	_, err = NewCatalogConfigMapFromDirectory("missing", "catalog", "ns", true)
+	require.Error(t, err)
+}
+
+func TestCompressedConfigMapEntries(t *testing.T) {
+	cm, err := NewCatalogConfigMapFromDirectory(testCatalogDir, "catalog", "ns", true)
+	require.NoError(t, err)
+	loader := NewConfigMapCatalogResourceLoader("ns", nil)
+
+	// compressed entries are covered by the signature
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	verifier, err := NewCatalogVerifier([]string{publicKeyPEM(t, key)})
+	require.NoError(t, err)
+	verifying := NewVerifyingConfigMapCatalogResourceLoader("ns", nil, verifier)
+	require.NoError(t, SignCatalogConfigMap(cm, key))
+	require.NoError(t, verifying.LoadCatalogResourcesFromConfigMap(NewInMem(), cm))
+
+	tampered := cm.DeepCopy()
+	tampered.BinaryData[ConfigMapPackageName], err = gzipEntry([]byte("[]"))
+	require.NoError(t, err)
+	err = verifying.LoadCatalogResourcesFromConfigMap(NewInMem(), tampered)
+	require.True(t, IsCatalogVerificationError(err))
+
+	both := cm.DeepCopy()
+	both.Data[ConfigMapPackageName] = "[]"
+	err = loader.LoadCatalogResourcesFromConfigMap(NewInMem(), both)
+	require.EqualError(t, err, "error loading ConfigMap catalog: packages is in both data and binaryData")
+
+	uncompressed := cm.DeepCopy()
+	uncompressed.BinaryData[ConfigMapPackageName] = []byte("- packageName: etcd\n")
+	err = loader.LoadCatalogResourcesFromConfigMap(NewInMem(), uncompressed)
+	require.EqualError(t, err, "error decompressing packages from ConfigMap catalog: gzip: invalid header")
+}
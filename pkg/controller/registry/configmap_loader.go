@@ -1,13 +1,23 @@
 package registry
 
 import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
 
 	"github.com/ghodss/yaml"
 	log "github.com/sirupsen/logrus"
 	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	corelisters "k8s.io/client-go/listers/core/v1"
 
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators/v1alpha1"
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/operatorclient"
@@ -18,12 +28,56 @@ const (
 	ConfigMapCRDName     = "customResourceDefinitions"
 	ConfigMapCSVName     = "clusterServiceVersions"
 	ConfigMapPackageName = "packages"
+
+	// MaxConfigMapEntrySize is the largest a compressed catalog ConfigMap entry may decompress to
+	MaxConfigMapEntrySize = 128 << 20
 )
 
+// ConfigMapSet is the set of ConfigMaps a catalog is split across
+type ConfigMapSet struct {
+	// Names of ConfigMaps in the catalog
+	Names []string
+	// Selector selects more ConfigMaps in the catalog, if set
+	Selector labels.Selector
+}
+
+// ConfigMapSetForCatalogSource returns the ConfigMaps of a CatalogSource: its ConfigMap, its ConfigMaps, and any
+// ConfigMaps its ConfigMapSelector selects
+func ConfigMapSetForCatalogSource(spec v1alpha1.CatalogSourceSpec) (ConfigMapSet, error) {
+	set := ConfigMapSet{}
+	if spec.ConfigMap != "" {
+		set.Names = append(set.Names, spec.ConfigMap)
+	}
+	set.Names = append(set.Names, spec.ConfigMaps...)
+	if spec.ConfigMapSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(spec.ConfigMapSelector)
+		if err != nil {
+			return set, fmt.Errorf("invalid ConfigMap selector: %s", err)
+		}
+		set.Selector = selector
+	}
+	return set, nil
+}
+
+func (s ConfigMapSet) String() string {
+	names := strings.Join(s.Names, ", ")
+	if s.Selector == nil {
+		return names
+	}
+	if names == "" {
+		return fmt.Sprintf("selected by %q", s.Selector.String())
+	}
+	return fmt.Sprintf("%s and those selected by %q", names, s.Selector.String())
+}
+
 // ConfigMapCatalogResourceLoader loads a ConfigMap of resources into the in-memory catalog
 type ConfigMapCatalogResourceLoader struct {
 	namespace string
 	opClient  operatorclient.ClientInterface
+	// verifier, if set, must accept the ConfigMap's signature before anything is loaded from it
+	verifier *CatalogVerifier
+	// lister, if set, reads ConfigMaps from an informer's cache instead of the api server
+	lister corelisters.ConfigMapNamespaceLister
 }
 
 func NewConfigMapCatalogResourceLoader(namespace string, opClient operatorclient.ClientInterface) ConfigMapCatalogResourceLoader {
@@ -33,91 +87,337 @@ func NewConfigMapCatalogResourceLoader(namespace string, opClient operatorclient
 	}
 }
 
+// NewVerifyingConfigMapCatalogResourceLoader creates a loader that only loads ConfigMaps signed by a key trusted by verifier
+func NewVerifyingConfigMapCatalogResourceLoader(namespace string, opClient operatorclient.ClientInterface, verifier *CatalogVerifier) ConfigMapCatalogResourceLoader {
+	return ConfigMapCatalogResourceLoader{
+		namespace: namespace,
+		opClient:  opClient,
+		verifier:  verifier,
+	}
+}
+
+// WithLister returns a copy of the loader that reads ConfigMaps from a lister of the loader's namespace, so that
+// loading a catalog doesn't call the api server
+func (d ConfigMapCatalogResourceLoader) WithLister(lister corelisters.ConfigMapNamespaceLister) ConfigMapCatalogResourceLoader {
+	d.lister = lister
+	return d
+}
+
 func (d *ConfigMapCatalogResourceLoader) LoadCatalogResources(catalog *InMem, configMapName string) error {
-	log.Debugf("Load ConfigMap     -- BEGIN %s", configMapName)
+	return d.LoadCatalogResourcesFromConfigMapSet(catalog, ConfigMapSet{Names: []string{configMapName}})
+}
 
-	cm, err := d.opClient.KubernetesInterface().CoreV1().ConfigMaps(d.namespace).Get(configMapName, metav1.GetOptions{})
+// LoadCatalogResourcesFromConfigMapSet loads a catalog split across a set of ConfigMaps
+func (d *ConfigMapCatalogResourceLoader) LoadCatalogResourcesFromConfigMapSet(catalog *InMem, set ConfigMapSet) error {
+	cms, err := d.GetConfigMaps(set)
 	if err != nil {
-		log.Debugf("Load ConfigMap     -- ERROR %s : error=%s", configMapName, err)
-		return fmt.Errorf("error loading catalog from ConfigMap %s: %s", configMapName, err)
+		return err
+	}
+	return d.LoadCatalogResourcesFromConfigMaps(catalog, cms)
+}
+
+// GetConfigMaps gets the ConfigMaps in a set, sorted by name
+func (d *ConfigMapCatalogResourceLoader) GetConfigMaps(set ConfigMapSet) ([]*v1.ConfigMap, error) {
+	byName := map[string]*v1.ConfigMap{}
+	for _, name := range set.Names {
+		log.Debugf("Load ConfigMap     -- BEGIN %s", name)
+		cm, err := d.getConfigMap(name)
+		if err != nil {
+			log.Debugf("Load ConfigMap     -- ERROR %s : error=%s", name, err)
+			return nil, fmt.Errorf("error loading catalog from ConfigMap %s: %s", name, err)
+		}
+		byName[name] = cm
+	}
+	if set.Selector != nil {
+		selected, err := d.listConfigMaps(set.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("error listing catalog ConfigMaps selected by %q: %s", set.Selector.String(), err)
+		}
+		for _, cm := range selected {
+			byName[cm.GetName()] = cm
+		}
 	}
-	return d.LoadCatalogResourcesFromConfigMap(catalog, cm)
+	if len(byName) == 0 {
+		return nil, fmt.Errorf("error loading catalog: no ConfigMaps in %s", set)
+	}
+
+	cms := make([]*v1.ConfigMap, 0, len(byName))
+	for _, cm := range byName {
+		cms = append(cms, cm)
+	}
+	sort.Slice(cms, func(i, j int) bool { return cms[i].GetName() < cms[j].GetName() })
+	return cms, nil
+}
+
+func (d *ConfigMapCatalogResourceLoader) getConfigMap(name string) (*v1.ConfigMap, error) {
+	if d.lister != nil {
+		return d.lister.Get(name)
+	}
+	return d.opClient.KubernetesInterface().CoreV1().ConfigMaps(d.namespace).Get(name, metav1.GetOptions{})
+}
+
+func (d *ConfigMapCatalogResourceLoader) listConfigMaps(selector labels.Selector) ([]*v1.ConfigMap, error) {
+	if d.lister != nil {
+		return d.lister.List(selector)
+	}
+	list, err := d.opClient.KubernetesInterface().CoreV1().ConfigMaps(d.namespace).List(metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	cms := make([]*v1.ConfigMap, 0, len(list.Items))
+	for i := range list.Items {
+		cms = append(cms, &list.Items[i])
+	}
+	return cms, nil
+}
+
+// ReferenceConfigMaps returns references to catalog ConfigMaps, each with the hash of its content, and a hash of the
+// content of them all
+func ReferenceConfigMaps(cms []*v1.ConfigMap) ([]v1alpha1.ConfigMapResourceReference, string) {
+	refs := make([]v1alpha1.ConfigMapResourceReference, 0, len(cms))
+	all := sha256.New()
+	for _, cm := range cms {
+		hash := configMapHash(cm)
+		refs = append(refs, v1alpha1.ConfigMapResourceReference{
+			Name:            cm.GetName(),
+			Namespace:       cm.GetNamespace(),
+			UID:             cm.GetUID(),
+			ResourceVersion: cm.GetResourceVersion(),
+			Hash:            hash,
+		})
+		all.Write([]byte(cm.GetName()))
+		all.Write([]byte{0})
+		all.Write([]byte(hash))
+		all.Write([]byte{0})
+	}
+	return refs, hex.EncodeToString(all.Sum(nil))
+}
+
+// configMapHash is the sha256 of every data and binaryData entry of a ConfigMap, hex encoded
+func configMapHash(cm *v1.ConfigMap) string {
+	return hex.EncodeToString(configMapDigest(cm))
+}
+
+// configMapDigest is the sha256 of the data and binaryData entries of a ConfigMap, except the skipped keys, in key order
+func configMapDigest(cm *v1.ConfigMap, skip ...string) []byte {
+	keys := []string{}
+	for key := range cm.Data {
+		keys = append(keys, key)
+	}
+	for key := range cm.BinaryData {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	hash := sha256.New()
+	for _, key := range keys {
+		if containsString(skip, key) {
+			continue
+		}
+		hash.Write([]byte(key))
+		hash.Write([]byte{0})
+		if value, ok := cm.Data[key]; ok {
+			hash.Write([]byte(value))
+		} else {
+			hash.Write(cm.BinaryData[key])
+		}
+		hash.Write([]byte{0})
+	}
+	return hash.Sum(nil)
 }
 
 func (d *ConfigMapCatalogResourceLoader) LoadCatalogResourcesFromConfigMap(catalog *InMem, cm *v1.ConfigMap) error {
+	return d.LoadCatalogResourcesFromConfigMaps(catalog, []*v1.ConfigMap{cm})
+}
+
+// configMapResources are the resources parsed from a catalog ConfigMap
+type configMapResources struct {
+	name     string
+	crds     []v1beta1.CustomResourceDefinition
+	csvs     []v1alpha1.ClusterServiceVersion
+	packages []PackageManifest
+}
+
+// LoadCatalogResourcesFromConfigMaps loads a catalog split across several ConfigMaps. The CRDs of every ConfigMap
+// are loaded before any CSVs, and the CSVs before any packages, so resources can refer to resources in other
+// ConfigMaps. Nothing is loaded if a resource is defined in more than one of the ConfigMaps.
+func (d *ConfigMapCatalogResourceLoader) LoadCatalogResourcesFromConfigMaps(catalog *InMem, cms []*v1.ConfigMap) error {
+	parsed := make([]configMapResources, 0, len(cms))
+	for _, cm := range cms {
+		resources, err := d.parseConfigMap(cm)
+		if err != nil {
+			return err
+		}
+		parsed = append(parsed, resources)
+	}
+	if err := findDuplicateDefinitions(parsed); err != nil {
+		return err
+	}
+
+	for _, resources := range parsed {
+		for _, crd := range resources.crds {
+			catalog.SetCRDDefinition(crd)
+		}
+	}
+	for _, resources := range parsed {
+		for _, csv := range resources.csvs {
+			catalog.setCSVDefinition(csv)
+		}
+	}
+	for _, resources := range parsed {
+		for _, packageManifest := range resources.packages {
+			if err := catalog.addPackageManifest(packageManifest); err != nil {
+				log.Debugf("Load ConfigMap     -- ERROR %s : error=%s", resources.name, err)
+				return err
+			}
+		}
+		if len(resources.packages) > 0 {
+			log.Debugf("Load ConfigMap      -- Found packages: %v", catalog.packages)
+		}
+		log.Debugf("Load ConfigMap     -- OK    %s", resources.name)
+	}
+	return nil
+}
+
+// findDuplicateDefinitions reports every resource defined in more than one ConfigMap
+func findDuplicateDefinitions(parsed []configMapResources) error {
+	definedIn := map[string][]string{}
+	define := func(resource, configMapName string) {
+		for _, name := range definedIn[resource] {
+			if name == configMapName {
+				return
+			}
+		}
+		definedIn[resource] = append(definedIn[resource], configMapName)
+	}
+	for _, resources := range parsed {
+		for _, crd := range resources.crds {
+			define(fmt.Sprintf("CRD %s", CRDKey{Kind: crd.Spec.Names.Kind, Name: crd.GetName(), Version: crd.Spec.Version}), resources.name)
+		}
+		for _, csv := range resources.csvs {
+			define(fmt.Sprintf("CSV %s", csv.GetName()), resources.name)
+		}
+		for _, pkg := range resources.packages {
+			define(fmt.Sprintf("package %s", pkg.PackageName), resources.name)
+		}
+	}
+
+	duplicates := []string{}
+	for resource, configMaps := range definedIn {
+		if len(configMaps) > 1 {
+			duplicates = append(duplicates, fmt.Sprintf("%s is defined in ConfigMaps %s", resource, strings.Join(configMaps, ", ")))
+		}
+	}
+	if len(duplicates) == 0 {
+		return nil
+	}
+	sort.Strings(duplicates)
+	return fmt.Errorf("error loading catalog: %s", strings.Join(duplicates, "; "))
+}
+
+func (d *ConfigMapCatalogResourceLoader) parseConfigMap(cm *v1.ConfigMap) (configMapResources, error) {
 	configMapName := cm.GetName()
-	found := false
-	crdListYaml, ok := cm.Data[ConfigMapCRDName]
-	if ok {
-		crdListJson, err := yaml.YAMLToJSON([]byte(crdListYaml))
+	resources := configMapResources{name: configMapName}
+	if d.verifier != nil {
+		signer, err := d.verifier.Verify(cm)
 		if err != nil {
 			log.Debugf("Load ConfigMap     -- ERROR %s : error=%s", configMapName, err)
-			return fmt.Errorf("error loading CRD list yaml from ConfigMap %s: %s", configMapName, err)
+			return resources, err
 		}
+		log.Debugf("Load ConfigMap     -- VERIFIED %s : signer=%s", configMapName, signer)
+	}
 
-		var parsedCRDList []v1beta1.CustomResourceDefinition
-		err = json.Unmarshal([]byte(crdListJson), &parsedCRDList)
+	crdListYaml, ok, err := catalogEntry(cm, ConfigMapCRDName)
+	if err != nil {
+		log.Debugf("Load ConfigMap     -- ERROR %s : error=%s", configMapName, err)
+		return resources, err
+	}
+	if ok {
+		crdListJson, err := yaml.YAMLToJSON([]byte(crdListYaml))
 		if err != nil {
 			log.Debugf("Load ConfigMap     -- ERROR %s : error=%s", configMapName, err)
-			return fmt.Errorf("error parsing CRD list (json) from ConfigMap %s: %s", configMapName, err)
+			return resources, fmt.Errorf("error loading CRD list yaml from ConfigMap %s: %s", configMapName, err)
 		}
 
-		for _, crd := range parsedCRDList {
-			found = true
-			catalog.SetCRDDefinition(crd)
+		err = json.Unmarshal([]byte(crdListJson), &resources.crds)
+		if err != nil {
+			log.Debugf("Load ConfigMap     -- ERROR %s : error=%s", configMapName, err)
+			return resources, fmt.Errorf("error parsing CRD list (json) from ConfigMap %s: %s", configMapName, err)
 		}
 	}
 
-	csvListYaml, ok := cm.Data[ConfigMapCSVName]
+	csvListYaml, ok, err := catalogEntry(cm, ConfigMapCSVName)
+	if err != nil {
+		log.Debugf("Load ConfigMap     -- ERROR %s : error=%s", configMapName, err)
+		return resources, err
+	}
 	if ok {
 		csvListJson, err := yaml.YAMLToJSON([]byte(csvListYaml))
 		if err != nil {
 			log.Debugf("Load ConfigMap     -- ERROR %s : error=%s", configMapName, err)
-			return fmt.Errorf("error loading CSV list yaml from ConfigMap %s: %s", configMapName, err)
+			return resources, fmt.Errorf("error loading CSV list yaml from ConfigMap %s: %s", configMapName, err)
 		}
 
-		var parsedCSVList []v1alpha1.ClusterServiceVersion
-		err = json.Unmarshal([]byte(csvListJson), &parsedCSVList)
+		err = json.Unmarshal([]byte(csvListJson), &resources.csvs)
 		if err != nil {
 			log.Debugf("Load ConfigMap     -- ERROR %s : error=%s", configMapName, err)
-			return fmt.Errorf("error parsing CSV list (json) from ConfigMap %s: %s", configMapName, err)
-		}
-
-		for _, csv := range parsedCSVList {
-			found = true
-			catalog.setCSVDefinition(csv)
+			return resources, fmt.Errorf("error parsing CSV list (json) from ConfigMap %s: %s", configMapName, err)
 		}
 	}
 
-	packageListYaml, ok := cm.Data[ConfigMapPackageName]
+	packageListYaml, ok, err := catalogEntry(cm, ConfigMapPackageName)
+	if err != nil {
+		log.Debugf("Load ConfigMap     -- ERROR %s : error=%s", configMapName, err)
+		return resources, err
+	}
 	if ok {
 		log.Debug("Load ConfigMap      -- ConfigMap contains packages")
 		packageListJson, err := yaml.YAMLToJSON([]byte(packageListYaml))
 		if err != nil {
 			log.Debugf("Load ConfigMap     -- ERROR %s : error=%s", configMapName, err)
-			return fmt.Errorf("error loading package list yaml from ConfigMap %s: %s", configMapName, err)
+			return resources, fmt.Errorf("error loading package list yaml from ConfigMap %s: %s", configMapName, err)
 		}
 
-		var parsedPackageManifests []PackageManifest
-		err = json.Unmarshal([]byte(packageListJson), &parsedPackageManifests)
+		err = json.Unmarshal([]byte(packageListJson), &resources.packages)
 		if err != nil {
 			log.Debugf("Load ConfigMap     -- ERROR %s : error=%s", configMapName, err)
-			return fmt.Errorf("error parsing package list (json) from ConfigMap %s: %s", configMapName, err)
-		}
-		for _, packageManifest := range parsedPackageManifests {
-			found = true
-			if err := catalog.addPackageManifest(packageManifest); err != nil {
-				return err
-			}
+			return resources, fmt.Errorf("error parsing package list (json) from ConfigMap %s: %s", configMapName, err)
 		}
-		log.Debugf("Load ConfigMap      -- Found packages: %v", catalog.packages)
 	}
 
-	if !found {
+	if len(resources.crds) == 0 && len(resources.csvs) == 0 && len(resources.packages) == 0 {
 		log.Debugf("Load ConfigMap     -- ERROR %s : no resources found", configMapName)
-		return fmt.Errorf("error parsing ConfigMap %s: no valid resources found", configMapName)
+		return resources, fmt.Errorf("error parsing ConfigMap %s: no valid resources found", configMapName)
 	}
+	return resources, nil
+}
 
-	log.Debugf("Load ConfigMap     -- OK    %s", configMapName)
-	return nil
+// catalogEntry returns a catalog entry of a ConfigMap. An entry is either plain YAML in data, or gzip compressed YAML
+// in binaryData under the same key.
+func catalogEntry(cm *v1.ConfigMap, key string) (string, bool, error) {
+	plain, inData := cm.Data[key]
+	compressed, inBinaryData := cm.BinaryData[key]
+	switch {
+	case inData && inBinaryData:
+		return "", false, fmt.Errorf("error loading ConfigMap %s: %s is in both data and binaryData", cm.GetName(), key)
+	case inData:
+		return plain, true, nil
+	case !inBinaryData:
+		return "", false, nil
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return "", false, fmt.Errorf("error decompressing %s from ConfigMap %s: %s", key, cm.GetName(), err)
+	}
+	defer gz.Close()
+	// refuse entries that decompress to more than the size limit
+	data, err := ioutil.ReadAll(&io.LimitedReader{R: gz, N: MaxConfigMapEntrySize + 1})
+	if err != nil {
+		return "", false, fmt.Errorf("error decompressing %s from ConfigMap %s: %s", key, cm.GetName(), err)
+	}
+	if len(data) > MaxConfigMapEntrySize {
+		return "", false, fmt.Errorf("error decompressing %s from ConfigMap %s: more than %d bytes", key, cm.GetName(), MaxConfigMapEntrySize)
+	}
+	return string(data), true, nil
 }
@@ -0,0 +1,159 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators/v1alpha1"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/operatorclient"
+)
+
+const (
+	testCRDs = `- metadata:
+    name: tests.example.com
+  spec:
+    group: example.com
+    version: v1
+    names:
+      kind: Test
+`
+	testCSVs = `- metadata:
+    name: test.v1
+  spec:
+    displayName: Test
+    customresourcedefinitions:
+      owned:
+      - name: tests.example.com
+        version: v1
+        kind: Test
+`
+	testUpgradeCSVs = `- metadata:
+    name: test.v2
+  spec:
+    displayName: Test
+    replaces: test.v1
+`
+	testPackages = `- packageName: test
+  channels:
+  - name: alpha
+    currentCSV: test.v2
+`
+)
+
+func splitConfigMap(name string, labels map[string]string, data map[string]string) *v1.ConfigMap {
+	return &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns", Labels: labels},
+		Data:       data,
+	}
+}
+
+func splitCatalog() []runtime.Object {
+	shard := map[string]string{"catalog": "split"}
+	return []runtime.Object{
+		// packages sort first, but refer to CSVs in other ConfigMaps
+		splitConfigMap("a-packages", shard, map[string]string{ConfigMapPackageName: testPackages}),
+		splitConfigMap("b-csvs", shard, map[string]string{ConfigMapCSVName: testCSVs}),
+		splitConfigMap("c-upgrades", nil, map[string]string{ConfigMapCSVName: testUpgradeCSVs}),
+		splitConfigMap("d-crds", nil, map[string]string{ConfigMapCRDName: testCRDs}),
+	}
+}
+
+func TestConfigMapSetForCatalogSource(t *testing.T) {
+	set, err := ConfigMapSetForCatalogSource(v1alpha1.CatalogSourceSpec{ConfigMap: "catalog"})
+	require.NoError(t, err)
+	require.Equal(t, ConfigMapSet{Names: []string{"catalog"}}, set)
+	require.Equal(t, "catalog", set.String())
+
+	set, err = ConfigMapSetForCatalogSource(v1alpha1.CatalogSourceSpec{
+		ConfigMap:         "catalog",
+		ConfigMaps:        []string{"more"},
+		ConfigMapSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"catalog": "split"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"catalog", "more"}, set.Names)
+	require.Equal(t, `catalog, more and those selected by "catalog=split"`, set.String())
+
+	_, err = ConfigMapSetForCatalogSource(v1alpha1.CatalogSourceSpec{
+		ConfigMapSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"not a label": "split"}},
+	})
+	require.Error(t, err)
+}
+
+func TestLoadCatalogResourcesFromConfigMapSet(t *testing.T) {
+	selector, err := metav1.LabelSelectorAsSelector(&metav1.LabelSelector{MatchLabels: map[string]string{"catalog": "split"}})
+	require.NoError(t, err)
+
+	tests := []struct {
+		description   string
+		objects       []runtime.Object
+		set           ConfigMapSet
+		expectedError string
+	}{
+		{
+			description: "Names",
+			objects:     splitCatalog(),
+			set:         ConfigMapSet{Names: []string{"d-crds", "c-upgrades", "b-csvs", "a-packages"}},
+		},
+		{
+			description: "NamesAndSelector",
+			objects:     splitCatalog(),
+			set:         ConfigMapSet{Names: []string{"c-upgrades", "d-crds", "b-csvs"}, Selector: selector},
+		},
+		{
+			description:   "MissingConfigMap",
+			objects:       splitCatalog(),
+			set:           ConfigMapSet{Names: []string{"missing"}, Selector: selector},
+			expectedError: `error loading catalog from ConfigMap missing: configmaps "missing" not found`,
+		},
+		{
+			description:   "MissingReference",
+			objects:       splitCatalog(),
+			set:           ConfigMapSet{Names: []string{"d-crds", "b-csvs", "a-packages"}},
+			expectedError: "Missing CSV with name test.v2",
+		},
+		{
+			description:   "NothingSelected",
+			set:           ConfigMapSet{Selector: selector},
+			expectedError: `error loading catalog: no ConfigMaps in selected by "catalog=split"`,
+		},
+		{
+			description: "Duplicates",
+			objects: append(splitCatalog(),
+				splitConfigMap("e-duplicates", nil, map[string]string{ConfigMapCRDName: testCRDs, ConfigMapCSVName: testCSVs}),
+			),
+			set:           ConfigMapSet{Names: []string{"c-upgrades", "d-crds", "e-duplicates"}, Selector: selector},
+			expectedError: "error loading catalog: CRD Test/tests.example.com/v1 is defined in ConfigMaps d-crds, e-duplicates; CSV test.v1 is defined in ConfigMaps b-csvs, e-duplicates",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockClient := operatorclient.NewMockClientInterface(ctrl)
+			mockClient.EXPECT().KubernetesInterface().Return(k8sfake.NewSimpleClientset(tt.objects...)).AnyTimes()
+
+			catalog, err := NewInMemoryFromConfigMapSet(mockClient, "ns", tt.set)
+			if tt.expectedError != "" {
+				require.EqualError(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+
+			csv, err := catalog.FindCSVForPackageNameUnderChannel("test", "alpha")
+			require.NoError(t, err)
+			require.Equal(t, "test.v2", csv.GetName())
+			replacement, err := catalog.FindReplacementCSVForName("test.v1")
+			require.NoError(t, err)
+			require.Equal(t, "test.v2", replacement.GetName())
+			latest, err := catalog.ListLatestCSVsForCRD(CRDKey{Kind: "Test", Name: "tests.example.com", Version: "v1"})
+			require.NoError(t, err)
+			require.Len(t, latest, 1)
+		})
+	}
+}
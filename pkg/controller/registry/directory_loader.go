@@ -2,6 +2,7 @@ package registry
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
@@ -14,19 +15,38 @@ import (
 // files ending in`.clusterserviceversion.yaml` will be parsed as CRDs
 type DirectoryCatalogResourceLoader struct {
 	Catalog *InMem
+
+	// Walk walks the directory, filepath.Walk if nil
+	Walk func(root string, walkFn filepath.WalkFunc) error
+	// ReadFile reads a file in the directory, ioutil.ReadFile if nil
+	ReadFile func(filename string) ([]byte, error)
+}
+
+func (d *DirectoryCatalogResourceLoader) walk(root string, walkFn filepath.WalkFunc) error {
+	if d.Walk == nil {
+		return filepath.Walk(root, walkFn)
+	}
+	return d.Walk(root, walkFn)
+}
+
+func (d *DirectoryCatalogResourceLoader) readFile(filename string) ([]byte, error) {
+	if d.ReadFile == nil {
+		return ioutil.ReadFile(filename)
+	}
+	return d.ReadFile(filename)
 }
 
 func (d *DirectoryCatalogResourceLoader) LoadCatalogResources(directory string) error {
 	log.Debugf("Load Dir     -- BEGIN %s", directory)
-	if err := filepath.Walk(directory, d.LoadCRDsWalkFunc); err != nil {
+	if err := d.walk(directory, d.LoadCRDsWalkFunc); err != nil {
 		log.Debugf("Load Dir     -- ERROR %s : CRD error=%s", directory, err)
 		return fmt.Errorf("error loading CRDs from directory %s: %s", directory, err)
 	}
-	if err := filepath.Walk(directory, d.LoadCSVsWalkFunc); err != nil {
+	if err := d.walk(directory, d.LoadCSVsWalkFunc); err != nil {
 		log.Debugf("Load Dir     -- ERROR %s : CSV error=%s", directory, err)
 		return fmt.Errorf("error loading CSVs from directory %s: %s", directory, err)
 	}
-	if err := filepath.Walk(directory, d.LoadPackagesWalkFunc); err != nil {
+	if err := d.walk(directory, d.LoadPackagesWalkFunc); err != nil {
 		log.Debugf("Load Dir     -- ERROR %s : PKG error=%s", directory, err)
 		return fmt.Errorf("error loading Packages from directory %s: %s", directory, err)
 	}
@@ -52,7 +72,11 @@ func (d *DirectoryCatalogResourceLoader) LoadCRDsWalkFunc(path string, f os.File
 		return nil
 	}
 	if strings.HasSuffix(path, ".crd.yaml") {
-		crd, err := LoadCRDFromFile(d.Catalog, path)
+		data, err := d.readFile(path)
+		if err != nil {
+			return fmt.Errorf("unable to load CRD from file %s: %v", path, err)
+		}
+		crd, err := loadCRD(d.Catalog, path, data)
 		if err != nil {
 			log.Debugf("Load CRD     -- ERROR %s", path)
 			return err
@@ -81,7 +105,11 @@ func (d *DirectoryCatalogResourceLoader) LoadCSVsWalkFunc(path string, f os.File
 		return nil
 	}
 	if strings.HasSuffix(path, ".clusterserviceversion.yaml") {
-		csv, err := LoadCSVFromFile(d.Catalog, path)
+		data, err := d.readFile(path)
+		if err != nil {
+			return fmt.Errorf("unable to load CSV from file %s: %v", path, err)
+		}
+		csv, err := loadCSV(d.Catalog, path, data)
 		if err != nil {
 			log.Debugf("Load CSV     -- ERROR %s", path)
 			return err
@@ -109,7 +137,11 @@ func (d *DirectoryCatalogResourceLoader) LoadPackagesWalkFunc(path string, f os.
 		return nil
 	}
 	if strings.HasSuffix(path, ".package.yaml") {
-		pkg, err := LoadPackageFromFile(d.Catalog, path)
+		data, err := d.readFile(path)
+		if err != nil {
+			return fmt.Errorf("unable to load package from file %s: %v", path, err)
+		}
+		pkg, err := loadPackage(d.Catalog, data)
 		if err != nil {
 			log.Debugf("Load Package     -- ERROR %s", path)
 			return err
@@ -0,0 +1,198 @@
+package registry
+
+import (
+	"fmt"
+	"path"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators/v1alpha1"
+)
+
+// PackageFilter hides the package channels of a catalog that Include doesn't match, or Exclude does. The zero value
+// hides nothing.
+type PackageFilter struct {
+	Include *v1alpha1.CatalogPackageFilter
+	Exclude *v1alpha1.CatalogPackageFilter
+}
+
+// PackageFilterForCatalogSource returns the package filter of a CatalogSource
+func PackageFilterForCatalogSource(spec v1alpha1.CatalogSourceSpec) PackageFilter {
+	return PackageFilter{Include: spec.Include, Exclude: spec.Exclude}
+}
+
+// IsZero reports whether the filter hides nothing
+func (f PackageFilter) IsZero() bool {
+	return f.Include == nil && f.Exclude == nil
+}
+
+// Validate checks that the package globs of the filter are well formed
+func (f PackageFilter) Validate() error {
+	for _, filter := range []*v1alpha1.CatalogPackageFilter{f.Include, f.Exclude} {
+		if filter == nil {
+			continue
+		}
+		for _, glob := range filter.Packages {
+			if _, err := path.Match(glob, ""); err != nil {
+				return fmt.Errorf("invalid package glob %q: %s", glob, err)
+			}
+		}
+	}
+	return nil
+}
+
+// exposes reports whether a channel of a package, with head at its head, is exposed by the filter
+func (f PackageFilter) exposes(packageName, channelName string, head *v1alpha1.ClusterServiceVersion) bool {
+	if f.Include != nil && !matchesPackageFilter(f.Include, packageName, channelName, head) {
+		return false
+	}
+	return f.Exclude == nil || !matchesPackageFilter(f.Exclude, packageName, channelName, head)
+}
+
+func matchesPackageFilter(filter *v1alpha1.CatalogPackageFilter, packageName, channelName string, head *v1alpha1.ClusterServiceVersion) bool {
+	if len(filter.Packages) > 0 && !matchesAnyGlob(filter.Packages, packageName) {
+		return false
+	}
+	if len(filter.Channels) > 0 && !containsString(filter.Channels, channelName) {
+		return false
+	}
+	if len(filter.Maturities) > 0 && !containsString(filter.Maturities, head.Spec.Maturity) {
+		return false
+	}
+	return len(filter.Providers) == 0 || containsString(filter.Providers, head.Spec.Provider.Name)
+}
+
+func matchesAnyGlob(globs []string, name string) bool {
+	for _, glob := range globs {
+		if ok, _ := path.Match(glob, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter returns a catalog holding only what the filter exposes of the catalog, and a record of what it hides. A zero
+// filter returns the catalog itself.
+func (m *InMem) Filter(f PackageFilter) (*InMem, *v1alpha1.CatalogFilterStatus, error) {
+	if f.IsZero() {
+		return m, nil, nil
+	}
+	filtered := NewInMem()
+	filtered.filter = f
+	status, err := m.FilterInto(filtered, f)
+	if err != nil {
+		return nil, nil, err
+	}
+	return filtered, status, nil
+}
+
+// FilterInto adds what the filter exposes of the catalog to dst, and returns a record of what it hides. A channel is
+// hidden with the CSVs in its replacement chain that aren't in an exposed channel, and a CRD is hidden with the CSVs
+// that own it. A hidden default channel is no longer the package's default.
+func (m *InMem) FilterInto(dst *InMem, f PackageFilter) (*v1alpha1.CatalogFilterStatus, error) {
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+
+	status := &v1alpha1.CatalogFilterStatus{}
+	packages := []PackageManifest{}
+	exposedCSVs := map[string]bool{}
+	for _, name := range sortedPackageNames(m.packages) {
+		pkg := m.packages[name]
+		exposed := pkg
+		exposed.Channels = []PackageChannel{}
+		hidden := []string{}
+		for _, channel := range pkg.Channels {
+			head, err := m.FindCSVByName(channel.CurrentCSVName)
+			if err != nil {
+				return nil, err
+			}
+			if !f.exposes(pkg.PackageName, channel.Name, head) {
+				hidden = append(hidden, pkg.PackageName+"/"+channel.Name)
+				continue
+			}
+			exposed.Channels = append(exposed.Channels, channel)
+			chain, err := m.fullCSVReplacesHistory(head)
+			if err != nil {
+				return nil, err
+			}
+			for _, csv := range chain {
+				exposedCSVs[csv.GetName()] = true
+			}
+		}
+		if len(exposed.Channels) == 0 {
+			status.Packages = append(status.Packages, pkg.PackageName)
+			continue
+		}
+		status.Channels = append(status.Channels, hidden...)
+		if exposed.DefaultChannelName != "" && containsString(hidden, pkg.PackageName+"/"+exposed.DefaultChannelName) {
+			exposed.DefaultChannelName = ""
+		}
+		packages = append(packages, exposed)
+	}
+
+	hiddenCSVs := map[string]bool{}
+	for name := range m.clusterservices {
+		// CSVs that aren't in any package can't be hidden by a package filter
+		if _, inPackage := m.csvPackageChannels[name]; inPackage && !exposedCSVs[name] {
+			hiddenCSVs[name] = true
+		}
+	}
+	for key, crd := range m.crds {
+		if owners := m.crdOwners[key]; len(owners) > 0 && allIn(owners, hiddenCSVs) {
+			status.CustomResourceDefinitions++
+			continue
+		}
+		if err := dst.SetCRDDefinition(crd); err != nil {
+			return nil, err
+		}
+	}
+	for name, csv := range m.clusterservices {
+		if hiddenCSVs[name] {
+			status.ClusterServiceVersions++
+			continue
+		}
+		if err := dst.setCSVDefinition(csv); err != nil {
+			return nil, err
+		}
+	}
+	for _, pkg := range packages {
+		if err := dst.addPackageManifest(pkg); err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+// FilteredBy reports whether the catalog was built by filtering with f
+func (m *InMem) FilteredBy(f PackageFilter) bool {
+	return equality.Semantic.DeepEqual(m.filter, f)
+}
+
+func sortedPackageNames(packages map[string]PackageManifest) []string {
+	names := make([]string, 0, len(packages))
+	for name := range packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func allIn(names []string, set map[string]bool) bool {
+	for _, name := range names {
+		if !set[name] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,103 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators/v1alpha1"
+)
+
+// channelCatalog has a package with an alpha channel at test.v2, which replaces test.v1 at the head of the default
+// stable channel
+func channelCatalog(t *testing.T) *InMem {
+	catalog := NewInMem()
+	require.NoError(t, catalog.SetCRDDefinition(v1beta1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "tests.example.com"},
+		Spec:       v1beta1.CustomResourceDefinitionSpec{Version: "v1", Names: v1beta1.CustomResourceDefinitionNames{Kind: "Test"}},
+	}))
+	owned := v1alpha1.CustomResourceDefinitions{Owned: []v1alpha1.CRDDescription{{Name: "tests.example.com", Version: "v1", Kind: "Test"}}}
+	require.NoError(t, catalog.setCSVDefinition(v1alpha1.ClusterServiceVersion{
+		ObjectMeta: metav1.ObjectMeta{Name: "test.v1"},
+		Spec:       v1alpha1.ClusterServiceVersionSpec{Maturity: "stable", CustomResourceDefinitions: owned},
+	}))
+	require.NoError(t, catalog.setCSVDefinition(v1alpha1.ClusterServiceVersion{
+		ObjectMeta: metav1.ObjectMeta{Name: "test.v2"},
+		Spec:       v1alpha1.ClusterServiceVersionSpec{Maturity: "alpha", Replaces: "test.v1", CustomResourceDefinitions: owned},
+	}))
+	require.NoError(t, catalog.addPackageManifest(PackageManifest{
+		PackageName:        "test",
+		DefaultChannelName: "stable",
+		Channels: []PackageChannel{
+			{Name: "alpha", CurrentCSVName: "test.v2"},
+			{Name: "stable", CurrentCSVName: "test.v1"},
+		},
+	}))
+	return catalog
+}
+
+func TestFilterPackages(t *testing.T) {
+	catalog, err := NewInMemoryFromDirectory(testCatalogDir)
+	require.NoError(t, err)
+
+	filter := PackageFilter{Include: &v1alpha1.CatalogPackageFilter{Packages: []string{"etcd", "prom*"}}}
+	filtered, status, err := catalog.Filter(filter)
+	require.NoError(t, err)
+	require.True(t, filtered.FilteredBy(filter))
+	require.False(t, catalog.FilteredBy(filter))
+
+	require.Equal(t, []string{"vault"}, status.Packages)
+	require.Empty(t, status.Channels)
+	before, after := catalog.Contents(), filtered.Contents()
+	require.Equal(t, 2, after.Packages)
+	require.Equal(t, before.ClusterServiceVersions-after.ClusterServiceVersions, status.ClusterServiceVersions)
+	require.Equal(t, before.CustomResourceDefinitions-after.CustomResourceDefinitions, status.CustomResourceDefinitions)
+	require.NotZero(t, status.CustomResourceDefinitions)
+
+	_, err = filtered.FindCSVForPackageNameUnderChannel("vault", "alpha")
+	require.Error(t, err)
+	_, err = filtered.FindCSVByName("vault-operator.0.1.9")
+	require.Error(t, err)
+	_, err = filtered.FindCSVForPackageNameUnderChannel("etcd", "alpha")
+	require.NoError(t, err)
+	_, err = filtered.FindCRDByKey(CRDKey{Kind: "EtcdCluster", Name: "etcdclusters.etcd.database.coreos.com", Version: "v1beta2"})
+	require.NoError(t, err)
+
+	_, status, err = catalog.Filter(PackageFilter{Exclude: &v1alpha1.CatalogPackageFilter{Providers: []string{"CoreOS, Inc"}, Maturities: []string{"alpha"}}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"etcd", "prometheus", "vault"}, status.Packages)
+	require.Equal(t, before.ClusterServiceVersions, status.ClusterServiceVersions)
+
+	same, status, err := catalog.Filter(PackageFilter{})
+	require.NoError(t, err)
+	require.True(t, same == catalog)
+	require.Nil(t, status)
+
+	_, _, err = catalog.Filter(PackageFilter{Exclude: &v1alpha1.CatalogPackageFilter{Packages: []string{"["}}})
+	require.EqualError(t, err, `invalid package glob "[": syntax error in pattern`)
+}
+
+func TestFilterChannels(t *testing.T) {
+	// test.v1 stays exposed in the alpha channel's replacement chain
+	filtered, status, err := channelCatalog(t).Filter(PackageFilter{Exclude: &v1alpha1.CatalogPackageFilter{Channels: []string{"stable"}}})
+	require.NoError(t, err)
+	require.Equal(t, &v1alpha1.CatalogFilterStatus{Channels: []string{"test/stable"}}, status)
+	pkg := filtered.AllPackages()["test"]
+	require.Equal(t, "", pkg.DefaultChannelName)
+	require.Len(t, pkg.Channels, 1)
+	_, err = filtered.FindCSVByName("test.v1")
+	require.NoError(t, err)
+
+	filtered, status, err = channelCatalog(t).Filter(PackageFilter{Include: &v1alpha1.CatalogPackageFilter{Maturities: []string{"stable"}}})
+	require.NoError(t, err)
+	require.Equal(t, &v1alpha1.CatalogFilterStatus{Channels: []string{"test/alpha"}, ClusterServiceVersions: 1}, status)
+	require.Equal(t, "stable", filtered.AllPackages()["test"].DefaultChannelName)
+	_, err = filtered.FindReplacementCSVForName("test.v1")
+	require.Error(t, err)
+	latest, err := filtered.ListLatestCSVsForCRD(CRDKey{Kind: "Test", Name: "tests.example.com", Version: "v1"})
+	require.NoError(t, err)
+	require.Len(t, latest, 1)
+	require.Equal(t, "test.v1", latest[0].CSV.GetName())
+}
@@ -0,0 +1,55 @@
+package registry
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/controller/install"
+)
+
+// CatalogImage is an image referenced by the install strategies of a catalog, along with the CSVs that reference it
+type CatalogImage struct {
+	Image string
+	CSVs  []string
+}
+
+// ListCatalogImages lists every image referenced by the deployments of every CSV in the catalog, sorted by image
+func ListCatalogImages(catalog *InMem) ([]CatalogImage, error) {
+	csvs, err := catalog.ListServices()
+	if err != nil {
+		return nil, err
+	}
+
+	resolver := install.StrategyResolver{}
+	referencedBy := map[string]map[string]struct{}{}
+	for _, csv := range csvs {
+		strategy, err := resolver.UnmarshalStrategy(csv.Spec.InstallStrategy)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing install strategy of %s: %s", csv.GetName(), err)
+		}
+		deploymentStrategy, ok := strategy.(*install.StrategyDetailsDeployment)
+		if !ok {
+			continue
+		}
+		for _, dep := range deploymentStrategy.DeploymentSpecs {
+			for _, image := range install.PodSpecImages(dep.Spec.Template.Spec) {
+				if _, ok := referencedBy[image]; !ok {
+					referencedBy[image] = map[string]struct{}{}
+				}
+				referencedBy[image][csv.GetName()] = struct{}{}
+			}
+		}
+	}
+
+	images := []CatalogImage{}
+	for image, csvNames := range referencedBy {
+		catalogImage := CatalogImage{Image: image}
+		for name := range csvNames {
+			catalogImage.CSVs = append(catalogImage.CSVs, name)
+		}
+		sort.Strings(catalogImage.CSVs)
+		images = append(images, catalogImage)
+	}
+	sort.Slice(images, func(i, j int) bool { return images[i].Image < images[j].Image })
+	return images, nil
+}
@@ -0,0 +1,23 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListCatalogImages(t *testing.T) {
+	catalog, err := NewInMemoryFromDirectory("../../../deploy/chart/catalog_resources/ocs")
+	require.NoError(t, err)
+
+	images, err := ListCatalogImages(catalog)
+	require.NoError(t, err)
+	require.Len(t, images, 8)
+	require.Contains(t, images, CatalogImage{
+		Image: "quay.io/coreos/etcd-operator@sha256:c0301e4686c3ed4206e370b42de5a3bd2229b9fb4906cf85f3f30650424abec2",
+		CSVs:  []string{"etcdoperator.v0.9.2"},
+	})
+	for i := 1; i < len(images); i++ {
+		require.True(t, images[i-1].Image < images[i].Image, "images should be sorted")
+	}
+}
@@ -19,11 +19,15 @@ func LoadCRDFromFile(m *InMem, filepath string) (*v1beta1.CustomResourceDefiniti
 	if err != nil {
 		return nil, fmt.Errorf("unable to load CRD from file %s: %v", filepath, err)
 	}
+	return loadCRD(m, filepath, data)
+}
+
+func loadCRD(m *InMem, filepath string, data []byte) (*v1beta1.CustomResourceDefinition, error) {
 	crd := v1beta1.CustomResourceDefinition{}
-	if _, _, err = scheme.Codecs.UniversalDecoder().Decode(data, nil, &crd); err != nil {
+	if _, _, err := scheme.Codecs.UniversalDecoder().Decode(data, nil, &crd); err != nil {
 		return nil, fmt.Errorf("could not decode contents of file %s into CRD: %v", filepath, err)
 	}
-	if err = m.SetCRDDefinition(crd); err != nil {
+	if err := m.SetCRDDefinition(crd); err != nil {
 		return nil, fmt.Errorf("unable to set CRD found in catalog: %v", err)
 	}
 	return &crd, nil
@@ -35,11 +39,15 @@ func LoadCSVFromFile(m *InMem, filepath string) (*v1alpha1.ClusterServiceVersion
 	if err != nil {
 		return nil, fmt.Errorf("unable to load CSV from file %s: %v", filepath, err)
 	}
+	return loadCSV(m, filepath, data)
+}
+
+func loadCSV(m *InMem, filepath string, data []byte) (*v1alpha1.ClusterServiceVersion, error) {
 	csv := v1alpha1.ClusterServiceVersion{}
-	if _, _, err = scheme.Codecs.UniversalDecoder().Decode(data, nil, &csv); err != nil {
+	if _, _, err := scheme.Codecs.UniversalDecoder().Decode(data, nil, &csv); err != nil {
 		return nil, fmt.Errorf("could not decode contents of file %s into CSV: %v", filepath, err)
 	}
-	if err = m.setCSVDefinition(csv); err != nil {
+	if err := m.setCSVDefinition(csv); err != nil {
 		return nil, fmt.Errorf("unable to set CSV found in catalog: %v", err)
 	}
 	return &csv, nil
@@ -51,6 +59,10 @@ func LoadPackageFromFile(m *InMem, filepath string) (*PackageManifest, error) {
 	if err != nil {
 		return nil, fmt.Errorf("unable to load package from file %s: %v", filepath, err)
 	}
+	return loadPackage(m, data)
+}
+
+func loadPackage(m *InMem, data []byte) (*PackageManifest, error) {
 	pkg := PackageManifest{}
 
 	packageJson, err := yaml.YAMLToJSON(data)
@@ -32,6 +32,9 @@ type InMem struct {
 
 	// map from CSV name to the package channel(s) that contain it.
 	csvPackageChannels map[string][]packageAndChannel
+
+	// the package filter the catalog was built with, if any
+	filter PackageFilter
 }
 
 type packageAndChannel struct {
@@ -41,7 +44,7 @@ type packageAndChannel struct {
 
 func NewInMemoryFromDirectory(directory string) (*InMem, error) {
 	log.Infof("loading catalog from directory: %s", directory)
-	loader := DirectoryCatalogResourceLoader{NewInMem()}
+	loader := DirectoryCatalogResourceLoader{Catalog: NewInMem()}
 	if err := loader.LoadCatalogResources(directory); err != nil {
 		return nil, err
 	}
@@ -49,10 +52,33 @@ func NewInMemoryFromDirectory(directory string) (*InMem, error) {
 }
 
 func NewInMemoryFromConfigMap(cmClient operatorclient.ClientInterface, namespace, cmName string) (*InMem, error) {
-	log.Infof("loading catalog from a configmap: %s", cmName)
-	loader := ConfigMapCatalogResourceLoader{namespace, cmClient}
+	return NewInMemoryFromConfigMapSet(cmClient, namespace, ConfigMapSet{Names: []string{cmName}})
+}
+
+// NewInMemoryFromConfigMapSet loads a catalog split across a set of ConfigMaps
+func NewInMemoryFromConfigMapSet(cmClient operatorclient.ClientInterface, namespace string, set ConfigMapSet) (*InMem, error) {
+	log.Infof("loading catalog from configmaps: %s", set)
+	loader := NewConfigMapCatalogResourceLoader(namespace, cmClient)
+	catalog := NewInMem()
+	if err := loader.LoadCatalogResourcesFromConfigMapSet(catalog, set); err != nil {
+		return nil, err
+	}
+	return catalog, nil
+}
+
+// NewInMemoryFromVerifiedConfigMap loads a catalog from a ConfigMap, failing with a CatalogVerificationError unless
+// the ConfigMap is signed by a key trusted by verifier
+func NewInMemoryFromVerifiedConfigMap(cmClient operatorclient.ClientInterface, namespace, cmName string, verifier *CatalogVerifier) (*InMem, error) {
+	return NewInMemoryFromVerifiedConfigMapSet(cmClient, namespace, ConfigMapSet{Names: []string{cmName}}, verifier)
+}
+
+// NewInMemoryFromVerifiedConfigMapSet loads a catalog split across a set of ConfigMaps, failing with a
+// CatalogVerificationError unless every ConfigMap is signed by a key trusted by verifier
+func NewInMemoryFromVerifiedConfigMapSet(cmClient operatorclient.ClientInterface, namespace string, set ConfigMapSet, verifier *CatalogVerifier) (*InMem, error) {
+	log.Infof("loading catalog from verified configmaps: %s", set)
+	loader := NewVerifyingConfigMapCatalogResourceLoader(namespace, cmClient, verifier)
 	catalog := NewInMem()
-	if err := loader.LoadCatalogResources(catalog, cmName); err != nil {
+	if err := loader.LoadCatalogResourcesFromConfigMapSet(catalog, set); err != nil {
 		return nil, err
 	}
 	return catalog, nil
@@ -70,6 +96,15 @@ func NewInMem() *InMem {
 	}
 }
 
+// Contents counts the packages, CSVs and CRDs in the catalog
+func (m *InMem) Contents() v1alpha1.CatalogContentsStatus {
+	return v1alpha1.CatalogContentsStatus{
+		Packages:                  len(m.packages),
+		ClusterServiceVersions:    len(m.clusterservices),
+		CustomResourceDefinitions: len(m.crds),
+	}
+}
+
 // SetCRDDefinition sets the full resource definition of a CRD in the stored map
 // only sets a new definition if one is not already set
 func (m *InMem) SetCRDDefinition(crd v1beta1.CustomResourceDefinition) error {
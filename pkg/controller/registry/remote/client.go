@@ -0,0 +1,237 @@
+package remote
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators/v1alpha1"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/controller/registry"
+)
+
+const (
+	DefaultTimeout  = 10 * time.Second
+	DefaultCacheTTL = time.Minute
+)
+
+// ClientConfig configures a Client. Zero values are replaced by defaults.
+type ClientConfig struct {
+	// Timeout bounds each request to the registry server
+	Timeout time.Duration
+	// CacheTTL is how long responses are served from the cache before the registry server is asked again
+	CacheTTL time.Duration
+	// Clock is used to expire cached responses
+	Clock clock.Clock
+}
+
+// Client is a registry.Source that queries a registry server.
+//
+// Responses are cached. When the registry server can't be reached, expired responses are served until it's back.
+type Client struct {
+	address string
+	base    string
+	http    *http.Client
+	ttl     time.Duration
+	clock   clock.Clock
+
+	mu    sync.Mutex
+	cache map[string]cachedResponse
+}
+
+type cachedResponse struct {
+	body    []byte
+	expires time.Time
+}
+
+var _ registry.Source = &Client{}
+
+// NewClient returns a client of the registry server at address, a host:port or a URL
+func NewClient(address string, config ClientConfig) *Client {
+	if config.Timeout == 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.CacheTTL == 0 {
+		config.CacheTTL = DefaultCacheTTL
+	}
+	if config.Clock == nil {
+		config.Clock = clock.RealClock{}
+	}
+	base := address
+	if !strings.Contains(base, "://") {
+		base = "http://" + base
+	}
+	return &Client{
+		address: address,
+		base:    strings.TrimSuffix(base, "/"),
+		http:    &http.Client{Timeout: config.Timeout},
+		ttl:     config.CacheTTL,
+		clock:   config.Clock,
+		cache:   map[string]cachedResponse{},
+	}
+}
+
+// Address returns the address the client was created for
+func (c *Client) Address() string {
+	return c.address
+}
+
+// Healthy returns an error unless the registry server is serving
+func (c *Client) Healthy() error {
+	resp, err := c.http.Get(c.base + HealthPath)
+	if err != nil {
+		return fmt.Errorf("registry server %s is unreachable: %s", c.base, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("registry server %s is unhealthy: %s", c.base, resp.Status)
+	}
+	return nil
+}
+
+// unavailableError is returned when the registry server couldn't answer a request
+type unavailableError struct {
+	error
+}
+
+// get decodes the response to a request into out, from the cache if it's there
+func (c *Client) get(path string, query url.Values, out interface{}) error {
+	request := c.base + path
+	if len(query) > 0 {
+		request += "?" + query.Encode()
+	}
+
+	c.mu.Lock()
+	cached, ok := c.cache[request]
+	c.mu.Unlock()
+	if ok && c.clock.Now().Before(cached.expires) {
+		return json.Unmarshal(cached.body, out)
+	}
+
+	body, err := c.fetch(request)
+	if _, unavailable := err.(unavailableError); unavailable && ok {
+		log.Warnf("%s, serving expired response", err)
+		return json.Unmarshal(cached.body, out)
+	}
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.cache[request] = cachedResponse{body: body, expires: c.clock.Now().Add(c.ttl)}
+	c.mu.Unlock()
+	return json.Unmarshal(body, out)
+}
+
+func (c *Client) fetch(request string) ([]byte, error) {
+	resp, err := c.http.Get(request)
+	if err != nil {
+		return nil, unavailableError{fmt.Errorf("error querying registry server: %s", err)}
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, unavailableError{fmt.Errorf("error reading registry server response: %s", err)}
+	}
+	if resp.StatusCode == http.StatusOK {
+		return body, nil
+	}
+
+	var errResp ErrorResponse
+	if err := json.Unmarshal(body, &errResp); err != nil || errResp.Error == "" {
+		errResp.Error = resp.Status
+	}
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return nil, unavailableError{fmt.Errorf("registry server error: %s", errResp.Error)}
+	}
+	return nil, fmt.Errorf("%s", errResp.Error)
+}
+
+func (c *Client) findCSV(path string, query url.Values) (*v1alpha1.ClusterServiceVersion, error) {
+	var csv *v1alpha1.ClusterServiceVersion
+	if err := c.get(path, query, &csv); err != nil {
+		return nil, err
+	}
+	return csv, nil
+}
+
+func crdQuery(key registry.CRDKey) url.Values {
+	return url.Values{ParamKind: {key.Kind}, ParamName: {key.Name}, ParamVersion: {key.Version}}
+}
+
+// ListPackages returns the packages in the catalog
+func (c *Client) ListPackages() (map[string]registry.PackageManifest, error) {
+	packages := map[string]registry.PackageManifest{}
+	if err := c.get(PackagesPath, nil, &packages); err != nil {
+		return nil, err
+	}
+	return packages, nil
+}
+
+// AllPackages returns the packages in the catalog. Since it can't report errors, the packages last listed are
+// returned if the registry server can't be queried, and the catalog is only empty if they never were.
+func (c *Client) AllPackages() map[string]registry.PackageManifest {
+	packages, err := c.ListPackages()
+	if err == nil {
+		return packages
+	}
+
+	c.mu.Lock()
+	cached, ok := c.cache[c.base+PackagesPath]
+	c.mu.Unlock()
+	packages = map[string]registry.PackageManifest{}
+	if !ok || json.Unmarshal(cached.body, &packages) != nil {
+		log.Warnf("error listing packages: %s", err)
+		return map[string]registry.PackageManifest{}
+	}
+	log.Warnf("error listing packages, serving the packages last listed: %s", err)
+	return packages
+}
+
+func (c *Client) FindCSVForPackageNameUnderChannel(packageName string, channelName string) (*v1alpha1.ClusterServiceVersion, error) {
+	return c.findCSV(CSVPath, url.Values{ParamPackage: {packageName}, ParamChannel: {channelName}})
+}
+
+func (c *Client) FindReplacementCSVForPackageNameUnderChannel(packageName string, channelName string, csvName string) (*v1alpha1.ClusterServiceVersion, error) {
+	return c.findCSV(ReplacementPath, url.Values{ParamPackage: {packageName}, ParamChannel: {channelName}, ParamCSV: {csvName}})
+}
+
+func (c *Client) FindReplacementCSVForName(name string) (*v1alpha1.ClusterServiceVersion, error) {
+	return c.findCSV(ReplacementPath, url.Values{ParamCSV: {name}})
+}
+
+func (c *Client) FindCSVByName(name string) (*v1alpha1.ClusterServiceVersion, error) {
+	return c.findCSV(CSVPath, url.Values{ParamName: {name}})
+}
+
+func (c *Client) ListServices() ([]v1alpha1.ClusterServiceVersion, error) {
+	var csvs []v1alpha1.ClusterServiceVersion
+	if err := c.get(CSVsPath, nil, &csvs); err != nil {
+		return nil, err
+	}
+	return csvs, nil
+}
+
+func (c *Client) FindCRDByKey(key registry.CRDKey) (*v1beta1.CustomResourceDefinition, error) {
+	var crd *v1beta1.CustomResourceDefinition
+	if err := c.get(CRDPath, crdQuery(key), &crd); err != nil {
+		return nil, err
+	}
+	return crd, nil
+}
+
+func (c *Client) ListLatestCSVsForCRD(key registry.CRDKey) ([]registry.CSVAndChannelInfo, error) {
+	var latest []registry.CSVAndChannelInfo
+	if err := c.get(LatestCSVsPath, crdQuery(key), &latest); err != nil {
+		return nil, err
+	}
+	return latest, nil
+}
@@ -0,0 +1,152 @@
+package remote
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/controller/registry"
+)
+
+func serveCatalog(t *testing.T) (*registry.InMem, *httptest.Server) {
+	catalog, err := registry.NewInMemoryFromDirectory("../../../../deploy/chart/catalog_resources/ocs")
+	require.NoError(t, err)
+	return catalog, httptest.NewServer(NewServer(catalog))
+}
+
+func TestClientMatchesServedCatalog(t *testing.T) {
+	catalog, server := serveCatalog(t)
+	defer server.Close()
+	client := NewClient(server.Listener.Addr().String(), ClientConfig{})
+
+	require.NoError(t, client.Healthy())
+	require.Equal(t, catalog.AllPackages(), client.AllPackages())
+
+	expectedCSVs, err := catalog.ListServices()
+	require.NoError(t, err)
+	csvs, err := client.ListServices()
+	require.NoError(t, err)
+	require.ElementsMatch(t, expectedCSVs, csvs)
+
+	csv, err := client.FindCSVByName("etcdoperator.v0.9.0")
+	require.NoError(t, err)
+	require.Equal(t, "etcdoperator.v0.9.0", csv.GetName())
+
+	csv, err = client.FindCSVForPackageNameUnderChannel("etcd", "alpha")
+	require.NoError(t, err)
+	expected, err := catalog.FindCSVForPackageNameUnderChannel("etcd", "alpha")
+	require.NoError(t, err)
+	require.Equal(t, expected, csv)
+
+	csv, err = client.FindReplacementCSVForPackageNameUnderChannel("etcd", "alpha", "etcdoperator.v0.9.0")
+	require.NoError(t, err)
+	require.Equal(t, "etcdoperator.v0.9.2", csv.GetName())
+
+	csv, err = client.FindReplacementCSVForName("etcdoperator.v0.9.0")
+	require.NoError(t, err)
+	require.Equal(t, "etcdoperator.v0.9.2", csv.GetName())
+
+	key := registry.CRDKey{Kind: "EtcdCluster", Name: "etcdclusters.etcd.database.coreos.com", Version: "v1beta2"}
+	crd, err := client.FindCRDByKey(key)
+	require.NoError(t, err)
+	require.Equal(t, key.Name, crd.GetName())
+
+	expectedLatest, err := catalog.ListLatestCSVsForCRD(key)
+	require.NoError(t, err)
+	latest, err := client.ListLatestCSVsForCRD(key)
+	require.NoError(t, err)
+	require.Equal(t, expectedLatest, latest)
+}
+
+func TestClientErrors(t *testing.T) {
+	_, server := serveCatalog(t)
+	defer server.Close()
+	client := NewClient(server.URL, ClientConfig{})
+
+	_, err := client.FindCSVByName("missing")
+	require.EqualError(t, err, "not found: ClusterServiceVersion missing")
+
+	_, err = client.FindCSVForPackageNameUnderChannel("etcd", "")
+	require.EqualError(t, err, `missing query parameter "channel"`)
+
+	packages := client.AllPackages()
+	require.NotEmpty(t, packages)
+
+	// the packages last listed are served while the registry server is unreachable
+	server.Close()
+	require.Error(t, client.Healthy())
+	require.Equal(t, packages, client.AllPackages())
+
+	_, err = NewClient(server.URL, ClientConfig{}).ListPackages()
+	require.Error(t, err)
+	require.Empty(t, NewClient(server.URL, ClientConfig{}).AllPackages())
+}
+
+func TestClientCache(t *testing.T) {
+	catalog, err := registry.NewInMemoryFromDirectory("../../../../deploy/chart/catalog_resources/ocs")
+	require.NoError(t, err)
+
+	requests := 0
+	available := true
+	handler := NewServer(catalog)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if !available {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	fakeClock := clock.NewFakeClock(time.Now())
+	client := NewClient(server.URL, ClientConfig{CacheTTL: time.Minute, Clock: fakeClock})
+
+	_, err = client.FindCSVByName("etcdoperator.v0.9.0")
+	require.NoError(t, err)
+	_, err = client.FindCSVByName("etcdoperator.v0.9.0")
+	require.NoError(t, err)
+	require.Equal(t, 1, requests)
+
+	// errors aren't cached
+	_, err = client.FindCSVByName("missing")
+	require.Error(t, err)
+	_, err = client.FindCSVByName("missing")
+	require.Error(t, err)
+	require.Equal(t, 3, requests)
+
+	// expired responses are refreshed
+	fakeClock.Step(2 * time.Minute)
+	_, err = client.FindCSVByName("etcdoperator.v0.9.0")
+	require.NoError(t, err)
+	require.Equal(t, 4, requests)
+
+	// and served while the registry server is unavailable
+	available = false
+	fakeClock.Step(2 * time.Minute)
+	csv, err := client.FindCSVByName("etcdoperator.v0.9.0")
+	require.NoError(t, err)
+	require.Equal(t, "etcdoperator.v0.9.0", csv.GetName())
+	require.Equal(t, 5, requests)
+
+	_, err = client.FindCSVByName("etcdoperator.v0.9.2")
+	require.EqualError(t, err, "registry server error: 503 Service Unavailable")
+}
+
+func TestClientTimeout(t *testing.T) {
+	unblock := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-unblock
+	}))
+	defer server.Close()
+	defer close(unblock)
+
+	client := NewClient(server.URL, ClientConfig{Timeout: 10 * time.Millisecond})
+	_, err := client.ListServices()
+	require.Error(t, err)
+	require.Error(t, client.Healthy())
+}
@@ -0,0 +1,42 @@
+// Package remote serves catalogs over the registry service protocol, and queries catalogs served that way.
+//
+// The protocol is JSON over HTTP. Each method of registry.Source is a GET request, with its arguments as query
+// parameters:
+//
+//	/v1/packages                                  AllPackages
+//	/v1/csvs                                      ListServices
+//	/v1/csv?name=                                 FindCSVByName
+//	/v1/csv?package=&channel=                     FindCSVForPackageNameUnderChannel
+//	/v1/replacement?csv=                          FindReplacementCSVForName
+//	/v1/replacement?package=&channel=&csv=        FindReplacementCSVForPackageNameUnderChannel
+//	/v1/crd?kind=&name=&version=                  FindCRDByKey
+//	/v1/crd/latest?kind=&name=&version=           ListLatestCSVsForCRD
+//
+// The response to a successful request is the result of the method. A failed request responds with a non-200
+// status and an ErrorResponse. /healthz responds with 200 OK while the server can serve requests.
+package remote
+
+const (
+	HealthPath      = "/healthz"
+	PackagesPath    = "/v1/packages"
+	CSVsPath        = "/v1/csvs"
+	CSVPath         = "/v1/csv"
+	ReplacementPath = "/v1/replacement"
+	CRDPath         = "/v1/crd"
+	LatestCSVsPath  = "/v1/crd/latest"
+)
+
+// Query parameters
+const (
+	ParamName    = "name"
+	ParamPackage = "package"
+	ParamChannel = "channel"
+	ParamCSV     = "csv"
+	ParamKind    = "kind"
+	ParamVersion = "version"
+)
+
+// ErrorResponse is the body of the response to a failed request
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
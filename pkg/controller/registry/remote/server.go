@@ -0,0 +1,134 @@
+package remote
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/controller/registry"
+)
+
+// NewServer returns a handler that serves source over the registry service protocol
+func NewServer(source registry.Source) http.Handler {
+	s := &server{source: source}
+	mux := http.NewServeMux()
+	mux.HandleFunc(HealthPath, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc(PackagesPath, s.handle(s.packages))
+	mux.HandleFunc(CSVsPath, s.handle(s.csvs))
+	mux.HandleFunc(CSVPath, s.handle(s.csv))
+	mux.HandleFunc(ReplacementPath, s.handle(s.replacement))
+	mux.HandleFunc(CRDPath, s.handle(s.crd))
+	mux.HandleFunc(LatestCSVsPath, s.handle(s.latestCSVs))
+	return mux
+}
+
+type server struct {
+	source registry.Source
+}
+
+// badRequest is returned by handlers when a request is missing arguments
+type badRequest struct {
+	error
+}
+
+func (s *server) handle(method func(url.Values) (interface{}, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{Error: fmt.Sprintf("method %s not allowed", r.Method)})
+			return
+		}
+		result, err := method(r.URL.Query())
+		if _, ok := err.(badRequest); ok {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		if err != nil {
+			writeJSON(w, http.StatusNotFound, ErrorResponse{Error: err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, result)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Warnf("error writing registry response: %s", err)
+	}
+}
+
+// required returns the values of query parameters, failing if any are empty
+func required(query url.Values, params ...string) ([]string, error) {
+	values := make([]string, len(params))
+	for i, param := range params {
+		values[i] = query.Get(param)
+		if values[i] == "" {
+			return nil, badRequest{fmt.Errorf("missing query parameter %q", param)}
+		}
+	}
+	return values, nil
+}
+
+func crdKey(query url.Values) (registry.CRDKey, error) {
+	values, err := required(query, ParamKind, ParamName, ParamVersion)
+	if err != nil {
+		return registry.CRDKey{}, err
+	}
+	return registry.CRDKey{Kind: values[0], Name: values[1], Version: values[2]}, nil
+}
+
+func (s *server) packages(query url.Values) (interface{}, error) {
+	return s.source.AllPackages(), nil
+}
+
+func (s *server) csvs(query url.Values) (interface{}, error) {
+	return s.source.ListServices()
+}
+
+func (s *server) csv(query url.Values) (interface{}, error) {
+	if query.Get(ParamName) != "" {
+		return s.source.FindCSVByName(query.Get(ParamName))
+	}
+	values, err := required(query, ParamPackage, ParamChannel)
+	if err != nil {
+		return nil, err
+	}
+	return s.source.FindCSVForPackageNameUnderChannel(values[0], values[1])
+}
+
+func (s *server) replacement(query url.Values) (interface{}, error) {
+	values, err := required(query, ParamCSV)
+	if err != nil {
+		return nil, err
+	}
+	if query.Get(ParamPackage) == "" && query.Get(ParamChannel) == "" {
+		return s.source.FindReplacementCSVForName(values[0])
+	}
+	pkg, err := required(query, ParamPackage, ParamChannel)
+	if err != nil {
+		return nil, err
+	}
+	return s.source.FindReplacementCSVForPackageNameUnderChannel(pkg[0], pkg[1], values[0])
+}
+
+func (s *server) crd(query url.Values) (interface{}, error) {
+	key, err := crdKey(query)
+	if err != nil {
+		return nil, err
+	}
+	return s.source.FindCRDByKey(key)
+}
+
+func (s *server) latestCSVs(query url.Values) (interface{}, error) {
+	key, err := crdKey(query)
+	if err != nil {
+		return nil, err
+	}
+	return s.source.ListLatestCSVsForCRD(key)
+}
@@ -1,7 +1,10 @@
 package resolver
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
@@ -56,16 +59,18 @@ func (resolver *MultiSourceResolver) resolveCSV(sourceRefs []registry.SourceRef,
 		}
 
 		var csvSourceKey registry.SourceKey
+		var csvSourceReason string
 		var csv *v1alpha1.ClusterServiceVersion
 		var err error
 
 		// Attempt to Get the full CSV object for the name from any
-		for _, ref := range sourceRefs {
+		for i, ref := range sourceRefs {
 			csv, err = ref.Source.FindCSVByName(currentName)
 
 			if err == nil {
 				// Found CSV
 				csvSourceKey = ref.SourceKey
+				csvSourceReason = sourceReason(sourceRefs, i)
 				break
 			}
 
@@ -117,6 +122,7 @@ func (resolver *MultiSourceResolver) resolveCSV(sourceRefs []registry.SourceRef,
 		// Set the catalog source name and namespace
 		step.CatalogSource = csvSourceKey.Name
 		step.CatalogSourceNamespace = csvSourceKey.Namespace
+		step.SourceReason = csvSourceReason
 
 		// Add the final step for the CSV to the plan.
 		log.Infof("finished step: %s", step.Name)
@@ -135,18 +141,20 @@ func (resolver *MultiSourceResolver) resolveCRDDescription(sourceRefs []registry
 	}
 	logger.Debug("resolving")
 	var crdSourceKey registry.SourceKey
+	var crdSourceReason string
 	var crd *v1beta1.CustomResourceDefinition
 	var source registry.Source
 	var err error
 
 	// Attempt to find the CRD in any other source if the CRD is not owned
-	for _, ref := range sourceRefs {
+	for i, ref := range sourceRefs {
 		source = ref.Source
 		crd, err = source.FindCRDByKey(crdKey)
 
 		if err == nil {
 			// Found the CRD
 			crdSourceKey = ref.SourceKey
+			crdSourceReason = sourceReason(sourceRefs, i)
 			break
 		}
 	}
@@ -172,6 +180,7 @@ func (resolver *MultiSourceResolver) resolveCRDDescription(sourceRefs []registry
 		// Set the catalog source name and namespace
 		step.CatalogSource = crdSourceKey.Name
 		step.CatalogSourceNamespace = crdSourceKey.Namespace
+		step.SourceReason = crdSourceReason
 
 		return step, "", err
 	}
@@ -190,21 +199,126 @@ func (resolver *MultiSourceResolver) resolveCRDDescription(sourceRefs []registry
 
 }
 
+// sourceReason tells why a resource was taken from the source at index found of the sources searched in order
+func sourceReason(sourceRefs []registry.SourceRef, found int) string {
+	ref := sourceRefs[found]
+	if ref.Preferred {
+		return "found in the preferred catalog source"
+	}
+	reason := fmt.Sprintf("found in the first catalog source by priority then name, with priority %d", ref.Priority)
+	if found > 0 {
+		searched := make([]string, 0, found)
+		for _, r := range sourceRefs[:found] {
+			searched = append(searched, r.SourceKey.Namespace+"/"+r.SourceKey.Name)
+		}
+		reason += "; not found in " + strings.Join(searched, ", ")
+	}
+	return reason
+}
+
 type stepResourceMap map[string][]v1alpha1.StepResource
 
+// Plan returns the steps of the plan in the order they're executed in: the CRDs by name, then the CSVs, each after the
+// CSVs in the plan that own the CRDs it requires. CSVs that don't depend on each other are ordered by name.
 func (srm stepResourceMap) Plan() []v1alpha1.Step {
-	steps := make([]v1alpha1.Step, 0)
+	var resources, csvs []v1alpha1.Step
 	for csvName, stepResSlice := range srm {
 		for _, stepRes := range stepResSlice {
-			steps = append(steps, v1alpha1.Step{
+			step := v1alpha1.Step{
 				Resolving: csvName,
 				Resource:  stepRes,
 				Status:    v1alpha1.StepStatusUnknown,
-			})
+			}
+			if stepRes.Kind == v1alpha1.ClusterServiceVersionKind {
+				csvs = append(csvs, step)
+			} else {
+				resources = append(resources, step)
+			}
 		}
 	}
+	sort.Slice(resources, func(i, j int) bool {
+		a, b := resources[i], resources[j]
+		if a.Resource.Kind != b.Resource.Kind {
+			return a.Resource.Kind < b.Resource.Kind
+		}
+		if a.Resource.Name != b.Resource.Name {
+			return a.Resource.Name < b.Resource.Name
+		}
+		return a.Resolving < b.Resolving
+	})
+
+	steps := make([]v1alpha1.Step, 0, len(resources)+len(csvs))
+	steps = append(steps, resources...)
+	return append(steps, orderCSVSteps(csvs)...)
+}
 
-	return steps
+// orderCSVSteps orders CSV steps so that a CSV comes after the CSVs that own the CRDs it requires, and otherwise by
+// name. CSVs in a dependency cycle are ordered by name after the rest.
+func orderCSVSteps(steps []v1alpha1.Step) []v1alpha1.Step {
+	sort.Slice(steps, func(i, j int) bool { return steps[i].Resource.Name < steps[j].Resource.Name })
+
+	owners := map[string]string{}
+	required := map[string][]string{}
+	for _, step := range steps {
+		var csv v1alpha1.ClusterServiceVersion
+		if err := json.Unmarshal([]byte(step.Resource.Manifest), &csv); err != nil {
+			log.Warnf("error reading the dependencies of CSV %s: %s", step.Resource.Name, err)
+			continue
+		}
+		for _, crd := range csv.Spec.CustomResourceDefinitions.Owned {
+			owners[crd.Name] = step.Resource.Name
+		}
+		for _, crd := range csv.Spec.CustomResourceDefinitions.Required {
+			required[step.Resource.Name] = append(required[step.Resource.Name], crd.Name)
+		}
+	}
+
+	// pending counts the providers of each CSV that aren't ordered yet
+	pending := map[string]int{}
+	dependents := map[string][]string{}
+	for _, step := range steps {
+		name := step.Resource.Name
+		providers := map[string]struct{}{}
+		for _, crd := range required[name] {
+			owner, ok := owners[crd]
+			if !ok || owner == name {
+				continue
+			}
+			if _, ok := providers[owner]; ok {
+				continue
+			}
+			providers[owner] = struct{}{}
+			dependents[owner] = append(dependents[owner], name)
+			pending[name]++
+		}
+	}
+
+	ordered := make([]v1alpha1.Step, 0, len(steps))
+	done := make([]bool, len(steps))
+	for len(ordered) < len(steps) {
+		next := -1
+		for i, step := range steps {
+			if !done[i] && pending[step.Resource.Name] == 0 {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			for i, step := range steps {
+				if !done[i] {
+					log.Warnf("CSV %s is in a dependency cycle", step.Resource.Name)
+					ordered = append(ordered, step)
+				}
+			}
+			break
+		}
+		done[next] = true
+		ordered = append(ordered, steps[next])
+		for _, dependent := range dependents[steps[next].Resource.Name] {
+			pending[dependent]--
+		}
+	}
+	return ordered
 }
 
 func (srm stepResourceMap) Combine(y stepResourceMap) {
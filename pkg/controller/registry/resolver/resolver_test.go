@@ -1,6 +1,7 @@
 package resolver
 
 import (
+	"encoding/json"
 	"errors"
 	"testing"
 
@@ -324,3 +325,67 @@ func crd(name, namespace string) v1beta1.CustomResourceDefinition {
 		},
 	}
 }
+
+func TestSourceReason(t *testing.T) {
+	refs := []registry.SourceRef{
+		{SourceKey: registry.SourceKey{Name: "preferred", Namespace: "ns"}, Preferred: true},
+		{SourceKey: registry.SourceKey{Name: "important", Namespace: "olm"}, Priority: 10},
+		{SourceKey: registry.SourceKey{Name: "another", Namespace: "olm"}},
+	}
+	require.Equal(t, "found in the preferred catalog source", sourceReason(refs, 0))
+	require.Equal(t, "found in the first catalog source by priority then name, with priority 10; not found in ns/preferred", sourceReason(refs, 1))
+	require.Equal(t, "found in the first catalog source by priority then name, with priority 0; not found in ns/preferred, olm/important", sourceReason(refs, 2))
+	require.Equal(t, "found in the first catalog source by priority then name, with priority 10", sourceReason(refs[1:], 0))
+}
+
+func TestStepResourceMapPlan(t *testing.T) {
+	csvStep := func(name string, owned, required []string) v1alpha1.StepResource {
+		manifest, err := json.Marshal(csv(name, "default", owned, required))
+		require.NoError(t, err)
+		return v1alpha1.StepResource{Name: name, Kind: csvKind, Manifest: string(manifest)}
+	}
+	crdStep := func(name string) v1alpha1.StepResource {
+		return v1alpha1.StepResource{Name: name, Kind: crdKind}
+	}
+
+	// app requires CRDs owned by db and cache, and db requires one owned by cache
+	srm := stepResourceMap{
+		"app":     {crdStep("apps"), csvStep("app", []string{"apps"}, []string{"dbs", "caches"})},
+		"db":      {crdStep("dbs"), csvStep("db", []string{"dbs"}, []string{"caches"})},
+		"cache":   {crdStep("caches"), csvStep("cache", []string{"caches"}, nil)},
+		"logging": {csvStep("logging", nil, nil)},
+	}
+	expected := []string{
+		"CustomResourceDefinition/apps",
+		"CustomResourceDefinition/caches",
+		"CustomResourceDefinition/dbs",
+		"ClusterServiceVersion/cache",
+		"ClusterServiceVersion/db",
+		"ClusterServiceVersion/app",
+		"ClusterServiceVersion/logging",
+	}
+
+	// the map is iterated in a different order every time
+	for i := 0; i < 10; i++ {
+		steps := srm.Plan()
+		actual := make([]string, 0, len(steps))
+		for _, step := range steps {
+			require.Equal(t, v1alpha1.StepStatusUnknown, step.Status)
+			actual = append(actual, step.Resource.Kind+"/"+step.Resource.Name)
+		}
+		require.Equal(t, expected, actual)
+	}
+
+	// CSVs in a dependency cycle are ordered by name after the rest
+	cycle := stepResourceMap{
+		"b": {csvStep("b", []string{"bs"}, []string{"as"})},
+		"a": {csvStep("a", []string{"as"}, []string{"bs"})},
+		"c": {csvStep("c", nil, []string{"as"})},
+		"d": {csvStep("d", nil, nil)},
+	}
+	names := []string{}
+	for _, step := range cycle.Plan() {
+		names = append(names, step.Resource.Name)
+	}
+	require.Equal(t, []string{"d", "a", "b", "c"}, names)
+}
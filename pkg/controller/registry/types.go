@@ -40,6 +40,10 @@ type SourceKey struct {
 type SourceRef struct {
 	SourceKey SourceKey
 	Source    Source
+	// Priority is the priority of the CatalogSource; higher priority sources are searched first
+	Priority int
+	// Preferred is set for the source the InstallPlan being resolved prefers, which is searched before all others
+	Preferred bool
 }
 
 // CRDKey contains metadata needed to uniquely identify a CRD
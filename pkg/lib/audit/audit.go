@@ -0,0 +1,255 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Action is the kind of mutation an audit record describes
+type Action string
+
+const (
+	ActionCreate Action = "Create"
+	ActionUpdate Action = "Update"
+	ActionDelete Action = "Delete"
+	// ActionApply is a create or update where it's unknown which one happened
+	ActionApply Action = "Apply"
+)
+
+// Correlation identifies the objects a mutation was made on behalf of, by UID. The service broker's instances are
+// identified by their instance ID.
+type Correlation struct {
+	Subscription          string `json:"subscription,omitempty"`
+	InstallPlan           string `json:"installPlan,omitempty"`
+	ClusterServiceVersion string `json:"clusterServiceVersion,omitempty"`
+	ServiceInstance       string `json:"serviceInstance,omitempty"`
+}
+
+// Record describes a single mutation of a cluster object
+type Record struct {
+	Time           time.Time              `json:"time"`
+	Actor          string                 `json:"actor"`
+	Action         Action                 `json:"action"`
+	Object         corev1.ObjectReference `json:"object"`
+	Diff           string                 `json:"diff,omitempty"`
+	CorrelationIDs Correlation            `json:"correlationIDs"`
+}
+
+// Sink stores audit records
+type Sink interface {
+	Write(record Record) error
+}
+
+// NewSink creates a Sink for a target: "stdout" (or "-") writes to stdout, an http:// or https:// URL POSTs each record
+// to the URL in the background, and anything else is a file path to append to. An empty target returns a nil Sink.
+func NewSink(target string) (Sink, error) {
+	switch {
+	case target == "":
+		return nil, nil
+	case target == "stdout" || target == "-":
+		return NewWriterSink(os.Stdout), nil
+	case strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://"):
+		return NewHTTPSink(target), nil
+	}
+	return NewFileSink(target)
+}
+
+type writerSink struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewWriterSink creates a Sink that writes records to w as JSON, one per line
+func NewWriterSink(w io.Writer) Sink {
+	return &writerSink{enc: json.NewEncoder(w)}
+}
+
+func (s *writerSink) Write(record Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.enc.Encode(record)
+}
+
+// NewFileSink creates a Sink that appends records to a file as JSON, one per line
+func NewFileSink(path string) (Sink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("error opening audit file %s: %s", path, err)
+	}
+	return NewWriterSink(file), nil
+}
+
+// httpSinkBuffer is how many records an http Sink holds while they wait to be sent
+const httpSinkBuffer = 1000
+
+type httpSink struct {
+	url     string
+	client  *http.Client
+	records chan Record
+	// dropped counts the records dropped because the buffer was full
+	dropped uint64
+}
+
+// NewHTTPSink creates a Sink that POSTs each record to url as JSON. Records are sent in the background, so a slow
+// endpoint doesn't hold up the mutations they describe; while the buffer of records waiting to be sent is full, new
+// records are dropped and counted.
+func NewHTTPSink(url string) Sink {
+	return newHTTPSink(url, httpSinkBuffer)
+}
+
+func newHTTPSink(url string, buffer int) *httpSink {
+	s := &httpSink{
+		url:     url,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		records: make(chan Record, buffer),
+	}
+	go s.run()
+	return s
+}
+
+func (s *httpSink) Write(record Record) error {
+	select {
+	case s.records <- record:
+		return nil
+	default:
+		dropped := atomic.AddUint64(&s.dropped, 1)
+		return fmt.Errorf("audit endpoint %s is falling behind, %d records dropped", s.url, dropped)
+	}
+}
+
+// run sends the buffered records until the process exits
+func (s *httpSink) run() {
+	for record := range s.records {
+		if err := s.send(record); err != nil {
+			log.Warnf("error sending audit record for %s %s %s/%s: %s", record.Action, record.Object.Kind, record.Object.Namespace, record.Object.Name, err)
+		}
+	}
+}
+
+func (s *httpSink) send(record Record) error {
+	body, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("audit endpoint %s returned %s", s.url, resp.Status)
+	}
+	return nil
+}
+
+// Auditor records mutations to a Sink on behalf of an actor. A nil Auditor records nothing.
+type Auditor struct {
+	sink  Sink
+	actor string
+}
+
+// NewAuditor creates an Auditor that records mutations made by actor, unless a record names its own actor. It returns
+// nil if sink is nil.
+func NewAuditor(sink Sink, actor string) *Auditor {
+	if sink == nil {
+		return nil
+	}
+	return &Auditor{sink: sink, actor: actor}
+}
+
+// for test stubbing
+var timeNow = time.Now
+
+// Record stamps and stores a record. Failing to store it is logged rather than failing the mutation it describes.
+func (a *Auditor) Record(record Record) {
+	if a == nil {
+		return
+	}
+	if record.Actor == "" {
+		record.Actor = a.actor
+	}
+	record.Time = timeNow().UTC()
+	if err := a.sink.Write(record); err != nil {
+		log.Warnf("error writing audit record for %s %s %s/%s: %s", record.Action, record.Object.Kind, record.Object.Namespace, record.Object.Name, err)
+	}
+}
+
+// Enabled reports if records are stored, for callers that need extra requests to describe a mutation
+func (a *Auditor) Enabled() bool {
+	return a != nil
+}
+
+// Reference creates an ObjectReference for an object of the given api version and kind
+func Reference(apiVersion, kind string, obj metav1.Object) corev1.ObjectReference {
+	return corev1.ObjectReference{
+		APIVersion: apiVersion,
+		Kind:       kind,
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+		UID:        obj.GetUID(),
+	}
+}
+
+// maxDiffDepth limits how deep into an object DiffSummary reports changed fields
+const maxDiffDepth = 3
+
+// DiffSummary lists the fields set in after whose values differ from before, as a comma separated list of dotted
+// paths. Fields only set in before are ignored, since they are usually defaulted by the api server.
+func DiffSummary(before, after interface{}) string {
+	beforeFields, err := toFields(before)
+	if err != nil {
+		return ""
+	}
+	afterFields, err := toFields(after)
+	if err != nil || beforeFields == nil {
+		return ""
+	}
+	paths := diffFields("", beforeFields, afterFields, 1)
+	sort.Strings(paths)
+	return strings.Join(paths, ",")
+}
+
+func toFields(obj interface{}) (interface{}, error) {
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var fields interface{}
+	err = json.Unmarshal(raw, &fields)
+	return fields, err
+}
+
+func diffFields(path string, before, after interface{}, depth int) []string {
+	afterMap, ok := after.(map[string]interface{})
+	beforeMap, beforeOk := before.(map[string]interface{})
+	if !ok || !beforeOk || depth > maxDiffDepth {
+		if reflect.DeepEqual(before, after) {
+			return nil
+		}
+		return []string{path}
+	}
+
+	paths := []string{}
+	for key, value := range afterMap {
+		fieldPath := key
+		if path != "" {
+			fieldPath = path + "." + key
+		}
+		paths = append(paths, diffFields(fieldPath, beforeMap[key], value, depth+1)...)
+	}
+	return paths
+}
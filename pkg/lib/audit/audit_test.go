@@ -0,0 +1,154 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testRecord() Record {
+	return Record{
+		Action: ActionCreate,
+		Object: Reference("v1", "Secret", &metav1.ObjectMeta{Namespace: "ns", Name: "pull-secret", UID: "secret-uid"}),
+		CorrelationIDs: Correlation{
+			Subscription: "sub-uid",
+			InstallPlan:  "ip-uid",
+		},
+	}
+}
+
+func TestAuditorRecord(t *testing.T) {
+	now := time.Date(2018, 7, 1, 12, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	var buf bytes.Buffer
+	auditor := NewAuditor(NewWriterSink(&buf), "catalog-operator")
+	auditor.Record(testRecord())
+
+	withActor := testRecord()
+	withActor.Actor = "system:serviceaccount:ns:installer"
+	auditor.Record(withActor)
+
+	decoder := json.NewDecoder(&buf)
+	var first, second Record
+	require.NoError(t, decoder.Decode(&first))
+	require.NoError(t, decoder.Decode(&second))
+
+	expected := testRecord()
+	expected.Time = now
+	expected.Actor = "catalog-operator"
+	require.Equal(t, expected, first)
+	require.Equal(t, "system:serviceaccount:ns:installer", second.Actor)
+
+	// a nil auditor records nothing
+	var none *Auditor
+	require.Nil(t, NewAuditor(nil, "catalog-operator"))
+	require.False(t, none.Enabled())
+	none.Record(testRecord())
+}
+
+func TestHTTPSink(t *testing.T) {
+	received := make(chan Record, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var record Record
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&record))
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		if record.Action == ActionDelete {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		received <- record
+	}))
+	defer server.Close()
+
+	sink, err := NewSink(server.URL)
+	require.NoError(t, err)
+
+	// records are sent in the background, and failing to send one doesn't stop the others
+	deleted := testRecord()
+	deleted.Action = ActionDelete
+	require.NoError(t, sink.Write(deleted))
+	require.NoError(t, sink.Write(testRecord()))
+	for _, expected := range []Action{ActionDelete, ActionCreate} {
+		select {
+		case record := <-received:
+			require.Equal(t, expected, record.Action)
+			require.Equal(t, testRecord().Object, record.Object)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for %s record", expected)
+		}
+	}
+}
+
+func TestHTTPSinkDropsWhenFull(t *testing.T) {
+	unblock := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-unblock
+	}))
+	defer server.Close()
+	defer close(unblock)
+
+	sink := newHTTPSink(server.URL, 1)
+	// the first record may be taken off the buffer before the second is written, so the buffer is full by the third
+	for i := 0; i < 3; i++ {
+		sink.Write(testRecord())
+	}
+	err := sink.Write(testRecord())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "records dropped")
+	require.True(t, atomic.LoadUint64(&sink.dropped) >= 1)
+}
+
+func TestFileSink(t *testing.T) {
+	file, err := ioutil.TempFile("", "audit")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+	defer os.Remove(file.Name())
+
+	for i := 0; i < 2; i++ {
+		sink, err := NewSink(file.Name())
+		require.NoError(t, err)
+		require.NoError(t, sink.Write(testRecord()))
+	}
+
+	// records are appended, one per line
+	data, err := ioutil.ReadFile(file.Name())
+	require.NoError(t, err)
+	require.Len(t, bytes.Split(bytes.TrimSpace(data), []byte("\n")), 2)
+
+	sink, err := NewSink("")
+	require.NoError(t, err)
+	require.Nil(t, sink)
+}
+
+func TestDiffSummary(t *testing.T) {
+	replicas := int32(1)
+	before := appsv1.DeploymentSpec{
+		Replicas: &replicas,
+		Template: corev1.PodTemplateSpec{
+			Spec: corev1.PodSpec{
+				Containers:    []corev1.Container{{Name: "operator", Image: "quay.io/coreos/operator:v1"}},
+				RestartPolicy: corev1.RestartPolicyAlways,
+			},
+		},
+	}
+	after := *before.DeepCopy()
+	after.Template.Spec.Containers[0].Image = "quay.io/coreos/operator:v2"
+	after.Template.Spec.RestartPolicy = ""
+	after.Template.Labels = map[string]string{"app": "operator"}
+
+	require.Equal(t, "template.metadata.labels,template.spec.containers", DiffSummary(before, after))
+	require.Equal(t, "", DiffSummary(before, before))
+	require.Equal(t, "", DiffSummary(nil, after))
+}
@@ -0,0 +1,52 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNext(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	tests := []struct {
+		schedule string
+		after    time.Time
+		expected time.Time
+	}{
+		{"* * * * *", time.Date(2018, 7, 4, 10, 15, 30, 0, time.UTC), time.Date(2018, 7, 4, 10, 16, 0, 0, time.UTC)},
+		{"0 22 * * 1-5", time.Date(2018, 7, 4, 10, 15, 0, 0, time.UTC), time.Date(2018, 7, 4, 22, 0, 0, 0, time.UTC)},
+		{"0 22 * * 1-5", time.Date(2018, 7, 6, 22, 0, 0, 0, time.UTC), time.Date(2018, 7, 9, 22, 0, 0, 0, time.UTC)},
+		{"*/15 9-17 * * *", time.Date(2018, 7, 4, 17, 45, 0, 0, time.UTC), time.Date(2018, 7, 5, 9, 0, 0, 0, time.UTC)},
+		{"30 2 1 */3 *", time.Date(2018, 7, 4, 0, 0, 0, 0, time.UTC), time.Date(2018, 10, 1, 2, 30, 0, 0, time.UTC)},
+		{"0 0 13 * 5", time.Date(2018, 7, 4, 0, 0, 0, 0, time.UTC), time.Date(2018, 7, 6, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2018, 7, 4, 0, 0, 0, 0, time.UTC), time.Date(2018, 7, 8, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2018, 7, 4, 0, 0, 0, 0, time.UTC), time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 2 *", time.Date(2018, 7, 4, 0, 0, 0, 0, time.UTC), time.Time{}},
+		// 02:30 doesn't exist in Berlin when daylight saving time starts
+		{"30 2 * * *", time.Date(2018, 3, 25, 0, 0, 0, 0, berlin), time.Date(2018, 3, 26, 2, 30, 0, 0, berlin)},
+		{"0 22 * * *", time.Date(2018, 7, 4, 20, 30, 0, 0, time.UTC).In(berlin), time.Date(2018, 7, 5, 22, 0, 0, 0, berlin)},
+	}
+	for _, tt := range tests {
+		schedule, err := Parse(tt.schedule)
+		require.NoError(t, err, tt.schedule)
+		require.True(t, tt.expected.Equal(schedule.Next(tt.after)), "%s after %s: expected %s, got %s", tt.schedule, tt.after, tt.expected, schedule.Next(tt.after))
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string]string{
+		"* * * *":     `invalid cron schedule "* * * *": expected 5 fields, found 4`,
+		"60 * * * *":  `invalid cron schedule "60 * * * *": invalid minute "60": expected 0-59`,
+		"* * 0 * *":   `invalid cron schedule "* * 0 * *": invalid day of month "0": expected 1-31`,
+		"* 5-1 * * *": `invalid cron schedule "* 5-1 * * *": invalid range in hour "5-1"`,
+		"*/0 * * * *": `invalid cron schedule "*/0 * * * *": invalid step in minute "*/0"`,
+		"* * * JAN *": `invalid cron schedule "* * * JAN *": invalid month "JAN": expected 1-12`,
+	}
+	for schedule, expected := range tests {
+		_, err := Parse(schedule)
+		require.EqualError(t, err, expected, schedule)
+	}
+}
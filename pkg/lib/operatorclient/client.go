@@ -34,6 +34,7 @@ type CustomResourceClient interface {
 	DeleteCustomResource(apiGroup, version, namespace, resourceKind, resourceName string) error
 	AtomicModifyCustomResource(apiGroup, version, namespace, resourceKind, resourceName string, f CustomResourceModifier, data interface{}) error
 	ListCustomResource(apiGroup, version, namespace, resourceKind string) (*CustomResourceList, error)
+	ListCustomResourceInAllNamespaces(apiGroup, version, resourcePlural string, limit int64) (*CustomResourceList, error)
 }
 
 // ServiceAccountClient contains methods for manipulating ServiceAccount.
@@ -65,8 +66,8 @@ var _ ClientInterface = &Client{}
 // Client is a kubernetes client that can talk to the API server.
 type Client struct {
 	config *rest.Config
-	*kubernetes.Clientset
-	extClientset *apiextensions.Clientset
+	kubernetes.Interface
+	extClientset apiextensions.Interface
 }
 
 // NewClient creates a kubernetes client or bails out on on failures.
@@ -89,9 +90,28 @@ func NewClient(kubeconfig string) ClientInterface {
 	return &Client{config, kubernetes.NewForConfigOrDie(config), apiextensions.NewForConfigOrDie(config)}
 }
 
+// NewClientFromConfig creates a kubernetes client from a rest config.
+func NewClientFromConfig(config *rest.Config) (ClientInterface, error) {
+	kubeClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	extClient, err := apiextensions.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{config, kubeClient, extClient}, nil
+}
+
+// NewClientFromInterfaces creates a kubernetes client from existing clientsets, such as the fakes used in tests.
+// Custom resources are read and written with raw requests, so they aren't supported by fake clientsets.
+func NewClientFromInterfaces(kubeClient kubernetes.Interface, extClient apiextensions.Interface) ClientInterface {
+	return &Client{Interface: kubeClient, extClientset: extClient}
+}
+
 // KubernetesInterface returns the Kubernetes interface.
 func (c *Client) KubernetesInterface() kubernetes.Interface {
-	return c.Clientset
+	return c.Interface
 }
 
 // ApiextensionsV1beta1Interface returns the API extention interface.
@@ -302,6 +302,31 @@ func (c *Client) ListCustomResource(apiGroup, version, namespace, resourceKind s
 	return &crList, nil
 }
 
+// ListCustomResourceInAllNamespaces lists the custom resources of the given plural resource name in every namespace,
+// or all of them if the resource is cluster scoped. A positive limit lists at most that many.
+func (c *Client) ListCustomResourceInAllNamespaces(apiGroup, version, resourcePlural string, limit int64) (*CustomResourceList, error) {
+	glog.V(4).Infof("LIST CUSTOM RESOURCE IN ALL NAMESPACES]: %s", resourcePlural)
+
+	var crList CustomResourceList
+
+	httpRestClient := c.extClientset.ApiextensionsV1beta1().RESTClient()
+	uri := fmt.Sprintf("/apis/%s/%s/%s", strings.ToLower(apiGroup), strings.ToLower(version), strings.ToLower(resourcePlural))
+	if limit > 0 {
+		uri += fmt.Sprintf("?limit=%d", limit)
+	}
+	glog.V(4).Infof("[GET]: %s", uri)
+	bytes, err := httpRestClient.Get().RequestURI(uri).DoRaw()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get custom resource list: %v", err)
+	}
+
+	if err := json.Unmarshal(bytes, &crList); err != nil {
+		return nil, err
+	}
+
+	return &crList, nil
+}
+
 // parseAPIVersion splits "coreos.com/v1" into
 // "coreos.com" and "v1".
 func parseAPIVersion(apiVersion string) (apiGroup, version string, err error) {
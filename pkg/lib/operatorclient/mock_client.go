@@ -199,6 +199,19 @@ func (mr *MockClientInterfaceMockRecorder) ListCustomResource(apiGroup, version,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCustomResource", reflect.TypeOf((*MockClientInterface)(nil).ListCustomResource), apiGroup, version, namespace, resourceKind)
 }
 
+// ListCustomResourceInAllNamespaces mocks base method
+func (m *MockClientInterface) ListCustomResourceInAllNamespaces(apiGroup, version, resourcePlural string, limit int64) (*CustomResourceList, error) {
+	ret := m.ctrl.Call(m, "ListCustomResourceInAllNamespaces", apiGroup, version, resourcePlural, limit)
+	ret0, _ := ret[0].(*CustomResourceList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCustomResourceInAllNamespaces indicates an expected call of ListCustomResourceInAllNamespaces
+func (mr *MockClientInterfaceMockRecorder) ListCustomResourceInAllNamespaces(apiGroup, version, resourcePlural, limit interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCustomResourceInAllNamespaces", reflect.TypeOf((*MockClientInterface)(nil).ListCustomResourceInAllNamespaces), apiGroup, version, resourcePlural, limit)
+}
+
 // CreateServiceAccount mocks base method
 func (m *MockClientInterface) CreateServiceAccount(arg0 *v10.ServiceAccount) (*v10.ServiceAccount, error) {
 	ret := m.ctrl.Call(m, "CreateServiceAccount", arg0)
@@ -558,6 +571,19 @@ func (mr *MockCustomResourceClientMockRecorder) ListCustomResource(apiGroup, ver
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCustomResource", reflect.TypeOf((*MockCustomResourceClient)(nil).ListCustomResource), apiGroup, version, namespace, resourceKind)
 }
 
+// ListCustomResourceInAllNamespaces mocks base method
+func (m *MockCustomResourceClient) ListCustomResourceInAllNamespaces(apiGroup, version, resourcePlural string, limit int64) (*CustomResourceList, error) {
+	ret := m.ctrl.Call(m, "ListCustomResourceInAllNamespaces", apiGroup, version, resourcePlural, limit)
+	ret0, _ := ret[0].(*CustomResourceList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCustomResourceInAllNamespaces indicates an expected call of ListCustomResourceInAllNamespaces
+func (mr *MockCustomResourceClientMockRecorder) ListCustomResourceInAllNamespaces(apiGroup, version, resourcePlural, limit interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCustomResourceInAllNamespaces", reflect.TypeOf((*MockCustomResourceClient)(nil).ListCustomResourceInAllNamespaces), apiGroup, version, resourcePlural, limit)
+}
+
 // MockServiceAccountClient is a mock of ServiceAccountClient interface
 type MockServiceAccountClient struct {
 	ctrl     *gomock.Controller
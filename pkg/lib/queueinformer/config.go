@@ -0,0 +1,84 @@
+package queueinformer
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const (
+	DefaultWorkers         = 1
+	DefaultMaxRetries      = 5
+	DefaultShutdownTimeout = 30 * time.Second
+	DefaultRetryBaseDelay  = 5 * time.Millisecond
+	DefaultRetryMaxDelay   = 1000 * time.Second
+	DefaultRetryQPS        = 10
+	DefaultRetryBurst      = 100
+)
+
+// DeadLetterHandler is called with a key that has failed to sync more times than an Operator retries, and the last
+// error syncing it
+type DeadLetterHandler func(key string, err error)
+
+// Config tunes how an Operator processes its queues. Zero values are replaced with defaults.
+type Config struct {
+	// Workers is the number of goroutines syncing keys from each queue
+	Workers int
+	// MaxRetries is the number of times a key that fails to sync is requeued before it's dead-lettered. A negative
+	// value retries forever.
+	MaxRetries int
+	// ShutdownTimeout is how long Run waits for in-flight syncs to finish once stopped
+	ShutdownTimeout time.Duration
+	// RetryBaseDelay and RetryMaxDelay bound the exponential backoff between retries of a key
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+	// RetryQPS and RetryBurst limit the overall rate of retries of all keys in a queue
+	RetryQPS   float64
+	RetryBurst int
+	// DeadLetterHandler is called with keys that have run out of retries. By default they are logged.
+	DeadLetterHandler DeadLetterHandler
+}
+
+// withDefaults returns a copy of the config with zero values replaced by defaults
+func (c Config) withDefaults() Config {
+	if c.Workers <= 0 {
+		c.Workers = DefaultWorkers
+	}
+	if c.MaxRetries == 0 {
+		c.MaxRetries = DefaultMaxRetries
+	}
+	if c.ShutdownTimeout <= 0 {
+		c.ShutdownTimeout = DefaultShutdownTimeout
+	}
+	if c.RetryBaseDelay <= 0 {
+		c.RetryBaseDelay = DefaultRetryBaseDelay
+	}
+	if c.RetryMaxDelay <= 0 {
+		c.RetryMaxDelay = DefaultRetryMaxDelay
+	}
+	if c.RetryQPS <= 0 {
+		c.RetryQPS = DefaultRetryQPS
+	}
+	if c.RetryBurst <= 0 {
+		c.RetryBurst = DefaultRetryBurst
+	}
+	if c.DeadLetterHandler == nil {
+		c.DeadLetterHandler = logDeadLetter
+	}
+	return c
+}
+
+// NewRateLimiter creates the rate limiter for retrying keys that fail to sync
+func (c Config) NewRateLimiter() workqueue.RateLimiter {
+	c = c.withDefaults()
+	return workqueue.NewMaxOfRateLimiter(
+		workqueue.NewItemExponentialFailureRateLimiter(c.RetryBaseDelay, c.RetryMaxDelay),
+		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(c.RetryQPS), c.RetryBurst)},
+	)
+}
+
+func logDeadLetter(key string, err error) {
+	log.Errorf("giving up on syncing %s: %s", key, err)
+}
@@ -1,6 +1,8 @@
 package queueinformer
 
 import (
+	"sync"
+
 	log "github.com/sirupsen/logrus"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
@@ -9,6 +11,11 @@ import (
 // SyncHandler is the function that reconciles the controlled object when seen
 type SyncHandler func(obj interface{}) error
 
+// DeleteHandler is the function that cleans up after a controlled object is deleted. It's given the last known state of
+// the object, which may be stale if the deletion was missed while disconnected from the api server. If cleanup still
+// fails after MaxRetries, the object is dropped along with the key.
+type DeleteHandler func(obj interface{}) error
+
 // QueueInformer ties an informer to a queue in order to process events from the informer
 // the informer watches objects of interest and adds objects to the queue for processing
 // the syncHandler is called for all objects on the queue
@@ -16,7 +23,47 @@ type QueueInformer struct {
 	queue                     workqueue.RateLimitingInterface
 	informer                  cache.SharedIndexInformer
 	syncHandler               SyncHandler
+	deleteHandler             DeleteHandler
 	resourceEventHandlerFuncs *cache.ResourceEventHandlerFuncs
+
+	// deleted holds the last known state of deleted objects until their DeleteHandler succeeds
+	deleted *deletedObjects
+}
+
+type deletedObjects struct {
+	sync.Mutex
+	objects map[string]interface{}
+}
+
+// markDeleted records the last known state of a deleted object for its DeleteHandler
+func (q *QueueInformer) markDeleted(key string, obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	q.deleted.Lock()
+	defer q.deleted.Unlock()
+	q.deleted.objects[key] = obj
+}
+
+// clearDeleted forgets a deleted object, e.g. because it has been cleaned up or recreated
+func (q *QueueInformer) clearDeleted(key string) {
+	if q.deleted == nil {
+		return
+	}
+	q.deleted.Lock()
+	defer q.deleted.Unlock()
+	delete(q.deleted.objects, key)
+}
+
+// lastDeleted returns the last known state of a deleted object that hasn't been cleaned up yet
+func (q *QueueInformer) lastDeleted(key string) (interface{}, bool) {
+	if q.deleted == nil {
+		return nil, false
+	}
+	q.deleted.Lock()
+	defer q.deleted.Unlock()
+	obj, ok := q.deleted.objects[key]
+	return obj, ok
 }
 
 // enqueue adds a key to the queue. If obj is a key already it gets added directly.
@@ -59,6 +106,7 @@ func (q *QueueInformer) defaultResourceEventHandlerFuncs() *cache.ResourceEventH
 			}
 
 			log.Infof("%s added", key)
+			q.clearDeleted(key)
 			q.enqueue(key)
 		},
 		DeleteFunc: func(obj interface{}) {
@@ -69,6 +117,11 @@ func (q *QueueInformer) defaultResourceEventHandlerFuncs() *cache.ResourceEventH
 
 			log.Infof("%s deleted", key)
 			q.queue.Forget(key)
+			if q.deleteHandler != nil {
+				// the delete handler is called when the key is processed
+				q.markDeleted(key, obj)
+				q.enqueue(key)
+			}
 		},
 		UpdateFunc: func(oldObj, newObj interface{}) {
 			key, ok := q.keyFunc(newObj)
@@ -83,22 +136,26 @@ func (q *QueueInformer) defaultResourceEventHandlerFuncs() *cache.ResourceEventH
 }
 
 // New creates a set of new queueinformers given a name, a set of informers, and a sync handler to handle the objects
-// that the operator is managing. Optionally, custom event handler funcs can be passed in (defaults will be provided)
-func New(queue workqueue.RateLimitingInterface, informers []cache.SharedIndexInformer, handler SyncHandler, funcs *cache.ResourceEventHandlerFuncs) []*QueueInformer {
+// that the operator is managing. Optionally, a delete handler to clean up after deleted objects and custom event
+// handler funcs can be passed in (the delete handler is only called by the default funcs)
+func New(queue workqueue.RateLimitingInterface, informers []cache.SharedIndexInformer, handler SyncHandler, deleteHandler DeleteHandler, funcs *cache.ResourceEventHandlerFuncs) []*QueueInformer {
 	queueInformers := []*QueueInformer{}
 	for _, informer := range informers {
-		queueInformers = append(queueInformers, NewInformer(queue, informer, handler, funcs))
+		queueInformers = append(queueInformers, NewInformer(queue, informer, handler, deleteHandler, funcs))
 	}
 	return queueInformers
 }
 
 // NewInformer creates a new queueinformer given a name, an informer, and a sync handler to handle the objects
-// that the operator is managing. Optionally, custom event handler funcs can be passed in (defaults will be provided)
-func NewInformer(queue workqueue.RateLimitingInterface, informer cache.SharedIndexInformer, handler SyncHandler, funcs *cache.ResourceEventHandlerFuncs) *QueueInformer {
+// that the operator is managing. Optionally, a delete handler to clean up after deleted objects and custom event
+// handler funcs can be passed in (the delete handler is only called by the default funcs)
+func NewInformer(queue workqueue.RateLimitingInterface, informer cache.SharedIndexInformer, handler SyncHandler, deleteHandler DeleteHandler, funcs *cache.ResourceEventHandlerFuncs) *QueueInformer {
 	queueInformer := &QueueInformer{
-		queue:       queue,
-		informer:    informer,
-		syncHandler: handler,
+		queue:         queue,
+		informer:      informer,
+		syncHandler:   handler,
+		deleteHandler: deleteHandler,
+		deleted:       &deletedObjects{objects: map[string]interface{}{}},
 	}
 	if funcs == nil {
 		queueInformer.resourceEventHandlerFuncs = queueInformer.defaultResourceEventHandlerFuncs()
@@ -2,34 +2,57 @@ package queueinformer
 
 import (
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/operatorclient"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
 )
 
 // An Operator is a collection of QueueInformers
 // OpClient is used to establish the connection to kubernetes
 type Operator struct {
 	queueInformers []*QueueInformer
-	OpClient       operatorclient.ClientInterface
+	// informers fill caches the operator reads from, without queueing their events
+	informers []cache.SharedIndexInformer
+	OpClient  operatorclient.ClientInterface
+	config    Config
 }
 
 // NewOperator creates a new Operator configured to manage the cluster defined in kubeconfig.
 func NewOperator(kubeconfig string, queueInformers ...*QueueInformer) (*Operator, error) {
-	opClient := operatorclient.NewClient(kubeconfig)
+	return NewOperatorWithConfig(kubeconfig, Config{}, queueInformers...)
+}
+
+// NewOperatorWithConfig creates a new Operator configured to manage the cluster defined in kubeconfig, that processes
+// its queues as configured.
+func NewOperatorWithConfig(kubeconfig string, config Config, queueInformers ...*QueueInformer) (*Operator, error) {
+	return NewOperatorFromClient(operatorclient.NewClient(kubeconfig), config, queueInformers...)
+}
+
+// NewOperatorFromClient creates a new Operator that manages the cluster opClient talks to, and processes its queues as
+// configured.
+func NewOperatorFromClient(opClient operatorclient.ClientInterface, config Config, queueInformers ...*QueueInformer) (*Operator, error) {
 	if queueInformers == nil {
 		queueInformers = []*QueueInformer{}
 	}
 	operator := &Operator{
 		OpClient:       opClient,
 		queueInformers: queueInformers,
+		config:         config.withDefaults(),
 	}
 	return operator, nil
 }
 
+// NewQueue creates a named queue that retries keys as configured for this operator
+func (o *Operator) NewQueue(name string) workqueue.RateLimitingInterface {
+	return workqueue.NewNamedRateLimitingQueue(o.config.NewRateLimiter(), name)
+}
+
 // RegisterQueueInformer adds a QueueInformer to this operator
 func (o *Operator) RegisterQueueInformer(queueInformer *QueueInformer) {
 	if o.queueInformers == nil {
@@ -38,11 +61,24 @@ func (o *Operator) RegisterQueueInformer(queueInformer *QueueInformer) {
 	o.queueInformers = append(o.queueInformers, queueInformer)
 }
 
+// RegisterInformer adds an informer whose cache the operator reads from, which is started and synced with the
+// QueueInformers but doesn't queue anything
+func (o *Operator) RegisterInformer(informer cache.SharedIndexInformer) {
+	o.informers = append(o.informers, informer)
+}
+
 // Run starts the operator's control loops
 func (o *Operator) Run(stopc <-chan struct{}) error {
-	for _, queueInformer := range o.queueInformers {
-		defer queueInformer.queue.ShutDown()
-	}
+	// runWorkers shuts the queues down once it's started, and queues can only be shut down once
+	workersStarted := false
+	defer func() {
+		if workersStarted {
+			return
+		}
+		for _, group := range o.queueGroups() {
+			group.queue.ShutDown()
+		}
+	}()
 
 	errChan := make(chan error)
 	go func() {
@@ -59,6 +95,9 @@ func (o *Operator) Run(stopc <-chan struct{}) error {
 	for _, queueInformer := range o.queueInformers {
 		hasSyncedCheckFns = append(hasSyncedCheckFns, queueInformer.informer.HasSynced)
 	}
+	for _, informer := range o.informers {
+		hasSyncedCheckFns = append(hasSyncedCheckFns, informer.HasSynced)
+	}
 
 	select {
 	case err := <-errChan:
@@ -74,29 +113,87 @@ func (o *Operator) Run(stopc <-chan struct{}) error {
 	for _, queueInformer := range o.queueInformers {
 		go queueInformer.informer.Run(stopc)
 	}
+	for _, informer := range o.informers {
+		go informer.Run(stopc)
+	}
 
 	log.Info("waiting for caches to sync...")
 	if ok := cache.WaitForCacheSync(stopc, hasSyncedCheckFns...); !ok {
 		return fmt.Errorf("failed to wait for caches to sync")
 	}
 
-	log.Info("starting workers...")
+	workersStarted = true
+	return o.runWorkers(stopc)
+}
+
+// queueGroup is a queue and the QueueInformers that share it
+type queueGroup struct {
+	queue          workqueue.RateLimitingInterface
+	queueInformers []*QueueInformer
+}
+
+// queueGroups groups the operator's QueueInformers by queue, in registration order
+func (o *Operator) queueGroups() []*queueGroup {
+	groups := []*queueGroup{}
+	byQueue := map[workqueue.RateLimitingInterface]*queueGroup{}
 	for _, queueInformer := range o.queueInformers {
-		go o.worker(queueInformer)
+		group, ok := byQueue[queueInformer.queue]
+		if !ok {
+			group = &queueGroup{queue: queueInformer.queue}
+			byQueue[queueInformer.queue] = group
+			groups = append(groups, group)
+		}
+		group.queueInformers = append(group.queueInformers, queueInformer)
+	}
+	return groups
+}
+
+// runWorkers processes each queue with the configured number of workers until stopc closes, then shuts the queues
+// down and waits for the workers to drain them, up to the shutdown timeout.
+func (o *Operator) runWorkers(stopc <-chan struct{}) error {
+	config := o.config.withDefaults()
+	groups := o.queueGroups()
+
+	log.Infof("starting %d workers per queue...", config.Workers)
+	var wg sync.WaitGroup
+	for _, group := range groups {
+		for i := 0; i < config.Workers; i++ {
+			wg.Add(1)
+			go func(group *queueGroup) {
+				defer wg.Done()
+				o.worker(group)
+			}(group)
+		}
 	}
 	<-stopc
-	return nil
+
+	log.Info("shutting down queues, waiting for workers to finish...")
+	for _, group := range groups {
+		group.queue.ShutDown()
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		log.Info("workers finished")
+		return nil
+	case <-time.After(config.ShutdownTimeout):
+		return fmt.Errorf("timed out after %s waiting for workers to finish", config.ShutdownTimeout)
+	}
 }
 
 // worker runs a worker thread that just dequeues items, processes them, and marks them done.
 // It enforces that the syncHandler is never invoked concurrently with the same key.
-func (o *Operator) worker(loop *QueueInformer) {
-	for o.processNextWorkItem(loop) {
+func (o *Operator) worker(group *queueGroup) {
+	for o.processNextWorkItem(group) {
 	}
 }
 
-func (o *Operator) processNextWorkItem(loop *QueueInformer) bool {
-	queue := loop.queue
+func (o *Operator) processNextWorkItem(group *queueGroup) bool {
+	queue := group.queue
 	key, quit := queue.Get()
 
 	if quit {
@@ -104,28 +201,55 @@ func (o *Operator) processNextWorkItem(loop *QueueInformer) bool {
 	}
 	defer queue.Done(key)
 
-	// requeue five times on error
-	if err := o.sync(loop, key.(string)); err != nil && queue.NumRequeues(key.(string)) < 5 {
+	err := o.sync(group, key.(string))
+	if err == nil {
+		queue.Forget(key)
+		return true
+	}
+
+	utilruntime.HandleError(errors.Wrap(err, fmt.Sprintf("Sync %q failed", key)))
+	config := o.config.withDefaults()
+	if config.MaxRetries < 0 || queue.NumRequeues(key) < config.MaxRetries {
 		log.Infof("retrying %s", key)
-		utilruntime.HandleError(errors.Wrap(err, fmt.Sprintf("Sync %q failed", key)))
 		queue.AddRateLimited(key)
 		return true
 	}
+
+	// out of retries; a deleted object whose cleanup keeps failing is given up on too
 	queue.Forget(key)
+	for _, loop := range group.queueInformers {
+		loop.clearDeleted(key.(string))
+	}
+	config.DeadLetterHandler(key.(string), err)
 	return true
 }
 
-func (o *Operator) sync(loop *QueueInformer, key string) error {
+func (o *Operator) sync(group *queueGroup, key string) error {
 	log.Infof("getting %s from queue", key)
-	obj, exists, err := loop.informer.GetIndexer().GetByKey(key)
-	if err != nil {
-		return err
+	for _, loop := range group.queueInformers {
+		obj, exists, err := loop.informer.GetIndexer().GetByKey(key)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return loop.syncHandler(obj)
+		}
 	}
 
-	if !exists {
-		// For now, we ignore the case where an object used to exist but no longer does
-		log.Infof("couldn't get %s from queue", key)
+	// the object no longer exists, clean up after it if it was seen being deleted
+	for _, loop := range group.queueInformers {
+		obj, deleted := loop.lastDeleted(key)
+		if !deleted {
+			continue
+		}
+		log.Infof("cleaning up deleted %s", key)
+		if err := loop.deleteHandler(obj); err != nil {
+			return err
+		}
+		loop.clearDeleted(key)
 		return nil
 	}
-	return loop.syncHandler(obj)
+
+	log.Infof("couldn't get %s from queue", key)
+	return nil
 }
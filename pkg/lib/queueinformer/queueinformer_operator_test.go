@@ -0,0 +1,201 @@
+package queueinformer
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func configMapInformer(t *testing.T, names ...string) cache.SharedIndexInformer {
+	informer := cache.NewSharedIndexInformer(&MockListWatcher{}, &corev1.ConfigMap{}, 0, cache.Indexers{})
+	for _, name := range names {
+		require.NoError(t, informer.GetIndexer().Add(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name}}))
+	}
+	return informer
+}
+
+func TestProcessNextWorkItemDeadLetters(t *testing.T) {
+	deadLetters := map[string]error{}
+	op := &Operator{config: Config{
+		MaxRetries:        2,
+		RetryBaseDelay:    time.Millisecond,
+		DeadLetterHandler: func(key string, err error) { deadLetters[key] = err },
+	}}
+	queue := op.NewQueue("test")
+	defer queue.ShutDown()
+
+	syncs := 0
+	op.RegisterQueueInformer(NewInformer(queue, configMapInformer(t, "failing"), func(obj interface{}) error {
+		syncs++
+		return errors.New("sync failed")
+	}, nil, nil))
+
+	group := op.queueGroups()[0]
+	queue.Add("ns/failing")
+	for i := 0; i < 3; i++ {
+		require.True(t, op.processNextWorkItem(group))
+	}
+
+	// the first sync and two retries fail, then the key is dead-lettered and forgotten
+	require.Equal(t, 3, syncs)
+	require.Equal(t, map[string]error{"ns/failing": errors.New("sync failed")}, deadLetters)
+	require.Equal(t, 0, queue.Len())
+	require.Equal(t, 0, queue.NumRequeues("ns/failing"))
+}
+
+func TestProcessNextWorkItemDeadLettersDeleted(t *testing.T) {
+	deadLetters := map[string]error{}
+	op := &Operator{config: Config{
+		MaxRetries:        1,
+		RetryBaseDelay:    time.Millisecond,
+		DeadLetterHandler: func(key string, err error) { deadLetters[key] = err },
+	}}
+	queue := op.NewQueue("test")
+	defer queue.ShutDown()
+
+	queueInformer := NewInformer(queue, configMapInformer(t), func(obj interface{}) error {
+		return nil
+	}, func(obj interface{}) error {
+		return errors.New("cleanup failed")
+	}, nil)
+	op.RegisterQueueInformer(queueInformer)
+	group := op.queueGroups()[0]
+
+	queueInformer.resourceEventHandlerFuncs.OnDelete(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "cm"}})
+	for i := 0; i < 2; i++ {
+		require.True(t, op.processNextWorkItem(group))
+	}
+
+	// once dead-lettered, the last known state of the object is dropped
+	require.Equal(t, map[string]error{"ns/cm": errors.New("cleanup failed")}, deadLetters)
+	_, pending := queueInformer.lastDeleted("ns/cm")
+	require.False(t, pending)
+}
+
+func TestSyncSharedQueue(t *testing.T) {
+	op := &Operator{}
+	queue := op.NewQueue("test")
+	defer queue.ShutDown()
+
+	synced := []string{}
+	handler := func(informer string) SyncHandler {
+		return func(obj interface{}) error {
+			synced = append(synced, fmt.Sprintf("%s:%s", informer, obj.(*corev1.ConfigMap).GetName()))
+			return nil
+		}
+	}
+	op.RegisterQueueInformer(NewInformer(queue, configMapInformer(t, "a"), handler("first"), nil, nil))
+	op.RegisterQueueInformer(NewInformer(queue, configMapInformer(t, "b"), handler("second"), nil, nil))
+
+	groups := op.queueGroups()
+	require.Len(t, groups, 1)
+
+	// keys are synced by whichever informer sharing the queue has the object
+	for _, key := range []string{"ns/a", "ns/b", "ns/missing"} {
+		require.NoError(t, op.sync(groups[0], key))
+	}
+	require.Equal(t, []string{"first:a", "second:b"}, synced)
+}
+
+func TestRunWorkersDrainsQueues(t *testing.T) {
+	names := []string{}
+	for i := 0; i < 10; i++ {
+		names = append(names, fmt.Sprintf("cm-%d", i))
+	}
+
+	op := &Operator{config: Config{Workers: 3, ShutdownTimeout: 5 * time.Second}}
+	queue := op.NewQueue("test")
+
+	var mu sync.Mutex
+	synced := map[string]struct{}{}
+	op.RegisterQueueInformer(NewInformer(queue, configMapInformer(t, names...), func(obj interface{}) error {
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		defer mu.Unlock()
+		synced[obj.(*corev1.ConfigMap).GetName()] = struct{}{}
+		return nil
+	}, nil, nil))
+
+	for _, name := range names {
+		queue.Add("ns/" + name)
+	}
+	stopc := make(chan struct{})
+	close(stopc)
+
+	// keys queued before stopping are still synced
+	require.NoError(t, op.runWorkers(stopc))
+	require.Len(t, synced, len(names))
+}
+
+func TestRunWorkersShutdownTimeout(t *testing.T) {
+	op := &Operator{config: Config{ShutdownTimeout: 10 * time.Millisecond}}
+	queue := op.NewQueue("test")
+
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{})
+	op.RegisterQueueInformer(NewInformer(queue, configMapInformer(t, "stuck"), func(obj interface{}) error {
+		close(started)
+		<-release
+		return nil
+	}, nil, nil))
+
+	queue.Add("ns/stuck")
+	stopc := make(chan struct{})
+	errc := make(chan error)
+	go func() { errc <- op.runWorkers(stopc) }()
+	<-started
+	close(stopc)
+
+	require.EqualError(t, <-errc, "timed out after 10ms waiting for workers to finish")
+}
+
+func TestSyncDeleted(t *testing.T) {
+	op := &Operator{}
+	queue := op.NewQueue("test")
+	defer queue.ShutDown()
+
+	informer := configMapInformer(t)
+	deleted := []*corev1.ConfigMap{}
+	deleteErr := errors.New("cleanup failed")
+	queueInformer := NewInformer(queue, informer, func(obj interface{}) error {
+		return nil
+	}, func(obj interface{}) error {
+		if deleteErr != nil {
+			return deleteErr
+		}
+		deleted = append(deleted, obj.(*corev1.ConfigMap))
+		return nil
+	}, nil)
+	op.RegisterQueueInformer(queueInformer)
+	group := op.queueGroups()[0]
+
+	// deletions missed while disconnected arrive as tombstones
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "cm"}}
+	queueInformer.resourceEventHandlerFuncs.OnDelete(cache.DeletedFinalStateUnknown{Key: "ns/cm", Obj: cm})
+	require.Equal(t, 1, queue.Len())
+
+	// a failed cleanup is retried
+	require.EqualError(t, op.sync(group, "ns/cm"), "cleanup failed")
+	deleteErr = nil
+	require.NoError(t, op.sync(group, "ns/cm"))
+	require.Equal(t, []*corev1.ConfigMap{cm}, deleted)
+
+	// once cleaned up, the key is ignored
+	require.NoError(t, op.sync(group, "ns/cm"))
+	require.Len(t, deleted, 1)
+
+	// an object recreated before its deletion was processed is synced, not cleaned up
+	queueInformer.resourceEventHandlerFuncs.OnDelete(cm)
+	require.NoError(t, informer.GetIndexer().Add(cm))
+	queueInformer.resourceEventHandlerFuncs.OnAdd(cm)
+	_, pending := queueInformer.lastDeleted("ns/cm")
+	require.False(t, pending)
+}
@@ -0,0 +1,192 @@
+// Package statusutil writes the status of OLM resources without losing races with other writers.
+//
+// Status is computed from objects read out of informer caches, which are often behind the cluster. Writing a whole
+// cached object fails with a conflict whenever anything else has touched it since, so the helpers here write the
+// computed status onto the latest version of the object and retry on conflict. Writes are skipped when the status
+// hasn't changed in any way that matters, so that a sync that found nothing new doesn't generate another update event.
+package statusutil
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators/v1alpha1"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/clientset/versioned"
+)
+
+// Backoff is how often and how quickly a status write is retried after a conflict
+var Backoff = retry.DefaultRetry
+
+// ClusterServiceVersionStatusEqual returns true if two ClusterServiceVersion statuses differ at most in when they
+// were last updated
+func ClusterServiceVersionStatusEqual(a, b v1alpha1.ClusterServiceVersionStatus) bool {
+	a.LastUpdateTime, b.LastUpdateTime = metav1.Time{}, metav1.Time{}
+	return equality.Semantic.DeepEqual(a, b)
+}
+
+// InstallPlanStatusEqual returns true if two InstallPlan statuses differ at most in when their conditions were last
+// updated
+func InstallPlanStatusEqual(a, b v1alpha1.InstallPlanStatus) bool {
+	a.Conditions, b.Conditions = withoutUpdateTimes(a.Conditions), withoutUpdateTimes(b.Conditions)
+	return equality.Semantic.DeepEqual(a, b)
+}
+
+func withoutUpdateTimes(conditions []v1alpha1.InstallPlanCondition) []v1alpha1.InstallPlanCondition {
+	if conditions == nil {
+		return nil
+	}
+	out := make([]v1alpha1.InstallPlanCondition, len(conditions))
+	for i, cond := range conditions {
+		cond.LastUpdateTime = metav1.Time{}
+		out[i] = cond
+	}
+	return out
+}
+
+// SubscriptionStatusEqual returns true if two Subscription statuses differ at most in when they were last updated
+func SubscriptionStatusEqual(a, b v1alpha1.SubscriptionStatus) bool {
+	a.LastUpdated, b.LastUpdated = metav1.Time{}, metav1.Time{}
+	return equality.Semantic.DeepEqual(a, b)
+}
+
+// CatalogSourceSyncTimesInterval is how far behind the sync times of a CatalogSource may fall before they're written
+// even though nothing else in its status changed
+var CatalogSourceSyncTimesInterval = 5 * time.Minute
+
+// CatalogSourceStatusEqual returns true if two CatalogSource statuses differ at most in when their catalogs were last
+// synced, by less than CatalogSourceSyncTimesInterval, so that syncing an unchanged catalog doesn't write its status
+// every time
+func CatalogSourceStatusEqual(a, b v1alpha1.CatalogSourceStatus) bool {
+	if timesApart(a.LastSync, b.LastSync) >= CatalogSourceSyncTimesInterval || timesApart(a.LastAttempt, b.LastAttempt) >= CatalogSourceSyncTimesInterval {
+		return false
+	}
+	a.LastSync, b.LastSync = metav1.Time{}, metav1.Time{}
+	a.LastAttempt, b.LastAttempt = metav1.Time{}, metav1.Time{}
+	return equality.Semantic.DeepEqual(a, b)
+}
+
+func timesApart(a, b metav1.Time) time.Duration {
+	if a.Before(&b) {
+		return b.Sub(a.Time)
+	}
+	return a.Sub(b.Time)
+}
+
+// UpdateClusterServiceVersionStatus writes the status of updated, which was computed from original. The write is
+// skipped if the status didn't change. Returns the ClusterServiceVersion as last read from or written to the cluster.
+func UpdateClusterServiceVersionStatus(client versioned.Interface, original, updated *v1alpha1.ClusterServiceVersion) (*v1alpha1.ClusterServiceVersion, error) {
+	csvs := client.OperatorsV1alpha1().ClusterServiceVersions(updated.GetNamespace())
+	out, err := updateStatus(original, updated,
+		func() (runtime.Object, error) {
+			return csvs.Get(updated.GetName(), metav1.GetOptions{})
+		},
+		func(obj runtime.Object) bool {
+			return ClusterServiceVersionStatusEqual(obj.(*v1alpha1.ClusterServiceVersion).Status, updated.Status)
+		},
+		func(obj runtime.Object) (runtime.Object, error) {
+			next := obj.(*v1alpha1.ClusterServiceVersion)
+			next.Status = updated.Status
+			return csvs.UpdateStatus(next)
+		},
+	)
+	result, _ := out.(*v1alpha1.ClusterServiceVersion)
+	return result, err
+}
+
+// UpdateInstallPlanStatus writes the status of updated, which was computed from original. The write is skipped if
+// the status didn't change. Returns the InstallPlan as last read from or written to the cluster.
+func UpdateInstallPlanStatus(client versioned.Interface, original, updated *v1alpha1.InstallPlan) (*v1alpha1.InstallPlan, error) {
+	plans := client.OperatorsV1alpha1().InstallPlans(updated.GetNamespace())
+	out, err := updateStatus(original, updated,
+		func() (runtime.Object, error) {
+			return plans.Get(updated.GetName(), metav1.GetOptions{})
+		},
+		func(obj runtime.Object) bool {
+			return InstallPlanStatusEqual(obj.(*v1alpha1.InstallPlan).Status, updated.Status)
+		},
+		func(obj runtime.Object) (runtime.Object, error) {
+			next := obj.(*v1alpha1.InstallPlan)
+			next.Status = updated.Status
+			return plans.UpdateStatus(next)
+		},
+	)
+	result, _ := out.(*v1alpha1.InstallPlan)
+	return result, err
+}
+
+// UpdateSubscriptionStatus writes the status of updated, which was computed from original. The write is skipped if
+// the status didn't change and was last updated after catalogsUpdated, so that a Subscription resolved against
+// changed catalogs records that it has seen them. Returns the Subscription as last read from or written to the cluster.
+func UpdateSubscriptionStatus(client versioned.Interface, original, updated *v1alpha1.Subscription, catalogsUpdated metav1.Time) (*v1alpha1.Subscription, error) {
+	subs := client.OperatorsV1alpha1().Subscriptions(updated.GetNamespace())
+	out, err := updateStatus(original, updated,
+		func() (runtime.Object, error) {
+			return subs.Get(updated.GetName(), metav1.GetOptions{})
+		},
+		func(obj runtime.Object) bool {
+			status := obj.(*v1alpha1.Subscription).Status
+			return catalogsUpdated.Before(&status.LastUpdated) && SubscriptionStatusEqual(status, updated.Status)
+		},
+		func(obj runtime.Object) (runtime.Object, error) {
+			next := obj.(*v1alpha1.Subscription)
+			next.Status = updated.Status
+			return subs.UpdateStatus(next)
+		},
+	)
+	result, _ := out.(*v1alpha1.Subscription)
+	return result, err
+}
+
+// UpdateCatalogSourceStatus writes the status of updated, which was computed from original. The write is skipped if
+// the status didn't change. Returns the CatalogSource as last read from or written to the cluster.
+func UpdateCatalogSourceStatus(client versioned.Interface, original, updated *v1alpha1.CatalogSource) (*v1alpha1.CatalogSource, error) {
+	sources := client.OperatorsV1alpha1().CatalogSources(updated.GetNamespace())
+	out, err := updateStatus(original, updated,
+		func() (runtime.Object, error) {
+			return sources.Get(updated.GetName(), metav1.GetOptions{})
+		},
+		func(obj runtime.Object) bool {
+			return CatalogSourceStatusEqual(obj.(*v1alpha1.CatalogSource).Status, updated.Status)
+		},
+		func(obj runtime.Object) (runtime.Object, error) {
+			next := obj.(*v1alpha1.CatalogSource)
+			next.Status = updated.Status
+			return sources.UpdateStatus(next)
+		},
+	)
+	result, _ := out.(*v1alpha1.CatalogSource)
+	return result, err
+}
+
+// updateStatus writes the status of updated, which was computed from original, unless equal says an object already has
+// that status. The first write is of updated itself; after a conflict, the latest object is read with get and written
+// with update, which copies the status of updated onto it. Returns the object as last read from or written to the
+// cluster.
+func updateStatus(original, updated runtime.Object, get func() (runtime.Object, error), equal func(runtime.Object) bool, update func(runtime.Object) (runtime.Object, error)) (runtime.Object, error) {
+	if equal(original) {
+		return original, nil
+	}
+	var out runtime.Object
+	next := updated
+	err := retry.RetryOnConflict(Backoff, func() (err error) {
+		if next == nil {
+			latest, err := get()
+			if err != nil {
+				return err
+			}
+			if equal(latest) {
+				out = latest
+				return nil
+			}
+			next = latest
+		}
+		out, err = update(next)
+		next = nil
+		return err
+	})
+	return out, err
+}
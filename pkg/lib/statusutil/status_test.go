@@ -0,0 +1,191 @@
+package statusutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clienttesting "k8s.io/client-go/testing"
+
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators/v1alpha1"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/clientset/versioned/fake"
+)
+
+func csv(phase v1alpha1.ClusterServiceVersionPhase, labels map[string]string) *v1alpha1.ClusterServiceVersion {
+	return &v1alpha1.ClusterServiceVersion{
+		ObjectMeta: metav1.ObjectMeta{Name: "csv", Namespace: "ns", Labels: labels},
+		Status:     v1alpha1.ClusterServiceVersionStatus{Phase: phase},
+	}
+}
+
+// conflicts fails the first n status updates with a conflict, and counts every status update
+func conflicts(client *fake.Clientset, n int) *int {
+	updates := 0
+	client.PrependReactor("update", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "status" {
+			return false, nil, nil
+		}
+		updates++
+		if updates <= n {
+			return true, nil, k8serrors.NewConflict(schema.GroupResource{Resource: action.GetResource().Resource}, "csv", nil)
+		}
+		return false, nil, nil
+	})
+	return &updates
+}
+
+func TestClusterServiceVersionStatusEqual(t *testing.T) {
+	base := v1alpha1.ClusterServiceVersionStatus{
+		Phase:             v1alpha1.CSVPhaseSucceeded,
+		Reason:            v1alpha1.CSVReasonInstallSuccessful,
+		LastUpdateTime:    metav1.Unix(1, 0),
+		Conditions:        []v1alpha1.ClusterServiceVersionCondition{{Phase: v1alpha1.CSVPhaseSucceeded}},
+		RequirementStatus: []v1alpha1.RequirementStatus{{Kind: "CustomResourceDefinition", Name: "a", Status: "Present"}},
+	}
+
+	updated := *base.DeepCopy()
+	updated.LastUpdateTime = metav1.Unix(2, 0)
+	require.True(t, ClusterServiceVersionStatusEqual(base, updated))
+
+	updated = *base.DeepCopy()
+	updated.Conditions = append(updated.Conditions, v1alpha1.ClusterServiceVersionCondition{Phase: v1alpha1.CSVPhaseFailed})
+	require.False(t, ClusterServiceVersionStatusEqual(base, updated))
+
+	updated = *base.DeepCopy()
+	updated.RequirementStatus[0].Status = "NotPresent"
+	require.False(t, ClusterServiceVersionStatusEqual(base, updated))
+}
+
+func TestInstallPlanStatusEqual(t *testing.T) {
+	base := v1alpha1.InstallPlanStatus{
+		Phase: v1alpha1.InstallPlanPhaseComplete,
+		Conditions: []v1alpha1.InstallPlanCondition{{
+			Type:               v1alpha1.InstallPlanInstalled,
+			LastUpdateTime:     metav1.Unix(1, 0),
+			LastTransitionTime: metav1.Unix(1, 0),
+		}},
+	}
+
+	updated := *base.DeepCopy()
+	updated.Conditions[0].LastUpdateTime = metav1.Unix(2, 0)
+	require.True(t, InstallPlanStatusEqual(base, updated))
+	require.Equal(t, metav1.Unix(1, 0), base.Conditions[0].LastUpdateTime)
+
+	updated.Conditions[0].LastTransitionTime = metav1.Unix(2, 0)
+	require.False(t, InstallPlanStatusEqual(base, updated))
+}
+
+func TestSubscriptionStatusEqual(t *testing.T) {
+	base := v1alpha1.SubscriptionStatus{State: v1alpha1.SubscriptionStateAtLatest, LastUpdated: metav1.Unix(1, 0)}
+
+	updated := base
+	updated.LastUpdated = metav1.Unix(2, 0)
+	require.True(t, SubscriptionStatusEqual(base, updated))
+
+	updated.State = v1alpha1.SubscriptionStateUpgradeAvailable
+	require.False(t, SubscriptionStatusEqual(base, updated))
+}
+
+func TestCatalogSourceStatusEqual(t *testing.T) {
+	base := v1alpha1.CatalogSourceStatus{Hash: "a", LastSync: metav1.Unix(1, 0), LastAttempt: metav1.Unix(1, 0)}
+
+	updated := base
+	updated.LastSync = metav1.Unix(2, 0)
+	updated.LastAttempt = metav1.Unix(2, 0)
+	require.True(t, CatalogSourceStatusEqual(base, updated))
+
+	// sync times that fell too far behind are written
+	updated.LastAttempt = metav1.NewTime(base.LastAttempt.Add(CatalogSourceSyncTimesInterval))
+	require.False(t, CatalogSourceStatusEqual(base, updated))
+	updated.LastAttempt = base.LastAttempt
+	updated.LastSync = metav1.NewTime(base.LastSync.Add(CatalogSourceSyncTimesInterval))
+	require.False(t, CatalogSourceStatusEqual(base, updated))
+	updated.LastSync = base.LastSync
+
+	updated.Hash = "b"
+	require.False(t, CatalogSourceStatusEqual(base, updated))
+}
+
+func TestUpdateClusterServiceVersionStatus(t *testing.T) {
+	tests := []struct {
+		description string
+		conflicts   int
+		cluster     *v1alpha1.ClusterServiceVersion
+		original    *v1alpha1.ClusterServiceVersion
+		updated     *v1alpha1.ClusterServiceVersion
+		wantUpdates int
+		wantPhase   v1alpha1.ClusterServiceVersionPhase
+		wantLabels  map[string]string
+		wantErr     bool
+	}{
+		{
+			description: "UnchangedStatusIsNotWritten",
+			cluster:     csv(v1alpha1.CSVPhasePending, nil),
+			original:    csv(v1alpha1.CSVPhasePending, nil),
+			updated:     csv(v1alpha1.CSVPhasePending, nil),
+			wantUpdates: 0,
+			wantPhase:   v1alpha1.CSVPhasePending,
+		},
+		{
+			description: "ChangedStatusIsWritten",
+			cluster:     csv(v1alpha1.CSVPhasePending, nil),
+			original:    csv(v1alpha1.CSVPhasePending, nil),
+			updated:     csv(v1alpha1.CSVPhaseInstalling, nil),
+			wantUpdates: 1,
+			wantPhase:   v1alpha1.CSVPhaseInstalling,
+		},
+		{
+			description: "ConflictRetriesAgainstLatest",
+			conflicts:   2,
+			cluster:     csv(v1alpha1.CSVPhasePending, map[string]string{"latest": "true"}),
+			original:    csv(v1alpha1.CSVPhasePending, nil),
+			updated:     csv(v1alpha1.CSVPhaseInstalling, nil),
+			wantUpdates: 3,
+			wantPhase:   v1alpha1.CSVPhaseInstalling,
+			wantLabels:  map[string]string{"latest": "true"},
+		},
+		{
+			description: "ConflictWithSameStatusIsNotRetried",
+			conflicts:   1,
+			cluster:     csv(v1alpha1.CSVPhaseInstalling, map[string]string{"latest": "true"}),
+			original:    csv(v1alpha1.CSVPhasePending, nil),
+			updated:     csv(v1alpha1.CSVPhaseInstalling, nil),
+			wantUpdates: 1,
+			wantPhase:   v1alpha1.CSVPhaseInstalling,
+			wantLabels:  map[string]string{"latest": "true"},
+		},
+		{
+			description: "TooManyConflicts",
+			conflicts:   Backoff.Steps,
+			cluster:     csv(v1alpha1.CSVPhasePending, nil),
+			original:    csv(v1alpha1.CSVPhasePending, nil),
+			updated:     csv(v1alpha1.CSVPhaseInstalling, nil),
+			wantUpdates: Backoff.Steps,
+			wantPhase:   v1alpha1.CSVPhasePending,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			client := fake.NewSimpleClientset(tt.cluster)
+			updates := conflicts(client, tt.conflicts)
+
+			out, err := UpdateClusterServiceVersionStatus(client, tt.original, tt.updated)
+			if tt.wantErr {
+				require.True(t, k8serrors.IsConflict(err))
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tt.wantPhase, out.Status.Phase)
+			}
+			require.Equal(t, tt.wantUpdates, *updates)
+
+			stored, err := client.OperatorsV1alpha1().ClusterServiceVersions("ns").Get("csv", metav1.GetOptions{})
+			require.NoError(t, err)
+			require.Equal(t, tt.wantPhase, stored.Status.Phase)
+			require.Equal(t, tt.wantLabels, stored.GetLabels())
+		})
+	}
+}
@@ -0,0 +1,212 @@
+// Package versionrange parses semver version ranges, like ">=0.9.0 <0.10.0", "0.9.x" or "<=2.0.0 || ^3.1.0"
+package versionrange
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/coreos/go-semver/semver"
+)
+
+// Range is a set of semver versions. A range is one or more sets of comparators separated by "||", and contains a
+// version if every comparator of any of its sets matches it. A comparator is a version prefixed by one of:
+//
+//	=  or nothing  exactly the version, or any version matching a partial version like "0.9", "0.9.x" or "0.9.*"
+//	>  >=  <  <=   greater or less than the version; missing parts of a partial version are zero
+//	~              patch updates: "~1.2.3" is ">=1.2.3 <1.3.0"
+//	^              updates that don't change the leftmost non-zero part: "^1.2.3" is ">=1.2.3 <2.0.0", and
+//	               "^0.2.3" is ">=0.2.3 <0.3.0"
+type Range struct {
+	source string
+	sets   [][]comparator
+}
+
+type comparator struct {
+	op      string
+	version semver.Version
+}
+
+// Parse parses a version range
+func Parse(s string) (Range, error) {
+	r := Range{source: s}
+	for _, alternative := range strings.Split(s, "||") {
+		fields := strings.Fields(alternative)
+		if len(fields) == 0 {
+			return Range{}, fmt.Errorf("invalid version range %q: empty set of comparators", s)
+		}
+		set := []comparator{}
+		for _, field := range fields {
+			comparators, err := parseComparator(field)
+			if err != nil {
+				return Range{}, fmt.Errorf("invalid version range %q: %s", s, err)
+			}
+			set = append(set, comparators...)
+		}
+		r.sets = append(r.sets, set)
+	}
+	return r, nil
+}
+
+// Contains reports whether a version is in the range
+func (r Range) Contains(v semver.Version) bool {
+	for _, set := range r.sets {
+		if matchesAll(set, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the range as it was parsed
+func (r Range) String() string {
+	return r.source
+}
+
+func matchesAll(set []comparator, v semver.Version) bool {
+	for _, c := range set {
+		if !c.matches(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c comparator) matches(v semver.Version) bool {
+	cmp := v.Compare(c.version)
+	switch c.op {
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	default:
+		return cmp == 0
+	}
+}
+
+// parseComparator parses a single comparator into the primitive comparators it stands for
+func parseComparator(s string) ([]comparator, error) {
+	op := ""
+	for _, prefix := range []string{">=", "<=", ">", "<", "=", "~", "^"} {
+		if strings.HasPrefix(s, prefix) {
+			op = prefix
+			break
+		}
+	}
+	p, err := parsePartial(strings.TrimPrefix(s, op))
+	if err != nil {
+		return nil, err
+	}
+	if p.specified == 0 && op != "" && op != "=" {
+		return nil, fmt.Errorf("%q: a wildcard can't have an operator", s)
+	}
+	lower := p.floor()
+
+	switch op {
+	case ">":
+		if p.full() {
+			return []comparator{{">", lower}}, nil
+		}
+		return []comparator{{">=", p.ceiling(p.specified - 1)}}, nil
+	case ">=":
+		return []comparator{{">=", lower}}, nil
+	case "<":
+		return []comparator{{"<", lower}}, nil
+	case "<=":
+		if p.full() {
+			return []comparator{{"<=", lower}}, nil
+		}
+		return []comparator{{"<", p.ceiling(p.specified - 1)}}, nil
+	case "~":
+		if p.specified == 3 {
+			return []comparator{{">=", lower}, {"<", p.ceiling(1)}}, nil
+		}
+	case "^":
+		// the leftmost non-zero part may not change; a partial version may change its first unspecified part
+		bump := 0
+		for bump < p.specified-1 && bump < 2 && p.parts[bump] == 0 {
+			bump++
+		}
+		return []comparator{{">=", lower}, {"<", p.ceiling(bump)}}, nil
+	}
+
+	// exact, or any version matching a partial version
+	if p.full() {
+		return []comparator{{"=", lower}}, nil
+	}
+	if p.specified == 0 {
+		return []comparator{{">=", lower}}, nil
+	}
+	return []comparator{{">=", lower}, {"<", p.ceiling(p.specified - 1)}}, nil
+}
+
+// partial is a version that may leave its trailing parts unspecified
+type partial struct {
+	parts      [3]int64
+	specified  int
+	preRelease semver.PreRelease
+	metadata   string
+}
+
+func parsePartial(s string) (partial, error) {
+	p := partial{}
+	if s == "" {
+		return p, fmt.Errorf("missing version")
+	}
+	s = strings.TrimPrefix(s, "v")
+	if i := strings.Index(s, "+"); i >= 0 {
+		s, p.metadata = s[:i], s[i+1:]
+	}
+	if i := strings.Index(s, "-"); i >= 0 {
+		s, p.preRelease = s[:i], semver.PreRelease(s[i+1:])
+	}
+
+	fields := strings.Split(s, ".")
+	if len(fields) > 3 {
+		return p, fmt.Errorf("invalid version %q", s)
+	}
+	for _, field := range fields {
+		if field == "x" || field == "X" || field == "*" {
+			break
+		}
+		n, err := strconv.ParseInt(field, 10, 64)
+		if err != nil || n < 0 {
+			return p, fmt.Errorf("invalid version %q", s)
+		}
+		p.parts[p.specified] = n
+		p.specified++
+	}
+	if p.preRelease != "" && !p.full() {
+		return p, fmt.Errorf("invalid version %q: a pre-release needs a full version", s)
+	}
+	return p, nil
+}
+
+func (p partial) full() bool {
+	return p.specified == 3
+}
+
+// floor returns the lowest version matching the partial version
+func (p partial) floor() semver.Version {
+	return semver.Version{
+		Major:      p.parts[0],
+		Minor:      p.parts[1],
+		Patch:      p.parts[2],
+		PreRelease: p.preRelease,
+		Metadata:   p.metadata,
+	}
+}
+
+// ceiling returns the lowest version above the partial version with the given part bumped
+func (p partial) ceiling(part int) semver.Version {
+	parts := p.parts
+	parts[part]++
+	for i := part + 1; i < len(parts); i++ {
+		parts[i] = 0
+	}
+	return semver.Version{Major: parts[0], Minor: parts[1], Patch: parts[2]}
+}
@@ -0,0 +1,65 @@
+package versionrange
+
+import (
+	"testing"
+
+	"github.com/coreos/go-semver/semver"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContains(t *testing.T) {
+	tests := []struct {
+		versionRange string
+		in           []string
+		out          []string
+	}{
+		{versionRange: "1.2.3", in: []string{"1.2.3"}, out: []string{"1.2.4", "1.2.3-alpha"}},
+		{versionRange: "=1.2.3", in: []string{"1.2.3"}, out: []string{"1.2.2"}},
+		{versionRange: "0.9.x", in: []string{"0.9.0", "0.9.12"}, out: []string{"0.8.9", "0.10.0", "1.9.0"}},
+		{versionRange: "0.9", in: []string{"0.9.3"}, out: []string{"0.10.0"}},
+		{versionRange: "1.*", in: []string{"1.0.0", "1.9.9"}, out: []string{"2.0.0", "0.9.9"}},
+		{versionRange: "*", in: []string{"0.0.0", "12.1.0"}},
+		{versionRange: "<=2.0.0", in: []string{"2.0.0", "0.1.0"}, out: []string{"2.0.1", "3.0.0"}},
+		{versionRange: "<=2.0", in: []string{"2.0.7"}, out: []string{"2.1.0"}},
+		{versionRange: ">1.2", in: []string{"1.3.0"}, out: []string{"1.2.9"}},
+		{versionRange: ">1.2.3 <2", in: []string{"1.2.4", "1.99.0"}, out: []string{"1.2.3", "2.0.0"}},
+		{versionRange: ">=0.9.0 <0.10.0", in: []string{"0.9.0", "0.9.5"}, out: []string{"0.10.0", "0.8.0"}},
+		{versionRange: "~1.2.3", in: []string{"1.2.3", "1.2.9"}, out: []string{"1.3.0", "1.2.2"}},
+		{versionRange: "~1.2", in: []string{"1.2.0", "1.2.9"}, out: []string{"1.3.0"}},
+		{versionRange: "^1.2.3", in: []string{"1.2.3", "1.9.0"}, out: []string{"2.0.0", "1.2.2"}},
+		{versionRange: "^0.2.3", in: []string{"0.2.3", "0.2.9"}, out: []string{"0.3.0"}},
+		{versionRange: "^0.0.3", in: []string{"0.0.3"}, out: []string{"0.0.4"}},
+		{versionRange: "^0.x", in: []string{"0.0.1", "0.9.0"}, out: []string{"1.0.0"}},
+		{versionRange: "<=0.9.0 || ^3.1.0", in: []string{"0.9.0", "3.4.0"}, out: []string{"1.0.0", "4.0.0"}},
+		{versionRange: "v1.0.0-beta.2", in: []string{"1.0.0-beta.2"}, out: []string{"1.0.0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.versionRange, func(t *testing.T) {
+			r, err := Parse(tt.versionRange)
+			require.NoError(t, err)
+			require.Equal(t, tt.versionRange, r.String())
+			for _, v := range tt.in {
+				require.True(t, r.Contains(*semver.New(v)), "%s should contain %s", tt.versionRange, v)
+			}
+			for _, v := range tt.out {
+				require.False(t, r.Contains(*semver.New(v)), "%s shouldn't contain %s", tt.versionRange, v)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string]string{
+		"":          `invalid version range "": empty set of comparators`,
+		"1.0.0 ||":  `invalid version range "1.0.0 ||": empty set of comparators`,
+		">=":        `invalid version range ">=": missing version`,
+		"1.a":       `invalid version range "1.a": invalid version "1.a"`,
+		"1.2.3.4":   `invalid version range "1.2.3.4": invalid version "1.2.3.4"`,
+		"1.2-alpha": `invalid version range "1.2-alpha": invalid version "1.2": a pre-release needs a full version`,
+		">x":        `invalid version range ">x": ">x": a wildcard can't have an operator`,
+	}
+	for versionRange, expected := range tests {
+		_, err := Parse(versionRange)
+		require.EqualError(t, err, expected, versionRange)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/client"
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/clientset/versioned"
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/controller/registry"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/audit"
 )
 
 var (
@@ -40,6 +41,7 @@ var (
 
 type Options struct {
 	Namespace string // restrict to resources within a namespace, default all namespaces
+	AuditSink string // where to record created and deleted objects, see audit.NewSink
 }
 
 // ALMBroker contains the clients and logic for fetching the catalog and creating instances
@@ -47,6 +49,7 @@ type ALMBroker struct {
 	opClient operatorclient.ClientInterface
 	client   versioned.Interface
 	catalog  catalogLoader
+	auditor  *audit.Auditor
 
 	namespace    string
 	dashboardURL *string // URL of a web-based management UI for services
@@ -60,6 +63,10 @@ func NewALMBroker(kubeconfigPath string, options Options) (*ALMBroker, error) {
 		return nil, err
 	}
 	almOpClient := operatorclient.NewClient(kubeconfigPath)
+	sink, err := audit.NewSink(options.AuditSink)
+	if err != nil {
+		return nil, err
+	}
 	// Allocate the new instance of an ALMBroker
 	br := &ALMBroker{
 		client:   versionedClient,
@@ -68,6 +75,7 @@ func NewALMBroker(kubeconfigPath string, options Options) (*ALMBroker, error) {
 			client:   versionedClient,
 			opClient: almOpClient,
 		},
+		auditor:   audit.NewAuditor(sink, "alm-servicebroker"),
 		namespace: options.Namespace,
 	}
 	return br, nil
@@ -99,8 +107,32 @@ func (c *inClusterCatalog) Load(namespace string) (registry.Source, error) {
 	// load service definitions from configmaps into temp in memory service registry
 	catalog := registry.NewInMem()
 	for _, cs := range csList.Items {
+		if cs.Spec.SourceType == v1alpha1.SourceTypeGrpc || cs.Spec.SourceType == v1alpha1.SourceTypeHTTP {
+			log.Debugf("Component=ServiceBroker Endpoint=GetCatalog CatalogSource=%s SourceType=%s not loaded from ConfigMaps, skipping", cs.GetName(), cs.Spec.SourceType)
+			continue
+		}
+		set, err := registry.ConfigMapSetForCatalogSource(cs.Spec)
+		if err != nil {
+			log.Errorf("Component=ServiceBroker Endpoint=GetCatalog Error=%s", err)
+			return nil, err
+		}
 		loader := registry.NewConfigMapCatalogResourceLoader(cs.GetNamespace(), c.opClient)
-		if err := loader.LoadCatalogResources(catalog, cs.Spec.ConfigMap); err != nil {
+		filter := registry.PackageFilterForCatalogSource(cs.Spec)
+		if filter.IsZero() {
+			if err := loader.LoadCatalogResourcesFromConfigMapSet(catalog, set); err != nil {
+				log.Errorf("Component=ServiceBroker Endpoint=GetCatalog Error=%s", err)
+				return nil, err
+			}
+			continue
+		}
+
+		// filtered packages are hidden from the broker too
+		unfiltered := registry.NewInMem()
+		if err := loader.LoadCatalogResourcesFromConfigMapSet(unfiltered, set); err != nil {
+			log.Errorf("Component=ServiceBroker Endpoint=GetCatalog Error=%s", err)
+			return nil, err
+		}
+		if _, err := unfiltered.FilterInto(catalog, filter); err != nil {
 			log.Errorf("Component=ServiceBroker Endpoint=GetCatalog Error=%s", err)
 			return nil, err
 		}
@@ -159,7 +191,7 @@ func (a *ALMBroker) GetCatalog(b *broker.RequestContext) (*broker.CatalogRespons
 	return &broker.CatalogResponse{osb.CatalogResponse{services}}, nil
 }
 
-func ensureNamespace(ns string, client operatorclient.ClientInterface) error {
+func ensureNamespace(ns string, client operatorclient.ClientInterface, auditor *audit.Auditor, correlation audit.Correlation) error {
 	_, err := client.KubernetesInterface().CoreV1().Namespaces().Get(ns, metav1.GetOptions{})
 	if err == nil {
 		return err
@@ -179,10 +211,15 @@ func ensureNamespace(ns string, client operatorclient.ClientInterface) error {
 	if ip == nil {
 		return errors.New("unexpected installplan returned by k8s api on create: <nil>")
 	}
+	auditor.Record(audit.Record{
+		Action:         audit.ActionCreate,
+		Object:         audit.Reference("v1", "Namespace", ip),
+		CorrelationIDs: correlation,
+	})
 
 	return err
 }
-func ensureCSV(namespace string, csvName string, client versioned.Interface) error {
+func ensureCSV(namespace string, csvName string, client versioned.Interface, auditor *audit.Auditor, correlation audit.Correlation) error {
 	// check that desired CSV has been installed
 	csv, err := client.OperatorsV1alpha1().ClusterServiceVersions(namespace).Get(csvName, metav1.GetOptions{})
 	if err == nil && csv != nil {
@@ -206,6 +243,12 @@ func ensureCSV(namespace string, csvName string, client versioned.Interface) err
 	if ip == nil {
 		return errors.New("unexpected response installing service plan")
 	}
+	correlation.InstallPlan = string(ip.GetUID())
+	auditor.Record(audit.Record{
+		Action:         audit.ActionCreate,
+		Object:         audit.Reference(v1alpha1.InstallPlanAPIVersion, v1alpha1.InstallPlanKind, ip),
+		CorrelationIDs: correlation,
+	})
 	// wait for installplan to finish
 	err = wait.Poll(pollInterval, pollDuration, func() (bool, error) {
 		pollIp, pollErr := client.OperatorsV1alpha1().InstallPlans(namespace).Get(ip.Name, metav1.GetOptions{})
@@ -232,7 +275,8 @@ func (a *ALMBroker) Provision(request *osb.ProvisionRequest, c *broker.RequestCo
 	if namespace == "" {
 		return nil, NamespaceRequiredError
 	}
-	if err := ensureNamespace(namespace, a.opClient); err != nil {
+	correlation := audit.Correlation{ServiceInstance: request.InstanceID}
+	if err := ensureNamespace(namespace, a.opClient, a.auditor, correlation); err != nil {
 		return nil, fmt.Errorf("failed to ensure namespace '%s' for plan '%s': %v", namespace, request.PlanID, err)
 	}
 	resp, err := a.GetCatalog(nil)
@@ -258,7 +302,7 @@ func (a *ALMBroker) Provision(request *osb.ProvisionRequest, c *broker.RequestCo
 	if !found {
 		return nil, fmt.Errorf("unknown plan '%s'", request.PlanID)
 	}
-	if err := ensureCSV(namespace, csvName, a.client); err != nil {
+	if err := ensureCSV(namespace, csvName, a.client, a.auditor, correlation); err != nil {
 		return nil, fmt.Errorf("failed to ensure CSV '%s' exists in namspace '%s' for plan '%s': %v", csvName, namespace, request.PlanID, err)
 	}
 	cr, err := planToCustomResourceObject(plan, request.InstanceID, request.Parameters)
@@ -273,6 +317,13 @@ func (a *ALMBroker) Provision(request *osb.ProvisionRequest, c *broker.RequestCo
 		}
 		exists = true
 	}
+	if !exists {
+		a.auditor.Record(audit.Record{
+			Action:         audit.ActionCreate,
+			Object:         audit.Reference(cr.GetAPIVersion(), cr.GetKind(), cr),
+			CorrelationIDs: correlation,
+		})
+	}
 	gvk := cr.GroupVersionKind()
 	obj, err := a.opClient.GetCustomResource(gvk.Group, gvk.Version, namespace, gvk.Kind, cr.GetName())
 	if err != nil {
@@ -408,6 +459,11 @@ Deprovisioning:
 			},
 		}, nil
 	}
+	a.auditor.Record(audit.Record{
+		Action:         audit.ActionDelete,
+		Object:         v1.ObjectReference{APIVersion: cr.GetAPIVersion(), Kind: cr.GetKind(), Namespace: namespace, Name: cr.GetName()},
+		CorrelationIDs: audit.Correlation{ServiceInstance: instanceID},
+	})
 	return &broker.DeprovisionResponse{
 		DeprovisionResponse: osb.DeprovisionResponse{
 			Async:        true,
@@ -0,0 +1,105 @@
+package integration
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apiserver/pkg/storage/names"
+	clienttesting "k8s.io/client-go/testing"
+
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators/v1alpha1"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/clientset/versioned"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/operatorclient"
+)
+
+// operatorClient answers the custom resource requests OLM makes for ClusterServiceVersions from the fake OLM
+// clientset. operatorclient.Client makes them with raw requests, which fake clientsets can't serve.
+type operatorClient struct {
+	operatorclient.ClientInterface
+	crClient versioned.Interface
+}
+
+var _ operatorclient.ClientInterface = &operatorClient{}
+
+func checkKind(apiGroup, version, resourceKind string) error {
+	if apiGroup != v1alpha1.GroupName || version != v1alpha1.GroupVersion || resourceKind != v1alpha1.ClusterServiceVersionKind {
+		return fmt.Errorf("custom resource %s/%s %s isn't supported by the harness", apiGroup, version, resourceKind)
+	}
+	return nil
+}
+
+func toUnstructured(csv *v1alpha1.ClusterServiceVersion) (*unstructured.Unstructured, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(csv)
+	if err != nil {
+		return nil, err
+	}
+	obj := &unstructured.Unstructured{Object: content}
+	obj.SetAPIVersion(v1alpha1.ClusterServiceVersionAPIVersion)
+	obj.SetKind(v1alpha1.ClusterServiceVersionKind)
+	return obj, nil
+}
+
+func (c *operatorClient) GetCustomResource(apiGroup, version, namespace, resourceKind, resourceName string) (*unstructured.Unstructured, error) {
+	if err := checkKind(apiGroup, version, resourceKind); err != nil {
+		return nil, err
+	}
+	csv, err := c.crClient.OperatorsV1alpha1().ClusterServiceVersions(namespace).Get(resourceName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return toUnstructured(csv)
+}
+
+func (c *operatorClient) ListCustomResource(apiGroup, version, namespace, resourceKind string) (*operatorclient.CustomResourceList, error) {
+	if err := checkKind(apiGroup, version, resourceKind); err != nil {
+		return nil, err
+	}
+	csvs, err := c.crClient.OperatorsV1alpha1().ClusterServiceVersions(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	list := &operatorclient.CustomResourceList{}
+	for i := range csvs.Items {
+		item, err := toUnstructured(&csvs.Items[i])
+		if err != nil {
+			return nil, err
+		}
+		list.Items = append(list.Items, item)
+	}
+	return list, nil
+}
+
+func (c *operatorClient) DeleteCustomResource(apiGroup, version, namespace, resourceKind, resourceName string) error {
+	if err := checkKind(apiGroup, version, resourceKind); err != nil {
+		return err
+	}
+	return c.crClient.OperatorsV1alpha1().ClusterServiceVersions(namespace).Delete(resourceName, &metav1.DeleteOptions{})
+}
+
+// generateNames fills in the name and UID of created objects like the api server does, since fake clientsets don't.
+// Reactors are passed copies of actions, so the objects are stored by the reactors that follow in the chain.
+func generateNames(fake *clienttesting.Fake) {
+	chain := fake.ReactionChain
+	fake.PrependReactor("create", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if meta, ok := action.(clienttesting.CreateAction).GetObject().(metav1.Object); ok {
+			if meta.GetName() == "" && meta.GetGenerateName() != "" {
+				meta.SetName(names.SimpleNameGenerator.GenerateName(meta.GetGenerateName()))
+			}
+			if meta.GetUID() == "" {
+				meta.SetUID(types.UID(names.SimpleNameGenerator.GenerateName(meta.GetName() + "-")))
+			}
+		}
+		for _, reactor := range chain {
+			if !reactor.Handles(action) {
+				continue
+			}
+			if handled, ret, err := reactor.React(action); handled {
+				return true, ret, err
+			}
+		}
+		return false, nil, nil
+	})
+}
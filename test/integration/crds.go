@@ -0,0 +1,76 @@
+package integration
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	apiextensions "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// CRDController stands in for the apiextensions apiserver, which accepts the names of CRDs and establishes them
+type CRDController struct {
+	client apiextensions.Interface
+}
+
+// NewCRDController creates a CRDController that updates the status of CRDs through client
+func NewCRDController(client apiextensions.Interface) *CRDController {
+	return &CRDController{client: client}
+}
+
+// Run establishes CRDs every interval until stopc closes
+func (c *CRDController) Run(interval time.Duration, stopc <-chan struct{}) {
+	wait.Until(func() {
+		if err := c.Sync(); err != nil {
+			log.Warnf("error syncing CRDs: %s", err)
+		}
+	}, interval, stopc)
+}
+
+// Sync accepts the names of every CRD that isn't established yet and establishes it
+func (c *CRDController) Sync() error {
+	crds, err := c.client.ApiextensionsV1beta1().CustomResourceDefinitions().List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for i := range crds.Items {
+		crd := &crds.Items[i]
+		if established(crd) {
+			continue
+		}
+		now := metav1.Now()
+		crd.Status.Conditions = []v1beta1.CustomResourceDefinitionCondition{
+			{
+				Type:               v1beta1.NamesAccepted,
+				Status:             v1beta1.ConditionTrue,
+				LastTransitionTime: now,
+				Reason:             "NoConflicts",
+				Message:            "no conflicts found",
+			},
+			{
+				Type:               v1beta1.Established,
+				Status:             v1beta1.ConditionTrue,
+				LastTransitionTime: now,
+				Reason:             "InitialNamesAccepted",
+				Message:            "the initial names have been accepted",
+			},
+		}
+		crd.Status.AcceptedNames = crd.Spec.Names
+		if _, err := c.client.ApiextensionsV1beta1().CustomResourceDefinitions().UpdateStatus(crd); err != nil {
+			return fmt.Errorf("error updating status of CRD %s: %s", crd.GetName(), err)
+		}
+	}
+	return nil
+}
+
+func established(crd *v1beta1.CustomResourceDefinition) bool {
+	for _, cond := range crd.Status.Conditions {
+		if cond.Type == v1beta1.Established && cond.Status == v1beta1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
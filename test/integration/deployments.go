@@ -0,0 +1,138 @@
+package integration
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/controller/install"
+)
+
+// Rollout is the outcome of rolling out a deployment
+type Rollout string
+
+const (
+	// RolloutComplete rolls out every replica and reports them available
+	RolloutComplete Rollout = "Complete"
+	// RolloutStalled leaves the rollout in progress, with no replica available
+	RolloutStalled Rollout = "Stalled"
+	// RolloutFailed reports that the rollout exceeded its progress deadline
+	RolloutFailed Rollout = "Failed"
+)
+
+// DeploymentController stands in for the kubernetes deployment controller. Rollouts of every deployment complete,
+// unless the deployment is given another outcome.
+type DeploymentController struct {
+	client   kubernetes.Interface
+	mu       sync.Mutex
+	outcomes map[string]Rollout
+}
+
+// NewDeploymentController creates a DeploymentController that updates the status of deployments through client
+func NewDeploymentController(client kubernetes.Interface) *DeploymentController {
+	return &DeploymentController{client: client, outcomes: map[string]Rollout{}}
+}
+
+// SetRollout sets the outcome of rollouts of a deployment. OLM waits for updates of existing deployments to roll
+// out, so an update of a deployment whose rollouts don't complete blocks until they do.
+func (c *DeploymentController) SetRollout(namespace, name string, outcome Rollout) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.outcomes[namespace+"/"+name] = outcome
+}
+
+func (c *DeploymentController) rollout(dep *appsv1.Deployment) Rollout {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if outcome, ok := c.outcomes[dep.GetNamespace()+"/"+dep.GetName()]; ok {
+		return outcome
+	}
+	return RolloutComplete
+}
+
+// Run updates the status of deployments every interval until stopc closes
+func (c *DeploymentController) Run(interval time.Duration, stopc <-chan struct{}) {
+	wait.Until(func() {
+		if err := c.Sync(); err != nil {
+			log.Warnf("error syncing deployments: %s", err)
+		}
+	}, interval, stopc)
+}
+
+// Sync updates the status of every deployment to reflect the outcome of its rollout
+func (c *DeploymentController) Sync() error {
+	deployments, err := c.client.AppsV1().Deployments(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for i := range deployments.Items {
+		dep := &deployments.Items[i]
+		status := c.rolloutStatus(dep)
+		if equality.Semantic.DeepEqual(dep.Status, status) {
+			continue
+		}
+		dep.Status = status
+		if _, err := c.client.AppsV1().Deployments(dep.GetNamespace()).UpdateStatus(dep); err != nil {
+			return fmt.Errorf("error updating status of deployment %s/%s: %s", dep.GetNamespace(), dep.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// rolloutStatus is the status of a deployment once the deployment controller has rolled it out as far as it will go
+func (c *DeploymentController) rolloutStatus(dep *appsv1.Deployment) appsv1.DeploymentStatus {
+	replicas := int32(1)
+	if dep.Spec.Replicas != nil {
+		replicas = *dep.Spec.Replicas
+	}
+	status := appsv1.DeploymentStatus{
+		ObservedGeneration: dep.GetGeneration(),
+		Replicas:           replicas,
+		UpdatedReplicas:    replicas,
+	}
+	switch c.rollout(dep) {
+	case RolloutStalled:
+		status.UnavailableReplicas = replicas
+		status.Conditions = []appsv1.DeploymentCondition{{
+			Type:    appsv1.DeploymentProgressing,
+			Status:  corev1.ConditionTrue,
+			Reason:  "ReplicaSetUpdated",
+			Message: fmt.Sprintf("deployment %q is progressing", dep.GetName()),
+		}}
+		return status
+	case RolloutFailed:
+		status.UnavailableReplicas = replicas
+		status.Conditions = []appsv1.DeploymentCondition{{
+			Type:    appsv1.DeploymentProgressing,
+			Status:  corev1.ConditionFalse,
+			Reason:  install.TimedOutReason,
+			Message: fmt.Sprintf("deployment %q has timed out progressing", dep.GetName()),
+		}}
+		return status
+	}
+	status.ReadyReplicas = replicas
+	status.AvailableReplicas = replicas
+	status.Conditions = []appsv1.DeploymentCondition{
+		{
+			Type:    appsv1.DeploymentAvailable,
+			Status:  corev1.ConditionTrue,
+			Reason:  "MinimumReplicasAvailable",
+			Message: "deployment has minimum availability",
+		},
+		{
+			Type:    appsv1.DeploymentProgressing,
+			Status:  corev1.ConditionTrue,
+			Reason:  "NewReplicaSetAvailable",
+			Message: fmt.Sprintf("deployment %q has successfully progressed", dep.GetName()),
+		},
+	}
+	return status
+}
@@ -0,0 +1,299 @@
+// Package integration runs the olm and catalog operators in-process against fake clientsets, so that scenarios
+// spanning both control loops can be tested without a cluster.
+package integration
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ghodss/yaml"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/wait"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators/v1alpha1"
+	versionedfake "github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/clientset/versioned/fake"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/controller/operators/catalog"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/controller/operators/olm"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/controller/registry"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/operatorclient"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/queueinformer"
+)
+
+const (
+	DefaultOperatorNamespace = "olm"
+	DefaultResyncInterval    = 100 * time.Millisecond
+	DefaultTimeout           = 30 * time.Second
+
+	pollInterval = 10 * time.Millisecond
+)
+
+// Config configures a Harness. Zero values are replaced with defaults.
+type Config struct {
+	// OperatorNamespace is where the operators run and read CatalogSources from
+	OperatorNamespace string
+	// Namespaces are the namespaces the operators watch. Defaults to all namespaces.
+	Namespaces []string
+	// ResyncInterval is how often informers resync
+	ResyncInterval time.Duration
+	// Timeout bounds how long the Wait methods wait
+	Timeout time.Duration
+	// Now is the time the fake clock starts at. Defaults to the current time.
+	Now time.Time
+	// QueueConfig tunes how the operators process their queues
+	QueueConfig queueinformer.Config
+}
+
+func (c Config) withDefaults() Config {
+	if c.OperatorNamespace == "" {
+		c.OperatorNamespace = DefaultOperatorNamespace
+	}
+	if len(c.Namespaces) == 0 {
+		c.Namespaces = []string{metav1.NamespaceAll}
+	}
+	if c.ResyncInterval <= 0 {
+		c.ResyncInterval = DefaultResyncInterval
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultTimeout
+	}
+	if c.Now.IsZero() {
+		c.Now = time.Now()
+	}
+	return c
+}
+
+// Harness runs the olm and catalog operators against fake clientsets and a fake clock. Tests arrange objects through
+// the clientsets and wait for the operators to act on them.
+type Harness struct {
+	KubeClient *k8sfake.Clientset
+	ExtClient  *apiextensionsfake.Clientset
+	CRClient   *versionedfake.Clientset
+	Clock      *clock.FakeClock
+	// Deployments stands in for the deployment controller
+	Deployments *DeploymentController
+	// CRDs stands in for the apiextensions apiserver establishing CRDs
+	CRDs *CRDController
+
+	config  Config
+	olm     *olm.Operator
+	catalog *catalog.Operator
+
+	stopc chan struct{}
+	wg    sync.WaitGroup
+	errs  chan error
+}
+
+// New creates a Harness whose clientsets start out with the given objects. Objects are routed to the clientset that
+// serves their type. Namespaces that the operators run in or watch are created if they're not given.
+func New(config Config, objects ...runtime.Object) (*Harness, error) {
+	config = config.withDefaults()
+
+	var kubeObjects, extObjects, crObjects []runtime.Object
+	namespaces := map[string]struct{}{}
+	for _, obj := range objects {
+		switch o := obj.(type) {
+		case *v1beta1.CustomResourceDefinition:
+			extObjects = append(extObjects, obj)
+		case *v1alpha1.ClusterServiceVersion, *v1alpha1.InstallPlan, *v1alpha1.Subscription, *v1alpha1.CatalogSource:
+			crObjects = append(crObjects, obj)
+		case *corev1.Namespace:
+			namespaces[o.GetName()] = struct{}{}
+			kubeObjects = append(kubeObjects, obj)
+		default:
+			kubeObjects = append(kubeObjects, obj)
+		}
+	}
+	for _, namespace := range append([]string{config.OperatorNamespace}, config.Namespaces...) {
+		if _, ok := namespaces[namespace]; ok || namespace == metav1.NamespaceAll {
+			continue
+		}
+		namespaces[namespace] = struct{}{}
+		kubeObjects = append(kubeObjects, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}})
+	}
+
+	h := &Harness{
+		KubeClient: k8sfake.NewSimpleClientset(kubeObjects...),
+		ExtClient:  apiextensionsfake.NewSimpleClientset(extObjects...),
+		CRClient:   versionedfake.NewSimpleClientset(crObjects...),
+		Clock:      clock.NewFakeClock(config.Now),
+		config:     config,
+		errs:       make(chan error, 2),
+	}
+	h.Deployments = NewDeploymentController(h.KubeClient)
+	h.CRDs = NewCRDController(h.ExtClient)
+	generateNames(&h.KubeClient.Fake)
+	generateNames(&h.CRClient.Fake)
+
+	opClient := &operatorClient{
+		ClientInterface: operatorclient.NewClientFromInterfaces(h.KubeClient, h.ExtClient),
+		crClient:        h.CRClient,
+	}
+
+	var err error
+	h.olm, err = olm.NewOperatorFromClients(opClient, h.CRClient, nil, h.Clock, config.ResyncInterval, nil, config.Namespaces, nil, nil, config.QueueConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error creating olm operator: %s", err)
+	}
+	h.catalog, err = catalog.NewOperatorFromClients(opClient, h.CRClient, nil, h.Clock, config.ResyncInterval, config.OperatorNamespace, nil, config.QueueConfig, config.Namespaces...)
+	if err != nil {
+		return nil, fmt.Errorf("error creating catalog operator: %s", err)
+	}
+	return h, nil
+}
+
+// Start runs the operators and the deployment and CRD controllers until Stop is called
+func (h *Harness) Start() {
+	h.stopc = make(chan struct{})
+	for _, op := range []*queueinformer.Operator{h.olm.Operator, h.catalog.Operator} {
+		h.wg.Add(1)
+		go func(op *queueinformer.Operator) {
+			defer h.wg.Done()
+			if err := op.Run(h.stopc); err != nil {
+				h.errs <- err
+			}
+		}(op)
+	}
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		h.Deployments.Run(pollInterval, h.stopc)
+	}()
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		h.CRDs.Run(pollInterval, h.stopc)
+	}()
+}
+
+// Stop stops the operators, waits for them to finish and returns the first error they returned
+func (h *Harness) Stop() error {
+	close(h.stopc)
+	h.wg.Wait()
+	select {
+	case err := <-h.errs:
+		return err
+	default:
+		return nil
+	}
+}
+
+// Step advances the fake clock, for scenarios that depend on timeouts or update times
+func (h *Harness) Step(d time.Duration) {
+	h.Clock.Step(d)
+}
+
+// WaitFor polls condition until it's met or the harness timeout passes. Errors from condition are retried, and the
+// last one is reported if it's never met.
+func (h *Harness) WaitFor(description string, condition func() (bool, error)) error {
+	var last error
+	err := wait.Poll(pollInterval, h.config.Timeout, func() (bool, error) {
+		select {
+		case err := <-h.errs:
+			return false, fmt.Errorf("operator stopped: %s", err)
+		default:
+		}
+		done, err := condition()
+		last = err
+		return done && err == nil, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		if last != nil {
+			return fmt.Errorf("timed out waiting for %s: %s", description, last)
+		}
+		return fmt.Errorf("timed out waiting for %s", description)
+	}
+	return err
+}
+
+// WaitForCSVPhase waits for a ClusterServiceVersion to reach a phase and returns it
+func (h *Harness) WaitForCSVPhase(namespace, name string, phase v1alpha1.ClusterServiceVersionPhase) (*v1alpha1.ClusterServiceVersion, error) {
+	var csv *v1alpha1.ClusterServiceVersion
+	err := h.WaitFor(fmt.Sprintf("ClusterServiceVersion %s/%s to reach phase %s", namespace, name, phase), func() (bool, error) {
+		var err error
+		csv, err = h.CRClient.OperatorsV1alpha1().ClusterServiceVersions(namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		if csv.Status.Phase != phase {
+			return false, fmt.Errorf("phase is %s: %s", csv.Status.Phase, csv.Status.Message)
+		}
+		return true, nil
+	})
+	return csv, err
+}
+
+// WaitForCSVDeleted waits for a ClusterServiceVersion to be deleted
+func (h *Harness) WaitForCSVDeleted(namespace, name string) error {
+	return h.WaitFor(fmt.Sprintf("ClusterServiceVersion %s/%s to be deleted", namespace, name), func() (bool, error) {
+		_, err := h.CRClient.OperatorsV1alpha1().ClusterServiceVersions(namespace).Get(name, metav1.GetOptions{})
+		if k8serrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+}
+
+// WaitForSubscription waits for a Subscription to satisfy condition and returns it
+func (h *Harness) WaitForSubscription(namespace, name string, condition func(*v1alpha1.Subscription) bool) (*v1alpha1.Subscription, error) {
+	var sub *v1alpha1.Subscription
+	err := h.WaitFor(fmt.Sprintf("Subscription %s/%s", namespace, name), func() (bool, error) {
+		var err error
+		sub, err = h.CRClient.OperatorsV1alpha1().Subscriptions(namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return condition(sub), nil
+	})
+	return sub, err
+}
+
+// SetCatalog creates or replaces the contents of a ConfigMap-backed CatalogSource in the operator namespace
+func (h *Harness) SetCatalog(name string, crds []v1beta1.CustomResourceDefinition, csvs []v1alpha1.ClusterServiceVersion, packages []registry.PackageManifest) error {
+	data := map[string]string{}
+	for key, content := range map[string]interface{}{
+		registry.ConfigMapCRDName:     crds,
+		registry.ConfigMapCSVName:     csvs,
+		registry.ConfigMapPackageName: packages,
+	} {
+		raw, err := yaml.Marshal(content)
+		if err != nil {
+			return fmt.Errorf("error marshalling catalog %s: %s", key, err)
+		}
+		data[key] = string(raw)
+	}
+
+	namespace := h.config.OperatorNamespace
+	configMaps := h.KubeClient.CoreV1().ConfigMaps(namespace)
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}, Data: data}
+	if _, err := configMaps.Update(cm); k8serrors.IsNotFound(err) {
+		_, err = configMaps.Create(cm)
+		if err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+
+	// the catalog operator reloads the catalog when its ConfigMap changes
+	catalogSources := h.CRClient.OperatorsV1alpha1().CatalogSources(namespace)
+	_, err := catalogSources.Get(name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		_, err = catalogSources.Create(&v1alpha1.CatalogSource{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: v1alpha1.CatalogSourceSpec{
+				Name:       name,
+				SourceType: "internal",
+				ConfigMap:  name,
+			},
+		})
+	}
+	return err
+}
@@ -0,0 +1,197 @@
+package integration
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators/v1alpha1"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/controller/install"
+	"github.com/operator-framework/operator-lifecycle-manager/pkg/controller/registry"
+)
+
+const (
+	testNamespace = "operators"
+	catalogName   = "catalog"
+	packageName   = "etcd"
+	channelName   = "alpha"
+	crdName       = "etcdclusters.etcd.database.coreos.com"
+)
+
+func etcdCRD() v1beta1.CustomResourceDefinition {
+	return v1beta1.CustomResourceDefinition{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apiextensions.k8s.io/v1beta1", Kind: "CustomResourceDefinition"},
+		ObjectMeta: metav1.ObjectMeta{Name: crdName},
+		Spec: v1beta1.CustomResourceDefinitionSpec{
+			Group:   "etcd.database.coreos.com",
+			Version: "v1beta2",
+			Scope:   v1beta1.NamespaceScoped,
+			Names: v1beta1.CustomResourceDefinitionNames{
+				Plural:   "etcdclusters",
+				Singular: "etcdcluster",
+				Kind:     "EtcdCluster",
+				ListKind: "EtcdClusterList",
+			},
+		},
+	}
+}
+
+func etcdCSV(t *testing.T, name, replaces, image string) v1alpha1.ClusterServiceVersion {
+	strategy := install.StrategyDetailsDeployment{
+		DeploymentSpecs: []install.StrategyDeploymentSpec{{
+			Name: "etcd-operator",
+			Spec: appsv1.DeploymentSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "etcd-operator"}},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "etcd-operator"}},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "etcd-operator", Image: image}},
+					},
+				},
+			},
+		}},
+	}
+	strategyRaw, err := json.Marshal(strategy)
+	require.NoError(t, err)
+
+	return v1alpha1.ClusterServiceVersion{
+		TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha1.ClusterServiceVersionAPIVersion, Kind: v1alpha1.ClusterServiceVersionKind},
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: v1alpha1.ClusterServiceVersionSpec{
+			Replaces: replaces,
+			InstallStrategy: v1alpha1.NamedInstallStrategy{
+				StrategyName:    install.InstallStrategyNameDeployment,
+				StrategySpecRaw: strategyRaw,
+			},
+			CustomResourceDefinitions: v1alpha1.CustomResourceDefinitions{
+				Owned: []v1alpha1.CRDDescription{{Name: crdName, Version: "v1beta2", Kind: "EtcdCluster"}},
+			},
+		},
+	}
+}
+
+func etcdPackage(currentCSV string) []registry.PackageManifest {
+	return []registry.PackageManifest{{
+		PackageName: packageName,
+		Channels:    []registry.PackageChannel{{Name: channelName, CurrentCSVName: currentCSV}},
+	}}
+}
+
+func TestSubscriptionInstallAndUpgrade(t *testing.T) {
+	h, err := New(Config{Namespaces: []string{testNamespace}})
+	require.NoError(t, err)
+
+	v1 := etcdCSV(t, "etcdoperator.v0.9.0", "", "quay.io/coreos/etcd-operator:v0.9.0")
+	v2 := etcdCSV(t, "etcdoperator.v0.9.2", v1.GetName(), "quay.io/coreos/etcd-operator:v0.9.2")
+	require.NoError(t, h.SetCatalog(catalogName, []v1beta1.CustomResourceDefinition{etcdCRD()}, []v1alpha1.ClusterServiceVersion{v1}, etcdPackage(v1.GetName())))
+
+	h.Start()
+	defer func() { require.NoError(t, h.Stop()) }()
+
+	// subscribing creates an InstallPlan that installs the current CSV in the channel
+	_, err = h.CRClient.OperatorsV1alpha1().Subscriptions(testNamespace).Create(&v1alpha1.Subscription{
+		ObjectMeta: metav1.ObjectMeta{Name: packageName, Namespace: testNamespace},
+		Spec: &v1alpha1.SubscriptionSpec{
+			CatalogSource:          catalogName,
+			CatalogSourceNamespace: DefaultOperatorNamespace,
+			Package:                packageName,
+			Channel:                channelName,
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = h.WaitForCSVPhase(testNamespace, v1.GetName(), v1alpha1.CSVPhaseSucceeded)
+	require.NoError(t, err)
+	dep, err := h.KubeClient.AppsV1().Deployments(testNamespace).Get("etcd-operator", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "quay.io/coreos/etcd-operator:v0.9.0", dep.Spec.Template.Spec.Containers[0].Image)
+	_, err = h.ExtClient.ApiextensionsV1beta1().CustomResourceDefinitions().Get(crdName, metav1.GetOptions{})
+	require.NoError(t, err)
+
+	sub, err := h.WaitForSubscription(testNamespace, packageName, func(sub *v1alpha1.Subscription) bool {
+		return sub.Status.State == v1alpha1.SubscriptionStateAtLatest
+	})
+	require.NoError(t, err)
+	require.Equal(t, v1.GetName(), sub.Status.CurrentCSV)
+
+	// updating the catalog upgrades to the new CSV, which replaces the old one
+	require.NoError(t, h.SetCatalog(catalogName, []v1beta1.CustomResourceDefinition{etcdCRD()}, []v1alpha1.ClusterServiceVersion{v1, v2}, etcdPackage(v2.GetName())))
+
+	_, err = h.WaitForCSVPhase(testNamespace, v2.GetName(), v1alpha1.CSVPhaseSucceeded)
+	require.NoError(t, err)
+	dep, err = h.KubeClient.AppsV1().Deployments(testNamespace).Get("etcd-operator", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "quay.io/coreos/etcd-operator:v0.9.2", dep.Spec.Template.Spec.Containers[0].Image)
+
+	// the replaced CSV is garbage collected
+	require.NoError(t, h.WaitForCSVDeleted(testNamespace, v1.GetName()))
+	sub, err = h.WaitForSubscription(testNamespace, packageName, func(sub *v1alpha1.Subscription) bool {
+		return sub.Status.CurrentCSV == v2.GetName() && sub.Status.State == v1alpha1.SubscriptionStateAtLatest
+	})
+	require.NoError(t, err)
+
+	plans, err := h.CRClient.OperatorsV1alpha1().InstallPlans(testNamespace).List(metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, plans.Items, 2)
+	for _, plan := range plans.Items {
+		require.Equal(t, v1alpha1.InstallPlanPhaseComplete, plan.Status.Phase)
+	}
+}
+
+func installCSV(t *testing.T, h *Harness, csv v1alpha1.ClusterServiceVersion) {
+	crd := etcdCRD()
+	_, err := h.ExtClient.ApiextensionsV1beta1().CustomResourceDefinitions().Create(&crd)
+	require.NoError(t, err)
+	csv.SetNamespace(testNamespace)
+	_, err = h.CRClient.OperatorsV1alpha1().ClusterServiceVersions(testNamespace).Create(&csv)
+	require.NoError(t, err)
+}
+
+func TestFailedRollout(t *testing.T) {
+	h, err := New(Config{Namespaces: []string{testNamespace}})
+	require.NoError(t, err)
+	h.Deployments.SetRollout(testNamespace, "etcd-operator", RolloutFailed)
+
+	csv := etcdCSV(t, "etcdoperator.v0.9.0", "", "quay.io/coreos/etcd-operator:v0.9.0")
+	installCSV(t, h, csv)
+
+	h.Start()
+	defer func() { require.NoError(t, h.Stop()) }()
+
+	// a rollout that exceeds its progress deadline fails the install
+	failed, err := h.WaitForCSVPhase(testNamespace, csv.GetName(), v1alpha1.CSVPhaseFailed)
+	require.NoError(t, err)
+	require.Equal(t, v1alpha1.CSVReasonInstallCheckFailed, failed.Status.Reason)
+	require.Contains(t, failed.Status.Message, "exceeded its progress deadline")
+}
+
+func TestInstallTimeout(t *testing.T) {
+	h, err := New(Config{Namespaces: []string{testNamespace}})
+	require.NoError(t, err)
+	h.Deployments.SetRollout(testNamespace, "etcd-operator", RolloutStalled)
+
+	csv := etcdCSV(t, "etcdoperator.v0.9.0", "", "quay.io/coreos/etcd-operator:v0.9.0")
+	csv.Spec.InstallTimeout = &metav1.Duration{Duration: 10 * time.Minute}
+	installCSV(t, h, csv)
+
+	h.Start()
+	defer func() { require.NoError(t, h.Stop()) }()
+
+	// a stalled rollout keeps the CSV installing until the install timeout passes on the clock
+	require.NoError(t, h.WaitFor("deployment rollout to stall", func() (bool, error) {
+		csv, err := h.CRClient.OperatorsV1alpha1().ClusterServiceVersions(testNamespace).Get(csv.GetName(), metav1.GetOptions{})
+		return err == nil && csv.Status.Phase == v1alpha1.CSVPhaseInstalling && csv.Status.Reason == v1alpha1.CSVReasonWaiting, err
+	}))
+
+	h.Step(time.Hour)
+	failed, err := h.WaitForCSVPhase(testNamespace, csv.GetName(), v1alpha1.CSVPhaseFailed)
+	require.NoError(t, err)
+	require.Equal(t, v1alpha1.CSVReasonInstallCheckFailed, failed.Status.Reason)
+	require.Contains(t, failed.Status.Message, "install timed out after 10m0s")
+}